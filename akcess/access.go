@@ -0,0 +1,250 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// AccessConfig MSPs whose members hold AKcess roles, kept in world state so every peer endorses with the same list
+type AccessConfig struct {
+	ObjectType      string   `json:"docType"`
+	AdminMSPIDs     []string `json:"adminMspIds"`           // members of these MSPs are admins
+	OTPIssuerMSPIDs []string `json:"otpIssuerMspIds"`       // members of these MSPs are trusted to issue OTP challenges
+	LegacyMSPID     string   `json:"legacyMspId,omitempty"` // MSP users and verifiers registered before identity bindings were enrolled with
+	UpdatedBy       string   `json:"updatedBy"`
+}
+
+// InitLedger bootstraps access config with admin and OTP issuer MSPs. Invoker must hold isAdmin=true attribute or admin role
+// of its MSP and at least one admin MSP must be passed. It can run only once, afterwards MSPs are changed with SetAdminMSPIDs
+// and SetOTPIssuerMSPIDs
+func (u *UserContract) InitLedger(ctx contractapi.TransactionContextInterface, adminMSPIDs []string, otpIssuerMSPIDs []string) Response {
+	response := Response{
+		TxID:    ctx.GetStub().GetTxID(),
+		Success: false,
+		Message: "",
+		Data:    nil,
+	}
+
+	config, err := getAccessConfig(ctx)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while fetching access config: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+	if config != nil {
+		response.Message = fmt.Sprint("Ledger is already initialized")
+		logger.Info(response.Message)
+		return response
+	}
+	invoker := invokerName(ctx)
+	if !isBootstrapAdmin(ctx) {
+		response.Message = fmt.Sprintf("Identity %s holds neither isAdmin attribute nor admin role, only admin can initialize ledger", invoker)
+		logger.Info(response.Message)
+		return response
+	}
+	if len(adminMSPIDs) == 0 {
+		response.Message = fmt.Sprint("At least one admin MSP is required")
+		logger.Info(response.Message)
+		return response
+	}
+
+	if otpIssuerMSPIDs == nil {
+		otpIssuerMSPIDs = []string{}
+	}
+
+	config = &AccessConfig{
+		ObjectType:      "accessconfig",
+		AdminMSPIDs:     adminMSPIDs,
+		OTPIssuerMSPIDs: otpIssuerMSPIDs,
+		UpdatedBy:       invoker,
+	}
+	err = saveAccessConfig(ctx, config)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while saving access config: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+
+	response.Success = true
+	response.Message = fmt.Sprint("Ledger initialized")
+	logger.Info(response.Message)
+	response.Data = config
+	return response
+}
+
+// SetAdminMSPIDs admin replaces list of admin MSPs, list can't be empty so admins can't lock themselves out
+func (u *UserContract) SetAdminMSPIDs(ctx contractapi.TransactionContextInterface, adminMSPIDs []string) Response {
+	response := Response{
+		TxID:    ctx.GetStub().GetTxID(),
+		Success: false,
+		Message: "",
+		Data:    nil,
+	}
+
+	invoker := invokerName(ctx)
+	if !IsAdmin(ctx) {
+		response.Message = fmt.Sprintf("Identity %s is not an admin, only admin can set admin MSPs", invoker)
+		logger.Info(response.Message)
+		return response
+	}
+	if len(adminMSPIDs) == 0 {
+		response.Message = fmt.Sprint("List of admin MSPs can't be empty")
+		logger.Info(response.Message)
+		return response
+	}
+	config, err := getAccessConfig(ctx)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while fetching access config: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+	if config == nil {
+		config = &AccessConfig{ObjectType: "accessconfig"}
+	}
+
+	config.AdminMSPIDs = adminMSPIDs
+	config.UpdatedBy = invoker
+	err = saveAccessConfig(ctx, config)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while saving access config: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+
+	response.Success = true
+	response.Message = fmt.Sprint("Admin MSPs updated")
+	logger.Info(response.Message)
+	response.Data = config
+	return response
+}
+
+// SetOTPIssuerMSPIDs admin replaces list of MSPs trusted to issue OTP challenges, pass empty list to trust only
+// identities holding otpIssuer attribute
+func (u *UserContract) SetOTPIssuerMSPIDs(ctx contractapi.TransactionContextInterface, otpIssuerMSPIDs []string) Response {
+	response := Response{
+		TxID:    ctx.GetStub().GetTxID(),
+		Success: false,
+		Message: "",
+		Data:    nil,
+	}
+
+	invoker := invokerName(ctx)
+	if !IsAdmin(ctx) {
+		response.Message = fmt.Sprintf("Identity %s is not an admin, only admin can set OTP issuer MSPs", invoker)
+		logger.Info(response.Message)
+		return response
+	}
+	config, err := getAccessConfig(ctx)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while fetching access config: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+	if config == nil {
+		response.Message = fmt.Sprint("Ledger is not initialized, run InitLedger first")
+		logger.Info(response.Message)
+		return response
+	}
+	if otpIssuerMSPIDs == nil {
+		otpIssuerMSPIDs = []string{}
+	}
+
+	config.OTPIssuerMSPIDs = otpIssuerMSPIDs
+	config.UpdatedBy = invoker
+	err = saveAccessConfig(ctx, config)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while saving access config: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+
+	response.Success = true
+	response.Message = fmt.Sprint("OTP issuer MSPs updated")
+	logger.Info(response.Message)
+	response.Data = config
+	return response
+}
+
+// GetAccessConfig returns access config, used by clients and by other chaincodes to check roles of their invoker
+func (u *UserContract) GetAccessConfig(ctx contractapi.TransactionContextInterface) (*AccessConfig, error) {
+	config, err := getAccessConfig(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read from world state. %s", err.Error())
+	}
+	if config == nil {
+		return nil, fmt.Errorf("Ledger is not initialized, run InitLedger first")
+	}
+	return config, nil
+}
+
+// IsAdmin checks if identity invoking transaction is an AKcess admin.
+// Admin is either member of one of admin MSPs in access config or holds isAdmin=true attribute in its certificate
+func IsAdmin(ctx contractapi.TransactionContextInterface) bool {
+	mspID, err := ctx.GetClientIdentity().GetMSPID()
+	if err == nil {
+		config, err := getAccessConfig(ctx)
+		if err == nil && config != nil {
+			if _, found := Find(config.AdminMSPIDs, mspID); found {
+				return true
+			}
+		}
+	}
+
+	err = ctx.GetClientIdentity().AssertAttributeValue("isAdmin", "true")
+	return err == nil
+}
+
+// isBootstrapAdmin checks if identity invoking transaction may initialize ledger before any admin MSP is configured.
+// It must hold isAdmin=true attribute or admin role of its MSP, which NodeOUs put in organizational unit of certificate
+func isBootstrapAdmin(ctx contractapi.TransactionContextInterface) bool {
+	if err := ctx.GetClientIdentity().AssertAttributeValue("isAdmin", "true"); err == nil {
+		return true
+	}
+	x509, err := ctx.GetClientIdentity().GetX509Certificate()
+	if err != nil || x509 == nil {
+		return false
+	}
+	_, found := Find(x509.Subject.OrganizationalUnit, "admin")
+	return found
+}
+
+// IsOTPIssuer checks if identity invoking transaction is trusted to issue OTP challenges.
+// Issuer is either member of one of OTP issuer MSPs in access config or holds otpIssuer=true attribute in its certificate
+func IsOTPIssuer(ctx contractapi.TransactionContextInterface) bool {
+	mspID, err := ctx.GetClientIdentity().GetMSPID()
+	if err == nil {
+		config, err := getAccessConfig(ctx)
+		if err == nil && config != nil {
+			if _, found := Find(config.OTPIssuerMSPIDs, mspID); found {
+				return true
+			}
+		}
+	}
+
+	err = ctx.GetClientIdentity().AssertAttributeValue("otpIssuer", "true")
+	return err == nil
+}
+
+// getAccessConfig reads access config, nil if ledger is not initialized yet
+func getAccessConfig(ctx contractapi.TransactionContextInterface) (*AccessConfig, error) {
+	key, _ := objectKey(ctx, ConfigObject, "access")
+	configAsBytes, err := ctx.GetStub().GetState(key)
+	if err != nil || configAsBytes == nil {
+		return nil, err
+	}
+	var config AccessConfig
+	err = json.Unmarshal(configAsBytes, &config)
+	if err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
+
+// saveAccessConfig writes access config to world state
+func saveAccessConfig(ctx contractapi.TransactionContextInterface, config *AccessConfig) error {
+	configAsBytes, _ := json.Marshal(config)
+	key, _ := objectKey(ctx, ConfigObject, "access")
+	return ctx.GetStub().PutState(key, configAsBytes)
+}
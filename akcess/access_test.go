@@ -0,0 +1,40 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestInitLedger(t *testing.T) {
+	tests := []struct {
+		name          string
+		role          string
+		attrs         map[string]string
+		adminMSPIDs   []string
+		expectedError string
+	}{
+		{name: "client of MSP", role: "client", adminMSPIDs: []string{"AdminMSP"}, expectedError: "Identity Org1MSP::alice holds neither isAdmin attribute nor admin role"},
+		{name: "peer of MSP", role: "peer", adminMSPIDs: []string{"AdminMSP"}, expectedError: "only admin can initialize ledger"},
+		{name: "admin role without admin MSPs", role: "admin", expectedError: "At least one admin MSP is required"},
+		{name: "admin role", role: "admin", adminMSPIDs: []string{"AdminMSP"}},
+		{name: "client with isAdmin attribute", role: "client", attrs: map[string]string{"isAdmin": "true"}, adminMSPIDs: []string{"AdminMSP"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := newTestLedger(t)
+			response := new(UserContract).InitLedger(l.asRole(tt.role, "Org1MSP", "alice", tt.attrs), tt.adminMSPIDs, nil)
+			expectResponse(t, response, tt.expectedError)
+			if tt.expectedError != "" {
+				if IsAdmin(l.as("Org1MSP", "bob", nil)) {
+					t.Fatalf("expected member of invoker MSP not to become admin")
+				}
+				return
+			}
+			if !IsAdmin(l.as("AdminMSP", "carol", nil)) || IsAdmin(l.as("Org1MSP", "bob", nil)) {
+				t.Fatalf("expected only members of AdminMSP to be admins, got %+v", response.Data)
+			}
+			response = new(UserContract).InitLedger(l.asRole("admin", "Org1MSP", "alice", nil), []string{"Org1MSP"}, nil)
+			expectResponse(t, response, "Ledger is already initialized")
+		})
+	}
+}
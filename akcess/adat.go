@@ -13,7 +13,7 @@ type DigitalAssetContract struct {
 	contractapi.Contract
 }
 
-// RegisterAsset register new digital asset
+// RegisterAsset register new digital asset of registered asset type, metadata is validated against schema of the type
 func (da *DigitalAssetContract) RegisterAsset(ctx contractapi.TransactionContextInterface, assetType string, metadata map[string]string, description string, assetDocHash string) Response {
 	response := Response{
 		TxID:    ctx.GetStub().GetTxID(),
@@ -22,14 +22,21 @@ func (da *DigitalAssetContract) RegisterAsset(ctx contractapi.TransactionContext
 		Data:    nil,
 	}
 
-	invoker, err := getCommonName(ctx)
+	invoker, err := resolveAkcessID(ctx)
 	if err != nil {
-		response.Message = fmt.Sprintf("Error while getting commonName from x509 cert: %s", err.Error())
-		logger.Error(response.Message)
+		response.Message = err.Error()
+		logger.Info(response.Message)
+		return response
+	}
+	err = validateAssetMetadata(ctx, assetType, metadata)
+	if err != nil {
+		response.Message = err.Error()
+		logger.Info(response.Message)
 		return response
 	}
 
 	asset := DigitalAsset{
+		ObjectType:    AssetObject,
 		UniqueAssetID: response.TxID,
 		AssetType:     assetType,
 		Owner:         invoker,
@@ -45,7 +52,7 @@ func (da *DigitalAssetContract) RegisterAsset(ctx contractapi.TransactionContext
 		return response
 	}
 
-	err = ctx.GetStub().PutState(asset.UniqueAssetID, assetAsBytes)
+	err = putObject(ctx, AssetObject, asset.UniqueAssetID, assetAsBytes)
 	if err != nil {
 		response.Message = fmt.Sprintf("Error while saving asset in ledger: %s", err.Error())
 		logger.Error(response.Message)
@@ -59,8 +66,14 @@ func (da *DigitalAssetContract) RegisterAsset(ctx contractapi.TransactionContext
 	return response
 }
 
-// TransferAsset transfers given asset from invoker to recipient
+// TransferAsset offers given asset to recipient with default expiry, kept for existing clients.
+// Asset is transferred only once recipient accepts offer with AcceptAssetTransfer
 func (da *DigitalAssetContract) TransferAsset(ctx contractapi.TransactionContextInterface, assetID string, recipient string) Response {
+	return offerAssetTransfer(ctx, assetID, recipient, 0, "")
+}
+
+// LinkDocument link document to digital asset
+func (da *DigitalAssetContract) LinkDocument(ctx contractapi.TransactionContextInterface, assetID string, documentID string) Response {
 	response := Response{
 		TxID:    ctx.GetStub().GetTxID(),
 		Success: false,
@@ -68,14 +81,14 @@ func (da *DigitalAssetContract) TransferAsset(ctx contractapi.TransactionContext
 		Data:    nil,
 	}
 
-	invoker, err := getCommonName(ctx)
+	invoker, err := resolveAkcessID(ctx)
 	if err != nil {
-		response.Message = fmt.Sprintf("Error while getting commonName from x509 cert: %s", err.Error())
-		logger.Error(response.Message)
+		response.Message = err.Error()
+		logger.Info(response.Message)
 		return response
 	}
 
-	assetAsBytes, err := ctx.GetStub().GetState(assetID)
+	assetAsBytes, err := getObject(ctx, AssetObject, assetID)
 	if err != nil {
 		response.Message = fmt.Sprintf("Error while getting asset from ledger: %s", err.Error())
 		logger.Error(response.Message)
@@ -95,73 +108,34 @@ func (da *DigitalAssetContract) TransferAsset(ctx contractapi.TransactionContext
 		return response
 	}
 
-	if asset.Owner != invoker {
-		response.Message = fmt.Sprintf("Digtal asset with id %s not owned by %s", asset.UniqueAssetID, invoker)
-		logger.Error(response.Message)
-		return response
-	}
-
-	asset.Owner = recipient
-	assetAsBytes, err = json.Marshal(asset)
-	if err != nil {
-		response.Message = fmt.Sprintf("Error while marshling asset: %s", err.Error())
-		logger.Error(response.Message)
-		return response
-	}
-
-	err = ctx.GetStub().PutState(asset.UniqueAssetID, assetAsBytes)
-	if err != nil {
-		response.Message = fmt.Sprintf("Error while saving asset in ledger: %s", err.Error())
-		logger.Error(response.Message)
-		return response
-	}
-
-	response.Success = true
-	response.Message = fmt.Sprintf("Digital asset with id %s successfully updated and owned by %s", asset.UniqueAssetID, recipient)
-	logger.Info(response.Message)
-	response.Data = asset
-	return response
-}
-
-// LinkDocument link document to digital asset
-func (da *DigitalAssetContract) LinkDocument(ctx contractapi.TransactionContextInterface, assetID string, documentID string) Response {
-	response := Response{
-		TxID:    ctx.GetStub().GetTxID(),
-		Success: false,
-		Message: "",
-		Data:    nil,
-	}
-
-	invoker, err := getCommonName(ctx)
+	err = checkAssetApproval(ctx, &asset, AssetActionLink, documentID, invoker)
 	if err != nil {
-		response.Message = fmt.Sprintf("Error while getting commonName from x509 cert: %s", err.Error())
-		logger.Error(response.Message)
+		response.Message = err.Error()
+		logger.Info(response.Message)
 		return response
 	}
 
-	assetAsBytes, err := ctx.GetStub().GetState(assetID)
+	doc, err := getDocument(ctx, documentID)
 	if err != nil {
-		response.Message = fmt.Sprintf("Error while getting asset from ledger: %s", err.Error())
+		response.Message = fmt.Sprintf("Error while fetching doc from world state: %s", err.Error())
 		logger.Error(response.Message)
 		return response
 	}
-	if assetAsBytes == nil {
-		response.Message = fmt.Sprintf("Digital asset with id %s not found", assetID)
+	if doc == nil {
+		response.Message = fmt.Sprintf("Document with id %s doesn't exist", documentID)
 		logger.Info(response.Message)
 		return response
 	}
-
-	var asset DigitalAsset
-	err = json.Unmarshal(assetAsBytes, &asset)
+	txTime, err := getTxTimestamp(ctx)
 	if err != nil {
-		response.Message = fmt.Sprintf("Error while unmarshling asset: %s", err.Error())
+		response.Message = fmt.Sprintf("Error while getting tx timestamp: %s", err.Error())
 		logger.Error(response.Message)
 		return response
 	}
-
-	if asset.Owner != invoker {
-		response.Message = fmt.Sprintf("Digtal asset with id %s not owned by %s", asset.UniqueAssetID, invoker)
-		logger.Error(response.Message)
+	err = checkDocumentActive(doc, txTime)
+	if err != nil {
+		response.Message = err.Error()
+		logger.Info(response.Message)
 		return response
 	}
 
@@ -181,12 +155,18 @@ func (da *DigitalAssetContract) LinkDocument(ctx contractapi.TransactionContextI
 		return response
 	}
 
-	err = ctx.GetStub().PutState(asset.UniqueAssetID, assetAsBytes)
+	err = putObject(ctx, AssetObject, asset.UniqueAssetID, assetAsBytes)
 	if err != nil {
 		response.Message = fmt.Sprintf("Error while saving asset in ledger: %s", err.Error())
 		logger.Error(response.Message)
 		return response
 	}
+	err = clearAssetApprovals(ctx, assetID, AssetActionLink, documentID)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while clearing approvals: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
 
 	response.Success = true
 	response.Message = fmt.Sprintf("Document %s linked with asset %s", documentID, assetID)
@@ -204,14 +184,14 @@ func (da *DigitalAssetContract) VerifyAssetOwnership(ctx contractapi.Transaction
 		Data:    nil,
 	}
 
-	invoker, err := getCommonName(ctx)
+	invoker, err := resolveAkcessID(ctx)
 	if err != nil {
-		response.Message = fmt.Sprintf("Error while getting commonName from x509 cert: %s", err.Error())
-		logger.Error(response.Message)
+		response.Message = err.Error()
+		logger.Info(response.Message)
 		return response
 	}
 
-	verifierAsBytes, err := ctx.GetStub().GetState(invoker)
+	verifierAsBytes, err := getObject(ctx, VerifierObject, invoker)
 	if err != nil {
 		response.Message = fmt.Sprintf("Error while getting verifier from ledger: %s", err.Error())
 		logger.Error(response.Message)
@@ -229,8 +209,13 @@ func (da *DigitalAssetContract) VerifyAssetOwnership(ctx contractapi.Transaction
 		logger.Error(response.Message)
 		return response
 	}
+	if !verifier.IsApproved() {
+		response.Message = fmt.Sprintf("Verifier %s is not approved, current status is %s", invoker, verifier.CurrentStatus())
+		logger.Info(response.Message)
+		return response
+	}
 
-	assetAsBytes, err := ctx.GetStub().GetState(assetID)
+	assetAsBytes, err := getObject(ctx, AssetObject, assetID)
 	if err != nil {
 		response.Message = fmt.Sprintf("Error while getting asset from ledger: %s", err.Error())
 		logger.Error(response.Message)
@@ -250,6 +235,13 @@ func (da *DigitalAssetContract) VerifyAssetOwnership(ctx contractapi.Transaction
 		return response
 	}
 
+	err = checkGradePolicy(ctx, PolicyScopeAsset, asset.AssetType, verifier.VerifierGrade)
+	if err != nil {
+		response.Message = fmt.Sprintf("Verifier %s can't verify asset %s: %s", invoker, assetID, err.Error())
+		logger.Info(response.Message)
+		return response
+	}
+
 	// Verifying hash of asset doc
 	if asset.AssetDocHash != assetDocHash {
 		response.Message = fmt.Sprint("Document malformed. Asset hash you sent is not metching with asset in Blockchain.", err.Error())
@@ -270,9 +262,29 @@ func (da *DigitalAssetContract) VerifyAssetOwnership(ctx contractapi.Transaction
 			logger.Error(response.Message)
 			return response
 		}
+		txTime, err := getTxTimestamp(ctx)
+		if err != nil {
+			response.Message = fmt.Sprintf("Error while getting tx timestamp: %s", err.Error())
+			logger.Error(response.Message)
+			return response
+		}
+		policy, err := getTimePolicy(ctx)
+		if err != nil {
+			response.Message = fmt.Sprintf("Error while fetching time policy: %s", err.Error())
+			logger.Error(response.Message)
+			return response
+		}
+		err = policy.CheckExpiryDate(expirydate, txTime)
+		if err != nil {
+			response.Message = err.Error()
+			logger.Info(response.Message)
+			return response
+		}
 		verification := Verification{
-			VerifierObj: verifier,
-			ExpirtyDate: expirydate,
+			VerifierID:      verifier.AkcessID,
+			VerifierVersion: verifier.Version,
+			ExpirtyDate:     expirydate,
+			TxTimestamp:     txTime,
 		}
 		asset.Verifications = append(asset.Verifications, verification)
 	}
@@ -284,7 +296,7 @@ func (da *DigitalAssetContract) VerifyAssetOwnership(ctx contractapi.Transaction
 		return response
 	}
 
-	err = ctx.GetStub().PutState(asset.UniqueAssetID, assetAsBytes)
+	err = putObject(ctx, AssetObject, asset.UniqueAssetID, assetAsBytes)
 	if err != nil {
 		response.Message = fmt.Sprintf("Error while saving asset in ledger: %s", err.Error())
 		logger.Error(response.Message)
@@ -307,14 +319,14 @@ func (da *DigitalAssetContract) RemoveVerification(ctx contractapi.TransactionCo
 		Data:    nil,
 	}
 
-	invoker, err := getCommonName(ctx)
+	invoker, err := resolveAkcessID(ctx)
 	if err != nil {
-		response.Message = fmt.Sprintf("Error while getting commonName from x509 cert: %s", err.Error())
-		logger.Error(response.Message)
+		response.Message = err.Error()
+		logger.Info(response.Message)
 		return response
 	}
 
-	verifierAsBytes, err := ctx.GetStub().GetState(invoker)
+	verifierAsBytes, err := getObject(ctx, VerifierObject, invoker)
 	if err != nil {
 		response.Message = fmt.Sprintf("Error while getting verifier from ledger: %s", err.Error())
 		logger.Error(response.Message)
@@ -333,7 +345,7 @@ func (da *DigitalAssetContract) RemoveVerification(ctx contractapi.TransactionCo
 		return response
 	}
 
-	assetAsBytes, err := ctx.GetStub().GetState(assetID)
+	assetAsBytes, err := getObject(ctx, AssetObject, assetID)
 	if err != nil {
 		response.Message = fmt.Sprintf("Error while getting asset from ledger: %s", err.Error())
 		logger.Error(response.Message)
@@ -370,7 +382,7 @@ func (da *DigitalAssetContract) RemoveVerification(ctx contractapi.TransactionCo
 		return response
 	}
 
-	err = ctx.GetStub().PutState(asset.UniqueAssetID, assetAsBytes)
+	err = putObject(ctx, AssetObject, asset.UniqueAssetID, assetAsBytes)
 	if err != nil {
 		response.Message = fmt.Sprintf("Error while saving asset in ledger: %s", err.Error())
 		logger.Error(response.Message)
@@ -394,7 +406,7 @@ func (da *DigitalAssetContract) GetDigitalAsset(ctx contractapi.TransactionConte
 		Data:    nil,
 	}
 
-	assetAsBytes, err := ctx.GetStub().GetState(assetID)
+	assetAsBytes, err := getObject(ctx, AssetObject, assetID)
 	if err != nil {
 		response.Message = fmt.Sprintf("Error while getting asset from ledger: %s", err.Error())
 		logger.Error(response.Message)
@@ -414,14 +426,84 @@ func (da *DigitalAssetContract) GetDigitalAsset(ctx contractapi.TransactionConte
 		return response
 	}
 
+	view, err := buildVerificationsView(ctx, PolicyScopeAsset, asset.AssetType, asset.Verifications, nil, "", false)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while evaluating verifications: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+
+	txTime, err := getTxTimestamp(ctx)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while getting tx timestamp: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+	liens, err := getIndexedLiens(ctx, AssetLienIndex, assetID, txTime)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while fetching liens: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+
 	response.Success = true
 	response.Message = fmt.Sprint("Successfully fetched asset")
 	logger.Info(response.Message)
-	response.Data = asset
+	response.Data = DigitalAssetView{
+		DigitalAsset:  asset,
+		Verifications: view.Verifications,
+		Verified:      view.Verified,
+		Policy:        view.Policy,
+		Liens:         liens,
+	}
+	return response
+}
+
+// GetVerifiersOfAsset get verifiers of digital asset with status of each verification,
+// pass activeOnly to skip expired verifications
+func (da *DigitalAssetContract) GetVerifiersOfAsset(ctx contractapi.TransactionContextInterface, assetID string, activeOnly bool) Response {
+	response := Response{
+		TxID:    ctx.GetStub().GetTxID(),
+		Success: false,
+		Message: "",
+		Data:    nil,
+	}
+
+	assetAsBytes, err := getObject(ctx, AssetObject, assetID)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while getting asset from ledger: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+	if assetAsBytes == nil {
+		response.Message = fmt.Sprintf("Digital asset with id %s not found", assetID)
+		logger.Info(response.Message)
+		return response
+	}
+
+	var asset DigitalAsset
+	err = json.Unmarshal(assetAsBytes, &asset)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while unmarshling asset: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+
+	view, err := buildVerificationsView(ctx, PolicyScopeAsset, asset.AssetType, asset.Verifications, nil, "", activeOnly)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while evaluating verifications: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+
+	response.Success = true
+	response.Message = fmt.Sprintf("Successfully fetched verifications of asset %s", assetID)
+	logger.Info(response.Message)
+	response.Data = view
 	return response
 }
 
-// GetAssetByOwner returns all assests of given owner
+// GetAssetByOwner returns all assests of given owner, including assets co-owned by them
 func (da *DigitalAssetContract) GetAssetByOwner(ctx contractapi.TransactionContextInterface, owner string) Response {
 	response := Response{
 		TxID:    ctx.GetStub().GetTxID(),
@@ -432,9 +514,12 @@ func (da *DigitalAssetContract) GetAssetByOwner(ctx contractapi.TransactionConte
 
 	var richQuery string = fmt.Sprintf(`{
 		"selector": {
-		   "owner": "%s"
+		   "$or": [
+			  {"owner": "%s"},
+			  {"owners": {"$elemMatch": {"akcessId": "%s"}}}
+		   ]
 		}
-	}`, owner)
+	}`, owner, owner)
 	resultIterator, err := ctx.GetStub().GetQueryResult(richQuery)
 	if err != nil {
 		response.Message = fmt.Sprintf("Error while fetching query result: %s", err.Error())
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"encoding/json"
 	"fmt"
 	"strconv"
 	"time"
@@ -10,62 +11,548 @@ import (
 
 // User describes basic details of user
 type User struct {
-	ObjectType    string                    `json:"docType"`
-	AkcessID      string                    `json:"akcessId"`
-	Verifications map[string][]Verification `json:"verifications"`
+	ObjectType           string                           `json:"docType"`
+	AkcessID             string                           `json:"akcessId"`
+	Verifications        map[string][]Verification        `json:"verifications"`
+	RevokedVerifications map[string][]RevokedVerification `json:"revokedVerifications"` // attestations verifiers withdrew, per profile field
+	Commitments          map[string]ProfileCommitment     `json:"commitments"`          // salted hash of value of each profile field
+	IdentityHash         string                           `json:"identityHash"`         // hash of MSP ID and identity which registered the user
+	SigningKeys          []SigningKey                     `json:"signingKeys"`          // public keys document signatures are verified with
+}
+
+// Signing key algorithms
+const (
+	KeyAlgorithmECDSAP256 = "ecdsa-p256" // ASN.1 DER signature over SHA-256 of message
+	KeyAlgorithmEd25519   = "ed25519"
+)
+
+// Signing key statuses
+const (
+	KeyActive  = "active"
+	KeyRevoked = "revoked"
+)
+
+// SigningKey public key of user, signatures are verified with active keys only
+type SigningKey struct {
+	KeyID     string     `json:"keyId"`
+	Algorithm string     `json:"algorithm"`
+	PublicKey string     `json:"publicKey"` // PEM encoded PKIX public key
+	Status    string     `json:"status"`
+	AddedAt   time.Time  `json:"addedAt"`
+	RevokedAt *time.Time `json:"revokedAt,omitempty"`
+}
+
+// ErasedAkcessID replaces AKcessID of erased user in records which can't be deleted, e.g. signatures
+const ErasedAkcessID = "erased"
+
+// UserTombstone left in place of erased user, keeps AKcessID reserved for identity which registered it
+type UserTombstone struct {
+	ObjectType   string    `json:"docType"`
+	AkcessID     string    `json:"akcessId"`
+	IdentityHash string    `json:"identityHash"` // empty when identity is unknown, AKcessID can't be registered again
+	ErasedBy     string    `json:"erasedBy"`
+	ErasedAt     time.Time `json:"erasedAt"`
+}
+
+// ProfileCommitment salted SHA-256 hash of profile field value, value and salt are kept off-chain by user
+type ProfileCommitment struct {
+	Commitment string    `json:"commitment"`
+	UpdatedAt  time.Time `json:"updatedAt"`
+}
+
+// CommitmentCheck result of checking disclosed profile field value against its commitment
+type CommitmentCheck struct {
+	ProfileField string            `json:"profileField"`
+	Commitment   string            `json:"commitment"`
+	Matches      bool              `json:"matches"` // disclosed value and salt match current commitment
+	Verified     VerificationsView `json:"verified"`
+}
+
+// Reason codes verifier can give when revoking its verification
+var revocationReasons = []string{"issued-in-error", "evidence-invalid", "fraud-suspected", "superseded", "other"}
+
+// RevokedVerification verification withdrawn by the verifier who made it
+type RevokedVerification struct {
+	Verification Verification `json:"verification"`
+	ReasonCode   string       `json:"reasonCode"`
+	RevokedBy    string       `json:"revokedBy"`
+	RevokedAt    time.Time    `json:"revokedAt"` // tx timestamp of revocation
+	TxID         string       `json:"txId"`
+}
+
+// Verifier accreditation statuses
+const (
+	VerifierPending   = "pending"
+	VerifierApproved  = "approved"
+	VerifierSuspended = "suspended"
+	VerifierRevoked   = "revoked"
+)
+
+// verifierTransitions lists statuses verifier can be moved to from its current status
+var verifierTransitions = map[string][]string{
+	VerifierPending:   {VerifierApproved, VerifierRevoked},
+	VerifierApproved:  {VerifierSuspended, VerifierRevoked},
+	VerifierSuspended: {VerifierApproved, VerifierRevoked},
 }
 
 // Verifier schema
 type Verifier struct {
-	ObjectType    string `json:"docType"`
-	AkcessID      string `json:"akcessId"` // AKcessID of a verifier
-	VerifierName  string `json:"verifierName"`
-	VerifierGrade string `json:"grade"`
+	ObjectType     string                   `json:"docType"`
+	AkcessID       string                   `json:"akcessId"` // AKcessID of a verifier
+	VerifierName   string                   `json:"verifierName"`
+	VerifierGrade  string                   `json:"grade"`
+	Status         string                   `json:"status"` // accreditation status of verifier
+	StatusHistory  []VerifierStatusChange   `json:"statusHistory"`
+	Version        int                      `json:"version"` // incremented on every profile update
+	ProfileHistory []VerifierProfileVersion `json:"profileHistory"`
+}
+
+// VerifierProfileVersion name and grade verifier had in given version of its profile
+type VerifierProfileVersion struct {
+	Version       int       `json:"version"`
+	VerifierName  string    `json:"verifierName"`
+	VerifierGrade string    `json:"grade"`
+	UpdatedBy     string    `json:"updatedBy"`
+	UpdatedAt     time.Time `json:"updatedAt"`
+}
+
+// VerifierStatusChange records accreditation status change of verifier
+type VerifierStatusChange struct {
+	Status    string    `json:"status"`
+	Reason    string    `json:"reason"`
+	ChangedBy string    `json:"changedBy"` // AKcessID of identity who changed the status
+	TimeStamp time.Time `json:"timeStamp"` // tx timestamp of the change
+}
+
+// Verification policy scopes
+const (
+	PolicyScopeProfile  = "profile"
+	PolicyScopeDocument = "document"
+	PolicyScopeAsset    = "asset"
+)
+
+// Grade single level of verifier grade taxonomy, higher level means stronger grade
+type Grade struct {
+	Code  string `json:"code"`
+	Name  string `json:"name"`
+	Level int    `json:"level"`
+}
+
+// GradeTaxonomy ordered list of grades verifiers can hold
+type GradeTaxonomy struct {
+	ObjectType string    `json:"docType"`
+	Grades     []Grade   `json:"grades"`
+	UpdatedBy  string    `json:"updatedBy"`
+	UpdatedAt  time.Time `json:"updatedAt"`
 }
 
-// Verification schema
+// VerificationPolicy minimum grade verifier needs to verify profile field, document type or asset type
+type VerificationPolicy struct {
+	ObjectType string    `json:"docType"`
+	Scope      string    `json:"scope"`  // profile, document or asset
+	Target     string    `json:"target"` // profile field, document type or asset type
+	MinGrade   string    `json:"minGrade"`
+	UpdatedBy  string    `json:"updatedBy"`
+	UpdatedAt  time.Time `json:"updatedAt"`
+}
+
+// PolicyCompliance tells if verifications of field, document or asset meet verification policy
+type PolicyCompliance struct {
+	RequiredGrade string `json:"requiredGrade"` // empty when no policy is set
+	MeetsPolicy   bool   `json:"meetsPolicy"`
+}
+
+// Verification schema, verifier details are joined from verifier registry on read
 type Verification struct {
-	VerifierObj Verifier  `json:"verifier"`
-	ExpirtyDate time.Time `json:"expiryDate"` // when verification will expire
+	VerifierID      string    `json:"verifierId"`           // AKcessID of verifier
+	VerifierVersion int       `json:"verifierVersion"`      // version of verifier profile at attestation time
+	ExpirtyDate     time.Time `json:"expiryDate"`           // when verification will expire
+	Commitment      string    `json:"commitment,omitempty"` // profile field commitment or document version hash verifier attested to
+	DocumentVersion int       `json:"documentVersion,omitempty"`
+	TxTimestamp     time.Time `json:"txTimestamp"` // tx timestamp of verifying transaction
+}
+
+// UnmarshalJSON reads verifications stored before verifier references were introduced,
+// those embed full verifier snapshot instead of verifierId
+func (v *Verification) UnmarshalJSON(data []byte) error {
+	type verification Verification
+	var stored struct {
+		verification
+		LegacyVerifier *Verifier `json:"verifier"`
+	}
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return err
+	}
+	*v = Verification(stored.verification)
+	if v.VerifierID == "" && stored.LegacyVerifier != nil {
+		v.VerifierID = stored.LegacyVerifier.AkcessID
+	}
+	return nil
 }
 
 // Document structure
 type Document struct {
-	ObjectType    string         `json:"docType"`
-	DocumentID    string         `json:"documentID"`
-	DocumentHash  []string       `json:"documentHash"`
-	Signature     []Signature    `json:"signature"`
-	AkcessID      string         `json:"akcessId"` // AKcessID of user who owns the document
-	Verifications []Verification `json:"verifications"`
+	ObjectType    string            `json:"docType"`
+	DocumentID    string            `json:"documentID"`
+	DocumentType  string            `json:"documentType"` // e.g. passport, used to look up verification policy
+	Versions      []DocumentVersion `json:"versions"`     // numbered from 1, last one is current
+	Signature     []Signature       `json:"signature"`
+	AkcessID      string            `json:"akcessId"` // AKcessID of user who owns the document
+	Verifications []Verification    `json:"verifications"`
+	SigningStatus string            `json:"signingStatus"` // stored status, see EffectiveSigningStatus
+	Workflow      *SigningWorkflow  `json:"workflow,omitempty"`
+	Status        string            `json:"status"` // stored lifecycle status, see EffectiveStatus
+	StatusReason  string            `json:"statusReason,omitempty"`
+	ReplacedBy    string            `json:"replacedBy,omitempty"` // ID of document superseding this one
+	ExpiresAt     *time.Time        `json:"expiresAt,omitempty"`
+	StatusChanged *StatusChange     `json:"statusChanged,omitempty"`
+}
+
+// Document lifecycle statuses
+const (
+	DocumentActive     = "active"
+	DocumentRevoked    = "revoked"
+	DocumentSuperseded = "superseded"
+	DocumentExpired    = "expired" // expiry date passed, never stored
+)
+
+// StatusChange who changed lifecycle status of document and when
+type StatusChange struct {
+	By string    `json:"by"`
+	At time.Time `json:"at"`
+}
+
+// Document signing statuses
+const (
+	SigningDraft              = "draft"
+	SigningAwaitingSignatures = "awaitingsignatures"
+	SigningCompleted          = "completed"
+	SigningExpired            = "expired" // deadline passed before enough signers signed, never stored
+)
+
+// Signing orders of workflow
+const (
+	SigningSequential = "sequential" // signers sign one after another in listed order
+	SigningParallel   = "parallel"
+)
+
+// SigningWorkflow signers required to sign document defined by its owner
+type SigningWorkflow struct {
+	Signers         []string   `json:"signers"` // AKcessIDs of required signers
+	Order           string     `json:"order"`
+	Deadline        time.Time  `json:"deadline"`
+	Quorum          int        `json:"quorum"`          // signatures needed to complete, 0 means all signers
+	DocumentVersion int        `json:"documentVersion"` // version being signed, set when signing starts
+	DefinedAt       time.Time  `json:"definedAt"`
+	StartedAt       *time.Time `json:"startedAt,omitempty"`
+}
+
+// SigningState signing status of document with signers who signed and who are still expected to sign
+type SigningState struct {
+	DocumentID     string           `json:"documentID"`
+	DocumentStatus string           `json:"documentStatus"` // effective lifecycle status of the document
+	Status         string           `json:"status"`
+	Workflow       *SigningWorkflow `json:"workflow"`
+	Signed         []string         `json:"signed"`
+	Pending        []string         `json:"pending"` // signers who can sign now, in sequential order only the next one
+}
+
+// PendingSignature document waiting for signature of signer
+type PendingSignature struct {
+	DocumentID      string    `json:"documentID"`
+	Owner           string    `json:"owner"`
+	DocumentVersion int       `json:"documentVersion"`
+	Hash            string    `json:"hash"`
+	Deadline        time.Time `json:"deadline"`
+}
+
+// Document verification request statuses
+const (
+	RequestPending  = "pending"
+	RequestApproved = "approved"
+	RequestRejected = "rejected"
+)
+
+// DocVerificationRequest request of document owner to verifier to verify the document
+type DocVerificationRequest struct {
+	ObjectType      string     `json:"docType"`
+	RequestID       string     `json:"requestId"` // tx ID of request
+	DocumentID      string     `json:"documentID"`
+	DocumentVersion int        `json:"documentVersion"` // version current at request, version verified once approved
+	Owner           string     `json:"owner"`
+	RequestedBy     string     `json:"requestedBy,omitempty"` // owner or receiver of share with verify permission
+	VerifierID      string     `json:"verifierId"`
+	Status          string     `json:"status"`
+	Reason          string     `json:"reason,omitempty"` // why verifier rejected the request
+	RequestedAt     time.Time  `json:"requestedAt"`
+	RespondedAt     *time.Time `json:"respondedAt,omitempty"`
+}
+
+// LegacyHashAlgorithm algorithm of versions made from documentHash entries of documents stored before versioning
+const LegacyHashAlgorithm = "unspecified"
+
+// hashAlgorithms supported document hash algorithms with their digest size in bytes
+var hashAlgorithms = map[string]int{
+	"sha256": 32,
+	"sha384": 48,
+	"sha512": 64,
+}
+
+// DocumentVersion content hash of one version of document
+type DocumentVersion struct {
+	Version       int       `json:"version"`
+	Hash          string    `json:"hash"` // hex encoded digest of document content
+	HashAlgorithm string    `json:"hashAlgorithm"`
+	Note          string    `json:"note"`
+	Author        string    `json:"author"`
+	CreatedAt     time.Time `json:"createdAt"`
+}
+
+// UnmarshalJSON reads documents stored before versioning was introduced. Their documentHash entries become
+// versions and their signatures and verifications are tied to the last of them, as documents couldn't change
+func (d *Document) UnmarshalJSON(data []byte) error {
+	type document Document
+	var stored struct {
+		document
+		LegacyHashes []string `json:"documentHash"`
+	}
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return err
+	}
+	*d = Document(stored.document)
+	if len(d.Versions) > 0 || len(stored.LegacyHashes) == 0 {
+		return nil
+	}
+
+	for i, hash := range stored.LegacyHashes {
+		d.Versions = append(d.Versions, DocumentVersion{
+			Version:       i + 1,
+			Hash:          hash,
+			HashAlgorithm: LegacyHashAlgorithm,
+			Author:        d.AkcessID,
+		})
+	}
+	latest := d.Versions[len(d.Versions)-1]
+	for i := range d.Signature {
+		if d.Signature[i].DocumentVersion == 0 {
+			d.Signature[i].DocumentVersion = latest.Version
+		}
+	}
+	for i := range d.Verifications {
+		if d.Verifications[i].DocumentVersion == 0 {
+			d.Verifications[i].DocumentVersion = latest.Version
+			d.Verifications[i].Commitment = latest.Hash
+		}
+	}
+	return nil
+}
+
+// LatestVersion returns current version of document, false if document has no versions
+func (d Document) LatestVersion() (DocumentVersion, bool) {
+	if len(d.Versions) == 0 {
+		return DocumentVersion{}, false
+	}
+	return d.Versions[len(d.Versions)-1], true
 }
 
 // Signature structure
 type Signature struct {
-	SignatureHash string    `json:"signatureHash"`
-	OTP           string    `json:"otp"`
-	AkcessID      string    `json:"akcessId"`  // AKcessID of user who signs
-	TimeStamp     time.Time `json:"timeStamp"` // timestamp when signature is performed
+	SignatureHash   string    `json:"signatureHash"`          // base64 encoded signature over hash of signed version
+	KeyID           string    `json:"keyId"`                  // signing key of signer signature was verified with
+	OTPHash         string    `json:"otpHash"`                // salted hash of OTP, salt is kept in private data collection
+	OTPChallenge    string    `json:"otpChallenge,omitempty"` // ID of OTP challenge signer answered
+	AkcessID        string    `json:"akcessId"`               // AKcessID of user who signs
+	TimeStamp       time.Time `json:"timeStamp"`              // timestamp when signature is performed, as supplied by signer
+	TxTimestamp     time.Time `json:"txTimestamp"`            // tx timestamp of signing transaction
+	DocumentVersion int       `json:"documentVersion"`        // version of document which was signed
 }
 
 // DocumentShare document object for share doc
 type DocumentShare struct {
-	ObjectType string   `json:"docType"`
-	SharingID  string   `json:"sharingid"`
-	Sender     string   `json:"sender"`
-	Receivers  []string `json:"receivers"`
-	DocumentID string   `json:"documentID"`
+	ObjectType      string                   `json:"docType"`
+	SharingID       string                   `json:"sharingid"`
+	Sender          string                   `json:"sender"`
+	ReceiverHashes  []string                 `json:"receiverHashes"` // salted hashes of receivers, receivers are kept in private data collection
+	DocumentID      string                   `json:"documentID"`
+	ParentSharingID string                   `json:"parentSharingId,omitempty"` // share this one was reshared from
+	Permissions     []string                 `json:"permissions"`
+	ValidFrom       time.Time                `json:"validFrom"`
+	ValidUntil      time.Time                `json:"validUntil"` // zero for shares made before validity windows, those don't expire
+	Status          string                   `json:"status"`     // stored status, see EffectiveStatus
+	RevokedAt       *time.Time               `json:"revokedAt,omitempty"`
+	Responses       map[string]ShareResponse `json:"responses"` // acknowledgements of receivers keyed by receiver hash
+	CreatedAt       time.Time                `json:"createdAt"`
+}
+
+// Share permissions
+const (
+	SharePermissionView    = "view"
+	SharePermissionVerify  = "verify"
+	SharePermissionReshare = "reshare"
+)
+
+var sharePermissions = []string{SharePermissionView, SharePermissionVerify, SharePermissionReshare}
+
+// Share statuses, only active and revoked are stored
+const (
+	ShareActive    = "active"
+	ShareRevoked   = "revoked"
+	ShareScheduled = "scheduled" // validity window didn't start yet
+	ShareExpired   = "expired"
+)
+
+// Receiver responses to share
+const (
+	ShareAcknowledged = "acknowledged"
+	ShareDeclined     = "declined"
+)
+
+// ShareResponse receiver's acknowledgement of share
+type ShareResponse struct {
+	Status      string    `json:"status"`
+	RespondedAt time.Time `json:"respondedAt"`
+}
+
+// ShareAccess result of share access check done by off-chain document storage
+type ShareAccess struct {
+	SharingID   string    `json:"sharingid"`
+	DocumentID  string    `json:"documentID"`
+	Receiver    string    `json:"receiver"`
+	Allowed     bool      `json:"allowed"`
+	Reason      string    `json:"reason,omitempty"` // why access is denied
+	Permissions []string  `json:"permissions"`
+	ValidUntil  time.Time `json:"validUntil"`
 }
 
 // DigitalAsset AKcess digital asset
 type DigitalAsset struct {
-	UniqueAssetID string            `json:"uniqueAssetID"`
-	AssetType     string            `json:"assetType"`
-	Owner         string            `json:"owner"`
-	Metadata      map[string]string `json:"metadata"`
-	LinkedDocs    []string          `json:"linkedDocs"`
-	Verifications []Verification    `json:"verifications"`
-	Description   string            `json:"description"`
-	AssetDocHash  string            `json:"assetDocHash"`
+	ObjectType      string            `json:"docType"`
+	UniqueAssetID   string            `json:"uniqueAssetID"`
+	AssetType       string            `json:"assetType"`
+	Owner           string            `json:"owner"`            // sole owner, or co-owner with largest share when asset has co-owners
+	Owners          []AssetOwner      `json:"owners,omitempty"` // co-owners with their shares, empty when asset has a sole owner
+	Metadata        map[string]string `json:"metadata"`
+	LinkedDocs      []string          `json:"linkedDocs"`
+	Verifications   []Verification    `json:"verifications"`
+	Description     string            `json:"description"`
+	AssetDocHash    string            `json:"assetDocHash"`
+	PendingTransfer string            `json:"pendingTransfer,omitempty"` // ID of transfer offer waiting for recipient
+	ApprovalRules   map[string]string `json:"approvalRules,omitempty"`   // approval rule of co-owners by action, default rules apply to missing actions
+}
+
+// AssetOwner co-owner of digital asset
+type AssetOwner struct {
+	AkcessID string `json:"akcessId"`
+	Share    int    `json:"share"` // in basis points, shares of all co-owners sum to FullShare
+}
+
+// Actions on digital asset which need approval of co-owners
+const (
+	AssetActionTransfer = "transfer"     // offering whole asset or a share of it
+	AssetActionLink     = "linkdocument" // linking document to asset
+	AssetActionRemove   = "remove"       // removing asset from ledger
+	AssetActionLien     = "lien"         // placing lien on asset
+	AssetActionMetadata = "metadata"     // updating metadata of asset
+	AssetActionRule     = "rule"         // changing approval rule, always needs all co-owners
+)
+
+// Approval rules of co-owners
+const (
+	ApprovalAll      = "all"      // every co-owner approves
+	ApprovalMajority = "majority" // co-owners holding more than half of shares approve
+	ApprovalAny      = "any"      // any single co-owner can act alone
+)
+
+// AssetApproval co-owner's approval of action on digital asset
+type AssetApproval struct {
+	ObjectType string    `json:"docType"`
+	AssetID    string    `json:"assetId"`
+	Action     string    `json:"action"`
+	Target     string    `json:"target"` // what action is done with, see ApproveAssetAction
+	Approver   string    `json:"approver"`
+	ApprovedAt time.Time `json:"approvedAt"`
+}
+
+// Asset transfer offer statuses
+const (
+	TransferPending   = "pending"
+	TransferAccepted  = "accepted"
+	TransferRejected  = "rejected"
+	TransferCancelled = "cancelled"
+	TransferExpired   = "expired" // offer expired before recipient responded, never stored
+)
+
+// TransferOffer offer of asset owner to transfer asset to recipient, asset changes owner when recipient accepts it
+type TransferOffer struct {
+	ObjectType  string     `json:"docType"`
+	OfferID     string     `json:"offerId"` // tx ID of offering transaction
+	AssetID     string     `json:"assetId"`
+	From        string     `json:"from"`
+	To          string     `json:"to"`
+	Share       int        `json:"share,omitempty"` // basis points of From's share offered, 0 when whole asset is offered
+	Status      string     `json:"status"`          // stored status, see EffectiveStatus
+	CreatedAt   time.Time  `json:"createdAt"`
+	ExpiresAt   time.Time  `json:"expiresAt"`
+	RespondedAt *time.Time `json:"respondedAt,omitempty"`
+}
+
+// Types of asset metadata values
+const (
+	MetadataString  = "string"
+	MetadataNumber  = "number"
+	MetadataInteger = "integer"
+	MetadataBoolean = "boolean"
+	MetadataDate    = "date" // date in ISO format
+)
+
+// MetadataField rules metadata value under one key has to follow
+type MetadataField struct {
+	Type     string   `json:"type"`
+	Required bool     `json:"required"`
+	Pattern  string   `json:"pattern,omitempty"` // regular expression whole value has to match
+	Enum     []string `json:"enum,omitempty"`    // allowed values, any value when empty
+}
+
+// AssetTypeSchema asset type registered by admin with schema of its metadata
+type AssetTypeSchema struct {
+	ObjectType     string                   `json:"docType"`
+	AssetType      string                   `json:"assetType"`
+	Description    string                   `json:"description"`
+	Fields         map[string]MetadataField `json:"fields"`
+	AllowExtraKeys bool                     `json:"allowExtraKeys"` // metadata can have keys which are not in fields
+	Version        int                      `json:"version"`        // increased each time schema is changed
+	UpdatedBy      string                   `json:"updatedBy"`
+	UpdatedAt      time.Time                `json:"updatedAt"`
+}
+
+// Lien statuses
+const (
+	LienActive   = "active"
+	LienReleased = "released"
+	LienExpired  = "expired" // lien expired before it was released, never stored
+)
+
+// Lien encumbrance registered by lienholder on digital asset, asset can't be transferred while lien is active
+type Lien struct {
+	ObjectType string     `json:"docType"`
+	LienID     string     `json:"lienId"` // tx ID of placing transaction
+	AssetID    string     `json:"assetId"`
+	Lienholder string     `json:"lienholder"`
+	AmountRef  string     `json:"amountRef"` // reference to secured amount kept off chain
+	PlacedBy   string     `json:"placedBy"`
+	PlacedAt   time.Time  `json:"placedAt"`
+	ExpiresAt  *time.Time `json:"expiresAt,omitempty"` // nil when lien lasts until released
+	Status     string     `json:"status"`              // stored status, see EffectiveStatus
+	ReleasedAt *time.Time `json:"releasedAt,omitempty"`
+}
+
+// DigitalAssetView asset details returned by queries together with computed verification state
+type DigitalAssetView struct {
+	DigitalAsset
+	Verifications []VerificationStatus `json:"verifications"`
+	Verified      bool                 `json:"verified"`
+	Policy        PolicyCompliance     `json:"policy"`
+	Liens         []Lien               `json:"liens"` // liens not released yet
 }
 
 // Find check if item already exists in slice
@@ -82,7 +569,7 @@ func Find(slice []string, val string) (int, bool) {
 func VerifiersList(v []Verification) []string {
 	var list []string
 	for _, verification := range v {
-		list = append(list, verification.VerifierObj.AkcessID)
+		list = append(list, verification.VerifierID)
 	}
 	return list
 }
@@ -100,6 +587,20 @@ func IsVerifier(ctx contractapi.TransactionContextInterface) bool {
 	return isverifier
 }
 
+// CurrentStatus returns accreditation status of verifier, verifiers registered
+// before accreditation was introduced are treated as pending
+func (v Verifier) CurrentStatus() string {
+	if v.Status == "" {
+		return VerifierPending
+	}
+	return v.Status
+}
+
+// IsApproved checks if verifier is currently allowed to make verifications
+func (v Verifier) IsApproved() bool {
+	return v.ObjectType == "verifier" && v.Status == VerifierApproved
+}
+
 // Remove deletes and element at peticular index from slice
 func Remove(s []Verification, i int) []Verification {
 	s[len(s)-1], s[i] = s[i], s[len(s)-1]
@@ -0,0 +1,468 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// DefaultTransferOfferDays how long transfer offer stays open when no expiry date is given
+const DefaultTransferOfferDays = 7
+
+// OfferAssetTransfer owner offers asset to recipient, asset stays with owner until recipient accepts offer.
+// Offer expires at expiryDate, pass empty date for default expiry. Asset can have only one pending offer,
+// co-owner needs approval of other co-owners by transfer rule of asset. Asset encumbered by active lien can't be offered
+func (da *DigitalAssetContract) OfferAssetTransfer(ctx contractapi.TransactionContextInterface, assetID string, recipient string, expiryDate string) Response {
+	return offerAssetTransfer(ctx, assetID, recipient, 0, expiryDate)
+}
+
+// AcceptAssetTransfer recipient accepts pending offer, asset or offered share becomes owned by recipient
+func (da *DigitalAssetContract) AcceptAssetTransfer(ctx contractapi.TransactionContextInterface, offerID string) Response {
+	response := Response{
+		TxID:    ctx.GetStub().GetTxID(),
+		Success: false,
+		Message: "",
+		Data:    nil,
+	}
+
+	invoker, err := resolveAkcessID(ctx)
+	if err != nil {
+		response.Message = err.Error()
+		logger.Info(response.Message)
+		return response
+	}
+	offer, err := getTransferOffer(ctx, offerID)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while fetching transfer offer from world state: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+	if offer == nil {
+		response.Message = fmt.Sprintf("Transfer offer with id %s doesn't exist", offerID)
+		logger.Info(response.Message)
+		return response
+	}
+	if offer.To != invoker {
+		response.Message = fmt.Sprintf("Transfer offer %s is not made to %s", offerID, invoker)
+		logger.Info(response.Message)
+		return response
+	}
+	user, err := getUser(ctx, invoker)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while fetching user from world state: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+	if user == nil {
+		response.Message = fmt.Sprintf("User with id %s doesn't exist, only registered users can accept assets", invoker)
+		logger.Info(response.Message)
+		return response
+	}
+	txTime, err := getTxTimestamp(ctx)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while getting tx timestamp: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+	err = checkOfferPending(offer, txTime)
+	if err != nil {
+		response.Message = err.Error()
+		logger.Info(response.Message)
+		return response
+	}
+	err = checkNoActiveLien(ctx, offer.AssetID, txTime)
+	if err != nil {
+		response.Message = err.Error()
+		logger.Info(response.Message)
+		return response
+	}
+	asset, err := getAsset(ctx, offer.AssetID)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while getting asset from ledger: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+	if asset == nil {
+		response.Message = fmt.Sprintf("Digital asset with id %s not found", offer.AssetID)
+		logger.Info(response.Message)
+		return response
+	}
+	held := asset.ShareOf(offer.From)
+	if held == 0 || held < offer.Share || asset.PendingTransfer != offerID {
+		response.Message = fmt.Sprintf("Transfer offer %s is no longer valid for digital asset %s", offerID, asset.UniqueAssetID)
+		logger.Info(response.Message)
+		return response
+	}
+
+	if offer.Share == 0 {
+		setAssetOwners(asset, []AssetOwner{{AkcessID: invoker, Share: FullShare}})
+	} else {
+		moveAssetShare(asset, offer.From, invoker, offer.Share)
+	}
+	asset.PendingTransfer = ""
+	err = saveAsset(ctx, asset)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while saving asset in ledger: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+	offer.Status = TransferAccepted
+	offer.RespondedAt = &txTime
+	err = saveTransferOffer(ctx, offer)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while saving transfer offer: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+
+	response.Success = true
+	if offer.Share == 0 {
+		response.Message = fmt.Sprintf("Digital asset with id %s successfully transferred from %s to %s", asset.UniqueAssetID, offer.From, invoker)
+	} else {
+		response.Message = fmt.Sprintf("Share %d of digital asset with id %s successfully transferred from %s to %s", offer.Share, asset.UniqueAssetID, offer.From, invoker)
+	}
+	logger.Info(response.Message)
+	response.Data = asset
+	return response
+}
+
+// RejectAssetTransfer recipient rejects pending offer, asset stays with owner
+func (da *DigitalAssetContract) RejectAssetTransfer(ctx contractapi.TransactionContextInterface, offerID string) Response {
+	return closeTransferOffer(ctx, offerID, TransferRejected)
+}
+
+// CancelAssetTransfer owner withdraws pending offer before recipient responds
+func (da *DigitalAssetContract) CancelAssetTransfer(ctx contractapi.TransactionContextInterface, offerID string) Response {
+	return closeTransferOffer(ctx, offerID, TransferCancelled)
+}
+
+// GetAssetTransferOffers returns transfer offers made by or to given user with their effective status
+func (da *DigitalAssetContract) GetAssetTransferOffers(ctx contractapi.TransactionContextInterface, akcessID string) Response {
+	response := Response{
+		TxID:    ctx.GetStub().GetTxID(),
+		Success: false,
+		Message: "",
+		Data:    nil,
+	}
+
+	var richQuery string = fmt.Sprintf(`{
+		"selector": {
+		   "docType": "%s",
+		   "$or": [
+			  {"from": "%s"},
+			  {"to": "%s"}
+		   ]
+		}
+	}`, TransferObject, akcessID, akcessID)
+	resultIterator, err := ctx.GetStub().GetQueryResult(richQuery)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while fetching query result: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+	defer resultIterator.Close()
+
+	txTime, err := getTxTimestamp(ctx)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while getting tx timestamp: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+	result := []TransferOffer{}
+	for resultIterator.HasNext() {
+		queryResponse, _ := resultIterator.Next()
+
+		offer := new(TransferOffer)
+		_ = json.Unmarshal(queryResponse.Value, offer)
+		offer.Status = offer.EffectiveStatus(txTime)
+		result = append(result, *offer)
+	}
+
+	response.Success = true
+	response.Message = fmt.Sprintf("Successfully fetched transfer offers of %s", akcessID)
+	logger.Info(response.Message)
+	response.Data = result
+	return response
+}
+
+// EffectiveStatus status of offer at given time, pending offers past their expiry are expired
+func (o TransferOffer) EffectiveStatus(now time.Time) string {
+	if o.Status == TransferPending && !now.Before(o.ExpiresAt) {
+		return TransferExpired
+	}
+	return o.Status
+}
+
+// checkOfferPending refuses offers which were already answered, cancelled or are expired
+func checkOfferPending(offer *TransferOffer, now time.Time) error {
+	status := offer.EffectiveStatus(now)
+	if status != TransferPending {
+		return fmt.Errorf("Transfer offer %s is %s", offer.OfferID, status)
+	}
+	return nil
+}
+
+// offerAssetTransfer stores pending offer of invoker's asset, or share of it when share isn't 0, to recipient.
+// Empty expiryDate means offer expires after DefaultTransferOfferDays
+func offerAssetTransfer(ctx contractapi.TransactionContextInterface, assetID string, recipient string, share int, expiryDate string) Response {
+	response := Response{
+		TxID:    ctx.GetStub().GetTxID(),
+		Success: false,
+		Message: "",
+		Data:    nil,
+	}
+
+	invoker, err := resolveAkcessID(ctx)
+	if err != nil {
+		response.Message = err.Error()
+		logger.Info(response.Message)
+		return response
+	}
+	if recipient == "" || recipient == invoker {
+		response.Message = fmt.Sprintf("Recipient of digital asset must be other than %s", invoker)
+		logger.Info(response.Message)
+		return response
+	}
+	asset, err := getAsset(ctx, assetID)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while getting asset from ledger: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+	if asset == nil {
+		response.Message = fmt.Sprintf("Digital asset with id %s not found", assetID)
+		logger.Info(response.Message)
+		return response
+	}
+	target := transferTarget(recipient, share)
+	err = checkAssetApproval(ctx, asset, AssetActionTransfer, target, invoker)
+	if err != nil {
+		response.Message = err.Error()
+		logger.Info(response.Message)
+		return response
+	}
+	if share > asset.ShareOf(invoker) {
+		response.Message = fmt.Sprintf("Offered share %d is greater than share %d of %s", share, asset.ShareOf(invoker), invoker)
+		logger.Info(response.Message)
+		return response
+	}
+
+	txTime, err := getTxTimestamp(ctx)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while getting tx timestamp: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+	err = checkNoActiveLien(ctx, assetID, txTime)
+	if err != nil {
+		response.Message = err.Error()
+		logger.Info(response.Message)
+		return response
+	}
+	if asset.PendingTransfer != "" {
+		pending, err := getTransferOffer(ctx, asset.PendingTransfer)
+		if err != nil {
+			response.Message = fmt.Sprintf("Error while fetching transfer offer from world state: %s", err.Error())
+			logger.Error(response.Message)
+			return response
+		}
+		if pending != nil && pending.EffectiveStatus(txTime) == TransferPending {
+			response.Message = fmt.Sprintf("Digital asset %s already has pending transfer offer %s", assetID, pending.OfferID)
+			logger.Info(response.Message)
+			return response
+		}
+	}
+
+	expiresAt := txTime.AddDate(0, 0, DefaultTransferOfferDays)
+	if expiryDate != "" {
+		expiresAt, err = time.Parse(time.RFC3339, expiryDate)
+		if err != nil {
+			response.Message = fmt.Sprintf("Error while parsing date pass date in ISO format: %s", err.Error())
+			logger.Info(response.Message)
+			return response
+		}
+		policy, err := getTimePolicy(ctx)
+		if err != nil {
+			response.Message = fmt.Sprintf("Error while fetching time policy: %s", err.Error())
+			logger.Error(response.Message)
+			return response
+		}
+		err = policy.CheckExpiryDate(expiresAt, txTime)
+		if err != nil {
+			response.Message = err.Error()
+			logger.Info(response.Message)
+			return response
+		}
+	}
+
+	offer := &TransferOffer{
+		ObjectType: TransferObject,
+		OfferID:    response.TxID,
+		AssetID:    assetID,
+		From:       invoker,
+		To:         recipient,
+		Share:      share,
+		Status:     TransferPending,
+		CreatedAt:  txTime,
+		ExpiresAt:  expiresAt,
+	}
+	err = saveTransferOffer(ctx, offer)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while saving transfer offer: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+	asset.PendingTransfer = offer.OfferID
+	err = saveAsset(ctx, asset)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while saving asset in ledger: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+	err = clearAssetApprovals(ctx, assetID, AssetActionTransfer, target)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while clearing approvals: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+
+	response.Success = true
+	response.Message = fmt.Sprintf("Digital asset with id %s offered to %s until %s", assetID, recipient, expiresAt.Format(time.RFC3339))
+	logger.Info(response.Message)
+	response.Data = offer
+	return response
+}
+
+// closeTransferOffer ends pending offer without transfer, recipient can reject it and owner can cancel it
+func closeTransferOffer(ctx contractapi.TransactionContextInterface, offerID string, status string) Response {
+	response := Response{
+		TxID:    ctx.GetStub().GetTxID(),
+		Success: false,
+		Message: "",
+		Data:    nil,
+	}
+
+	invoker, err := resolveAkcessID(ctx)
+	if err != nil {
+		response.Message = err.Error()
+		logger.Info(response.Message)
+		return response
+	}
+	offer, err := getTransferOffer(ctx, offerID)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while fetching transfer offer from world state: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+	if offer == nil {
+		response.Message = fmt.Sprintf("Transfer offer with id %s doesn't exist", offerID)
+		logger.Info(response.Message)
+		return response
+	}
+	if status == TransferRejected && offer.To != invoker {
+		response.Message = fmt.Sprintf("Transfer offer %s is not made to %s", offerID, invoker)
+		logger.Info(response.Message)
+		return response
+	}
+	if status == TransferCancelled && offer.From != invoker {
+		response.Message = fmt.Sprintf("Transfer offer %s is not made by %s", offerID, invoker)
+		logger.Info(response.Message)
+		return response
+	}
+	txTime, err := getTxTimestamp(ctx)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while getting tx timestamp: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+	err = checkOfferPending(offer, txTime)
+	if err != nil {
+		response.Message = err.Error()
+		logger.Info(response.Message)
+		return response
+	}
+	asset, err := getAsset(ctx, offer.AssetID)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while getting asset from ledger: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+
+	offer.Status = status
+	offer.RespondedAt = &txTime
+	err = saveTransferOffer(ctx, offer)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while saving transfer offer: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+	if asset != nil && asset.PendingTransfer == offerID {
+		asset.PendingTransfer = ""
+		err = saveAsset(ctx, asset)
+		if err != nil {
+			response.Message = fmt.Sprintf("Error while saving asset in ledger: %s", err.Error())
+			logger.Error(response.Message)
+			return response
+		}
+	}
+
+	response.Success = true
+	response.Message = fmt.Sprintf("Transfer offer %s %s by %s", offerID, status, invoker)
+	logger.Info(response.Message)
+	response.Data = offer
+	return response
+}
+
+// getAsset reads digital asset, nil if asset doesn't exist
+func getAsset(ctx contractapi.TransactionContextInterface, assetID string) (*DigitalAsset, error) {
+	assetAsBytes, err := getObject(ctx, AssetObject, assetID)
+	if err != nil {
+		return nil, err
+	}
+	if assetAsBytes == nil {
+		return nil, nil
+	}
+	var asset DigitalAsset
+	err = json.Unmarshal(assetAsBytes, &asset)
+	if err != nil {
+		return nil, err
+	}
+	return &asset, nil
+}
+
+// saveAsset writes digital asset to world state
+func saveAsset(ctx contractapi.TransactionContextInterface, asset *DigitalAsset) error {
+	assetAsBytes, err := json.Marshal(asset)
+	if err != nil {
+		return err
+	}
+	return putObject(ctx, AssetObject, asset.UniqueAssetID, assetAsBytes)
+}
+
+// getTransferOffer reads transfer offer, nil if offer doesn't exist
+func getTransferOffer(ctx contractapi.TransactionContextInterface, offerID string) (*TransferOffer, error) {
+	offerAsBytes, err := getObject(ctx, TransferObject, offerID)
+	if err != nil {
+		return nil, err
+	}
+	if offerAsBytes == nil {
+		return nil, nil
+	}
+	var offer TransferOffer
+	err = json.Unmarshal(offerAsBytes, &offer)
+	if err != nil {
+		return nil, err
+	}
+	return &offer, nil
+}
+
+// saveTransferOffer writes transfer offer to world state
+func saveTransferOffer(ctx contractapi.TransactionContextInterface, offer *TransferOffer) error {
+	offerAsBytes, err := json.Marshal(offer)
+	if err != nil {
+		return err
+	}
+	return putObject(ctx, TransferObject, offer.OfferID, offerAsBytes)
+}
@@ -0,0 +1,334 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// RegisterAssetType admin registers asset type with schema of its metadata, registering existing type replaces its schema.
+// Assets of the type are validated against schema when registered and when their metadata is updated
+func (da *DigitalAssetContract) RegisterAssetType(ctx contractapi.TransactionContextInterface, assetType string, description string, fields map[string]MetadataField, allowExtraKeys bool) Response {
+	response := Response{
+		TxID:    ctx.GetStub().GetTxID(),
+		Success: false,
+		Message: "",
+		Data:    nil,
+	}
+
+	invoker := invokerName(ctx)
+	if !IsAdmin(ctx) {
+		response.Message = fmt.Sprintf("Identity %s is not an admin, only admin can register asset types", invoker)
+		logger.Info(response.Message)
+		return response
+	}
+	if assetType == "" {
+		response.Message = fmt.Sprint("Asset type can't be empty")
+		logger.Info(response.Message)
+		return response
+	}
+	for _, key := range sortedFieldKeys(fields) {
+		err := checkMetadataField(key, fields[key])
+		if err != nil {
+			response.Message = err.Error()
+			logger.Info(response.Message)
+			return response
+		}
+	}
+
+	existing, err := getAssetType(ctx, assetType)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while fetching asset type from world state: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+	txTime, err := getTxTimestamp(ctx)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while getting tx timestamp: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+	schema := AssetTypeSchema{
+		ObjectType:     AssetTypeObject,
+		AssetType:      assetType,
+		Description:    description,
+		Fields:         fields,
+		AllowExtraKeys: allowExtraKeys,
+		Version:        1,
+		UpdatedBy:      invoker,
+		UpdatedAt:      txTime,
+	}
+	if schema.Fields == nil {
+		schema.Fields = map[string]MetadataField{}
+	}
+	if existing != nil {
+		schema.Version = existing.Version + 1
+	}
+	schemaAsBytes, _ := json.Marshal(schema)
+	err = putObject(ctx, AssetTypeObject, assetType, schemaAsBytes)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while saving asset type: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+
+	response.Success = true
+	response.Message = fmt.Sprintf("Asset type %s registered with schema version %d", assetType, schema.Version)
+	logger.Info(response.Message)
+	response.Data = schema
+	return response
+}
+
+// GetAssetType returns metadata schema of asset type, used by clients to build asset forms
+func (da *DigitalAssetContract) GetAssetType(ctx contractapi.TransactionContextInterface, assetType string) Response {
+	response := Response{
+		TxID:    ctx.GetStub().GetTxID(),
+		Success: false,
+		Message: "",
+		Data:    nil,
+	}
+
+	schema, err := getAssetType(ctx, assetType)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while fetching asset type from world state: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+	if schema == nil {
+		response.Message = fmt.Sprintf("Asset type %s is not registered", assetType)
+		logger.Info(response.Message)
+		return response
+	}
+
+	response.Success = true
+	response.Message = fmt.Sprintf("Successfully fetched asset type %s", assetType)
+	logger.Info(response.Message)
+	response.Data = schema
+	return response
+}
+
+// GetAssetTypes returns all registered asset types with their schemas
+func (da *DigitalAssetContract) GetAssetTypes(ctx contractapi.TransactionContextInterface) Response {
+	response := Response{
+		TxID:    ctx.GetStub().GetTxID(),
+		Success: false,
+		Message: "",
+		Data:    nil,
+	}
+
+	resultIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(AssetTypeObject, []string{})
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while fetching asset types: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+	defer resultIterator.Close()
+
+	result := []AssetTypeSchema{}
+	for resultIterator.HasNext() {
+		queryResponse, err := resultIterator.Next()
+		if err != nil {
+			response.Message = fmt.Sprintf("Error while iterating asset types: %s", err.Error())
+			logger.Error(response.Message)
+			return response
+		}
+		var schema AssetTypeSchema
+		json.Unmarshal(queryResponse.Value, &schema)
+		result = append(result, schema)
+	}
+
+	response.Success = true
+	response.Message = fmt.Sprint("Successfully fetched asset types")
+	logger.Info(response.Message)
+	response.Data = result
+	return response
+}
+
+// UpdateAssetMetadata owner replaces metadata of asset, co-owners need approval by metadata rule of asset.
+// New metadata is validated against current schema of asset type
+func (da *DigitalAssetContract) UpdateAssetMetadata(ctx contractapi.TransactionContextInterface, assetID string, metadata map[string]string) Response {
+	response := Response{
+		TxID:    ctx.GetStub().GetTxID(),
+		Success: false,
+		Message: "",
+		Data:    nil,
+	}
+
+	invoker, err := resolveAkcessID(ctx)
+	if err != nil {
+		response.Message = err.Error()
+		logger.Info(response.Message)
+		return response
+	}
+	asset, err := getAsset(ctx, assetID)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while getting asset from ledger: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+	if asset == nil {
+		response.Message = fmt.Sprintf("Digital asset with id %s not found", assetID)
+		logger.Info(response.Message)
+		return response
+	}
+	err = checkAssetApproval(ctx, asset, AssetActionMetadata, assetID, invoker)
+	if err != nil {
+		response.Message = err.Error()
+		logger.Info(response.Message)
+		return response
+	}
+	err = validateAssetMetadata(ctx, asset.AssetType, metadata)
+	if err != nil {
+		response.Message = err.Error()
+		logger.Info(response.Message)
+		return response
+	}
+
+	asset.Metadata = metadata
+	err = saveAsset(ctx, asset)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while saving asset in ledger: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+	err = clearAssetApprovals(ctx, assetID, AssetActionMetadata, assetID)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while clearing approvals: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+
+	response.Success = true
+	response.Message = fmt.Sprintf("Metadata of digital asset %s updated by %s", assetID, invoker)
+	logger.Info(response.Message)
+	response.Data = asset
+	return response
+}
+
+// Validate checks metadata against schema, keys are checked in sorted order so the same error is reported on every peer
+func (s AssetTypeSchema) Validate(metadata map[string]string) error {
+	for _, key := range sortedFieldKeys(s.Fields) {
+		field := s.Fields[key]
+		value, found := metadata[key]
+		if !found || value == "" {
+			if field.Required {
+				return fmt.Errorf("Metadata key %s is required for asset type %s", key, s.AssetType)
+			}
+			continue
+		}
+		err := field.check(value)
+		if err != nil {
+			return fmt.Errorf("Metadata key %s of asset type %s: %s", key, s.AssetType, err.Error())
+		}
+	}
+	if !s.AllowExtraKeys {
+		keys := []string{}
+		for key := range metadata {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if _, found := s.Fields[key]; !found {
+				return fmt.Errorf("Metadata key %s is not part of schema of asset type %s", key, s.AssetType)
+			}
+		}
+	}
+	return nil
+}
+
+// check refuses value which is not of field type, doesn't match its pattern or is not one of its enumerated values
+func (f MetadataField) check(value string) error {
+	var err error
+	switch f.Type {
+	case MetadataNumber:
+		_, err = strconv.ParseFloat(value, 64)
+	case MetadataInteger:
+		_, err = strconv.ParseInt(value, 10, 64)
+	case MetadataBoolean:
+		_, err = strconv.ParseBool(value)
+	case MetadataDate:
+		_, err = time.Parse(time.RFC3339, value)
+	}
+	if err != nil {
+		return fmt.Errorf("value %s is not a valid %s", value, f.Type)
+	}
+	if f.Pattern != "" {
+		matched, err := regexp.MatchString("^(?:"+f.Pattern+")$", value)
+		if err != nil || !matched {
+			return fmt.Errorf("value %s doesn't match pattern %s", value, f.Pattern)
+		}
+	}
+	if len(f.Enum) > 0 {
+		if _, found := Find(f.Enum, value); !found {
+			return fmt.Errorf("value %s is not one of allowed values", value)
+		}
+	}
+	return nil
+}
+
+// checkMetadataField refuses field definitions with unknown type, invalid pattern or enumerated values not valid for the field
+func checkMetadataField(key string, field MetadataField) error {
+	if key == "" {
+		return fmt.Errorf("Metadata key can't be empty")
+	}
+	if _, found := Find([]string{MetadataString, MetadataNumber, MetadataInteger, MetadataBoolean, MetadataDate}, field.Type); !found {
+		return fmt.Errorf("Metadata key %s has unknown type %s, use string, number, integer, boolean or date", key, field.Type)
+	}
+	if field.Pattern != "" {
+		_, err := regexp.Compile(field.Pattern)
+		if err != nil {
+			return fmt.Errorf("Metadata key %s has invalid pattern: %s", key, err.Error())
+		}
+	}
+	for _, value := range field.Enum {
+		err := MetadataField{Type: field.Type, Pattern: field.Pattern}.check(value)
+		if err != nil {
+			return fmt.Errorf("Metadata key %s has invalid allowed value: %s", key, err.Error())
+		}
+	}
+	return nil
+}
+
+// validateAssetMetadata checks metadata against schema of registered asset type
+func validateAssetMetadata(ctx contractapi.TransactionContextInterface, assetType string, metadata map[string]string) error {
+	schema, err := getAssetType(ctx, assetType)
+	if err != nil {
+		return fmt.Errorf("Error while fetching asset type from world state: %s", err.Error())
+	}
+	if schema == nil {
+		return fmt.Errorf("Asset type %s is not registered", assetType)
+	}
+	return schema.Validate(metadata)
+}
+
+// getAssetType reads schema of asset type, nil if type isn't registered
+func getAssetType(ctx contractapi.TransactionContextInterface, assetType string) (*AssetTypeSchema, error) {
+	schemaAsBytes, err := getObject(ctx, AssetTypeObject, assetType)
+	if err != nil {
+		return nil, err
+	}
+	if schemaAsBytes == nil {
+		return nil, nil
+	}
+	var schema AssetTypeSchema
+	err = json.Unmarshal(schemaAsBytes, &schema)
+	if err != nil {
+		return nil, err
+	}
+	return &schema, nil
+}
+
+// sortedFieldKeys keys of schema fields in sorted order
+func sortedFieldKeys(fields map[string]MetadataField) []string {
+	keys := []string{}
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
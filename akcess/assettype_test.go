@@ -0,0 +1,116 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestAssetTypeSchemaValidate(t *testing.T) {
+	schema := AssetTypeSchema{
+		AssetType: "vehicle",
+		Fields: map[string]MetadataField{
+			"vin":        {Type: MetadataString, Required: true, Pattern: "[A-HJ-NPR-Z0-9]{17}"},
+			"year":       {Type: MetadataInteger, Required: true},
+			"weight":     {Type: MetadataNumber},
+			"electric":   {Type: MetadataBoolean},
+			"registered": {Type: MetadataDate},
+			"color":      {Type: MetadataString, Enum: []string{"red", "blue"}},
+		},
+	}
+	valid := func(changes map[string]string) map[string]string {
+		metadata := map[string]string{"vin": "1HGCM82633A004352", "year": "2020"}
+		for key, value := range changes {
+			if value == "" {
+				delete(metadata, key)
+				continue
+			}
+			metadata[key] = value
+		}
+		return metadata
+	}
+
+	tests := []struct {
+		name           string
+		metadata       map[string]string
+		allowExtraKeys bool
+		expectedError  string
+	}{
+		{name: "required keys only", metadata: valid(nil)},
+		{name: "all keys", metadata: valid(map[string]string{"weight": "1320.5", "electric": "true", "registered": "2020-05-01T00:00:00Z", "color": "red"})},
+		{name: "missing required key", metadata: valid(map[string]string{"year": ""}), expectedError: "Metadata key year is required for asset type vehicle"},
+		{name: "value not matching pattern", metadata: valid(map[string]string{"vin": "123"}), expectedError: "Metadata key vin of asset type vehicle: value 123 doesn't match pattern"},
+		{name: "pattern must match whole value", metadata: valid(map[string]string{"vin": "1HGCM82633A004352X"}), expectedError: "doesn't match pattern"},
+		{name: "invalid integer", metadata: valid(map[string]string{"year": "20.5"}), expectedError: "value 20.5 is not a valid integer"},
+		{name: "invalid number", metadata: valid(map[string]string{"weight": "heavy"}), expectedError: "value heavy is not a valid number"},
+		{name: "invalid boolean", metadata: valid(map[string]string{"electric": "maybe"}), expectedError: "value maybe is not a valid boolean"},
+		{name: "invalid date", metadata: valid(map[string]string{"registered": "2020-05-01"}), expectedError: "value 2020-05-01 is not a valid date"},
+		{name: "value not allowed", metadata: valid(map[string]string{"color": "green"}), expectedError: "value green is not one of allowed values"},
+		{name: "extra key refused", metadata: valid(map[string]string{"owner": "alice"}), expectedError: "Metadata key owner is not part of schema of asset type vehicle"},
+		{name: "extra key allowed", metadata: valid(map[string]string{"owner": "alice"}), allowExtraKeys: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schema.AllowExtraKeys = tt.allowExtraKeys
+			expectErr(t, schema.Validate(tt.metadata), tt.expectedError)
+		})
+	}
+}
+
+func TestCheckMetadataField(t *testing.T) {
+	tests := []struct {
+		name          string
+		key           string
+		field         MetadataField
+		expectedError string
+	}{
+		{name: "string field", key: "name", field: MetadataField{Type: MetadataString}},
+		{name: "enumerated integer field", key: "doors", field: MetadataField{Type: MetadataInteger, Enum: []string{"2", "4"}}},
+		{name: "empty key", key: "", field: MetadataField{Type: MetadataString}, expectedError: "Metadata key can't be empty"},
+		{name: "unknown type", key: "name", field: MetadataField{Type: "text"}, expectedError: "Metadata key name has unknown type text"},
+		{name: "invalid pattern", key: "name", field: MetadataField{Type: MetadataString, Pattern: "[a-"}, expectedError: "Metadata key name has invalid pattern"},
+		{name: "allowed value of other type", key: "doors", field: MetadataField{Type: MetadataInteger, Enum: []string{"2", "four"}}, expectedError: "Metadata key doors has invalid allowed value: value four is not a valid integer"},
+		{name: "allowed value not matching pattern", key: "code", field: MetadataField{Type: MetadataString, Pattern: "[A-Z]+", Enum: []string{"AB", "cd"}}, expectedError: "Metadata key code has invalid allowed value: value cd doesn't match pattern"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expectErr(t, checkMetadataField(tt.key, tt.field), tt.expectedError)
+		})
+	}
+}
+
+func TestRegisterAssetType(t *testing.T) {
+	l := newAssetLedger(t)
+	l.initLedger()
+	fields := map[string]MetadataField{"year": {Type: MetadataInteger, Required: true}}
+
+	tests := []struct {
+		name            string
+		mspID           string
+		assetType       string
+		fields          map[string]MetadataField
+		expectedVersion int
+		expectedError   string
+	}{
+		{name: "non admin can't register", mspID: "Org1MSP", assetType: "vehicle", fields: fields, expectedError: "only admin can register asset types"},
+		{name: "empty asset type", mspID: "AdminMSP", fields: fields, expectedError: "Asset type can't be empty"},
+		{name: "invalid field", mspID: "AdminMSP", assetType: "vehicle", fields: map[string]MetadataField{"year": {Type: "text"}}, expectedError: "unknown type text"},
+		{name: "admin registers type", mspID: "AdminMSP", assetType: "vehicle", fields: fields, expectedVersion: 1},
+		{name: "registering again bumps version", mspID: "AdminMSP", assetType: "vehicle", fields: fields, expectedVersion: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := new(DigitalAssetContract).RegisterAssetType(l.as(tt.mspID, "admin", nil), tt.assetType, "", tt.fields, false)
+			expectResponse(t, response, tt.expectedError)
+			if tt.expectedError == "" && response.Data.(AssetTypeSchema).Version != tt.expectedVersion {
+				t.Fatalf("expected schema version %d, got %+v", tt.expectedVersion, response.Data)
+			}
+		})
+	}
+
+	l.put(l.compositeKey(AssetObject, "asset1"), DigitalAsset{ObjectType: "asset", UniqueAssetID: "asset1", AssetType: "vehicle", Owner: "alice"})
+	expectResponse(t, new(DigitalAssetContract).UpdateAssetMetadata(l.as("Org1MSP", "alice", nil), "asset1", map[string]string{"year": "new"}), "value new is not a valid integer")
+	expectResponse(t, new(DigitalAssetContract).UpdateAssetMetadata(l.as("Org1MSP", "mallory", nil), "asset1", map[string]string{"year": "2020"}), "not owned by mallory")
+	expectResponse(t, new(DigitalAssetContract).UpdateAssetMetadata(l.as("Org1MSP", "alice", nil), "asset1", map[string]string{"year": "2020"}), "")
+}
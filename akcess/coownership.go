@@ -0,0 +1,439 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// FullShare share of sole owner in basis points
+const FullShare = 10000
+
+// defaultApprovalRules rules of co-owners used until co-owners set their own
+var defaultApprovalRules = map[string]string{
+	AssetActionTransfer: ApprovalAll,
+	AssetActionLink:     ApprovalAny,
+	AssetActionRemove:   ApprovalAll,
+	AssetActionLien:     ApprovalAll,
+	AssetActionMetadata: ApprovalAll,
+	AssetActionRule:     ApprovalAll,
+}
+
+// OfferAssetShare co-owner offers part of their share to recipient, share is in basis points.
+// Recipient becomes co-owner when accepting offer with AcceptAssetTransfer
+func (da *DigitalAssetContract) OfferAssetShare(ctx contractapi.TransactionContextInterface, assetID string, recipient string, share int, expiryDate string) Response {
+	if share <= 0 {
+		response := Response{
+			TxID:    ctx.GetStub().GetTxID(),
+			Success: false,
+			Message: fmt.Sprint("Offered share must be greater than zero"),
+			Data:    nil,
+		}
+		logger.Info(response.Message)
+		return response
+	}
+	return offerAssetTransfer(ctx, assetID, recipient, share, expiryDate)
+}
+
+// ApproveAssetAction co-owner approves action on asset before another co-owner performs it.
+// Target is recipient when whole asset is transferred, "recipient:share" when a share is transferred,
+// document id when document is linked, asset id when asset is removed or its metadata updated, lienholder when lien is placed
+// and "action:rule" when rule is changed
+func (da *DigitalAssetContract) ApproveAssetAction(ctx contractapi.TransactionContextInterface, assetID string, action string, target string) Response {
+	response := Response{
+		TxID:    ctx.GetStub().GetTxID(),
+		Success: false,
+		Message: "",
+		Data:    nil,
+	}
+
+	invoker, err := resolveAkcessID(ctx)
+	if err != nil {
+		response.Message = err.Error()
+		logger.Info(response.Message)
+		return response
+	}
+	if _, found := defaultApprovalRules[action]; !found {
+		response.Message = fmt.Sprintf("Unknown action %s, action must be one of %s", action, strings.Join(assetActions(), ", "))
+		logger.Info(response.Message)
+		return response
+	}
+	if target == "" {
+		response.Message = fmt.Sprint("Target of approved action can't be empty")
+		logger.Info(response.Message)
+		return response
+	}
+	asset, err := getAsset(ctx, assetID)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while getting asset from ledger: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+	if asset == nil {
+		response.Message = fmt.Sprintf("Digital asset with id %s not found", assetID)
+		logger.Info(response.Message)
+		return response
+	}
+	if asset.ShareOf(invoker) == 0 {
+		response.Message = fmt.Sprintf("Identity %s is not an owner of digital asset %s", invoker, assetID)
+		logger.Info(response.Message)
+		return response
+	}
+	txTime, err := getTxTimestamp(ctx)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while getting tx timestamp: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+
+	approval := AssetApproval{
+		ObjectType: AssetApprovalObject,
+		AssetID:    assetID,
+		Action:     action,
+		Target:     target,
+		Approver:   invoker,
+		ApprovedAt: txTime,
+	}
+	approvalKey, err := ctx.GetStub().CreateCompositeKey(AssetApprovalObject, []string{assetID, action, target, invoker})
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while creating approval key: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+	approvalAsBytes, _ := json.Marshal(approval)
+	err = ctx.GetStub().PutState(approvalKey, approvalAsBytes)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while saving approval: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+
+	response.Success = true
+	response.Message = fmt.Sprintf("%s approved %s of %s on digital asset %s", invoker, action, target, assetID)
+	logger.Info(response.Message)
+	response.Data = approval
+	return response
+}
+
+// GetAssetApprovals returns approvals of co-owners given on asset and not used yet
+func (da *DigitalAssetContract) GetAssetApprovals(ctx contractapi.TransactionContextInterface, assetID string) Response {
+	response := Response{
+		TxID:    ctx.GetStub().GetTxID(),
+		Success: false,
+		Message: "",
+		Data:    nil,
+	}
+
+	approvals, err := getAssetApprovals(ctx, []string{assetID})
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while fetching approvals: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+
+	response.Success = true
+	response.Message = fmt.Sprintf("Successfully fetched approvals of asset %s", assetID)
+	logger.Info(response.Message)
+	response.Data = approvals
+	return response
+}
+
+// SetAssetApprovalRule co-owner changes which co-owners must approve action, change itself needs approval of all co-owners
+func (da *DigitalAssetContract) SetAssetApprovalRule(ctx contractapi.TransactionContextInterface, assetID string, action string, rule string) Response {
+	response := Response{
+		TxID:    ctx.GetStub().GetTxID(),
+		Success: false,
+		Message: "",
+		Data:    nil,
+	}
+
+	invoker, err := resolveAkcessID(ctx)
+	if err != nil {
+		response.Message = err.Error()
+		logger.Info(response.Message)
+		return response
+	}
+	if _, found := defaultApprovalRules[action]; !found || action == AssetActionRule {
+		response.Message = fmt.Sprintf("Rule can't be set for action %s", action)
+		logger.Info(response.Message)
+		return response
+	}
+	if rule != ApprovalAll && rule != ApprovalMajority && rule != ApprovalAny {
+		response.Message = fmt.Sprintf("Unknown approval rule %s, rule must be one of %s, %s, %s", rule, ApprovalAll, ApprovalMajority, ApprovalAny)
+		logger.Info(response.Message)
+		return response
+	}
+	asset, err := getAsset(ctx, assetID)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while getting asset from ledger: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+	if asset == nil {
+		response.Message = fmt.Sprintf("Digital asset with id %s not found", assetID)
+		logger.Info(response.Message)
+		return response
+	}
+	target := action + ":" + rule
+	err = checkAssetApproval(ctx, asset, AssetActionRule, target, invoker)
+	if err != nil {
+		response.Message = err.Error()
+		logger.Info(response.Message)
+		return response
+	}
+
+	if asset.ApprovalRules == nil {
+		asset.ApprovalRules = map[string]string{}
+	}
+	asset.ApprovalRules[action] = rule
+	err = saveAsset(ctx, asset)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while saving asset in ledger: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+	err = clearAssetApprovals(ctx, assetID, AssetActionRule, target)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while clearing approvals: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+
+	response.Success = true
+	response.Message = fmt.Sprintf("Action %s on digital asset %s now needs approval of %s co-owners", action, assetID, rule)
+	logger.Info(response.Message)
+	response.Data = asset
+	return response
+}
+
+// RemoveAsset removes asset from ledger with approval of co-owners, its history stays available.
+// Asset encumbered by active lien can't be removed
+func (da *DigitalAssetContract) RemoveAsset(ctx contractapi.TransactionContextInterface, assetID string) Response {
+	response := Response{
+		TxID:    ctx.GetStub().GetTxID(),
+		Success: false,
+		Message: "",
+		Data:    nil,
+	}
+
+	invoker, err := resolveAkcessID(ctx)
+	if err != nil {
+		response.Message = err.Error()
+		logger.Info(response.Message)
+		return response
+	}
+	asset, err := getAsset(ctx, assetID)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while getting asset from ledger: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+	if asset == nil {
+		response.Message = fmt.Sprintf("Digital asset with id %s not found", assetID)
+		logger.Info(response.Message)
+		return response
+	}
+	err = checkAssetApproval(ctx, asset, AssetActionRemove, assetID, invoker)
+	if err != nil {
+		response.Message = err.Error()
+		logger.Info(response.Message)
+		return response
+	}
+	txTime, err := getTxTimestamp(ctx)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while getting tx timestamp: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+	err = checkNoActiveLien(ctx, assetID, txTime)
+	if err != nil {
+		response.Message = err.Error()
+		logger.Info(response.Message)
+		return response
+	}
+
+	err = deleteObject(ctx, AssetObject, assetID)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while removing asset from ledger: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+	err = clearAssetApprovals(ctx, assetID, AssetActionRemove, assetID)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while clearing approvals: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+
+	response.Success = true
+	response.Message = fmt.Sprintf("Digital asset %s removed by %s", assetID, invoker)
+	logger.Info(response.Message)
+	response.Data = asset
+	return response
+}
+
+// OwnerShares co-owners of asset, sole owner of asset without co-owners holds FullShare
+func (a DigitalAsset) OwnerShares() []AssetOwner {
+	if len(a.Owners) > 0 {
+		return a.Owners
+	}
+	return []AssetOwner{{AkcessID: a.Owner, Share: FullShare}}
+}
+
+// ShareOf share of given owner in basis points, 0 when not an owner
+func (a DigitalAsset) ShareOf(akcessID string) int {
+	for _, owner := range a.OwnerShares() {
+		if owner.AkcessID == akcessID {
+			return owner.Share
+		}
+	}
+	return 0
+}
+
+// ApprovalRule rule co-owners have to follow for given action
+func (a DigitalAsset) ApprovalRule(action string) string {
+	if action != AssetActionRule {
+		if rule, found := a.ApprovalRules[action]; found {
+			return rule
+		}
+	}
+	return defaultApprovalRules[action]
+}
+
+// setAssetOwners replaces owners of asset, owners without share are dropped.
+// Owner is set to co-owner with largest share, co-owners are kept only when there is more than one
+func setAssetOwners(asset *DigitalAsset, owners []AssetOwner) {
+	kept := []AssetOwner{}
+	for _, owner := range owners {
+		if owner.Share > 0 {
+			kept = append(kept, owner)
+		}
+	}
+	asset.Owner = ""
+	largest := 0
+	for _, owner := range kept {
+		if owner.Share > largest {
+			asset.Owner = owner.AkcessID
+			largest = owner.Share
+		}
+	}
+	asset.Owners = nil
+	if len(kept) > 1 {
+		asset.Owners = kept
+	}
+}
+
+// moveAssetShare moves share from one owner to another, recipient who is already a co-owner gets it added to their share
+func moveAssetShare(asset *DigitalAsset, from string, to string, share int) {
+	owners := []AssetOwner{}
+	received := false
+	for _, owner := range asset.OwnerShares() {
+		if owner.AkcessID == from {
+			owner.Share -= share
+		}
+		if owner.AkcessID == to {
+			owner.Share += share
+			received = true
+		}
+		owners = append(owners, owner)
+	}
+	if !received {
+		owners = append(owners, AssetOwner{AkcessID: to, Share: share})
+	}
+	setAssetOwners(asset, owners)
+}
+
+// transferTarget target of transfer approvals, see ApproveAssetAction
+func transferTarget(recipient string, share int) string {
+	if share == 0 {
+		return recipient
+	}
+	return recipient + ":" + strconv.Itoa(share)
+}
+
+// checkAssetApproval refuses action of invoker on asset unless invoker is co-owner and approvals of co-owners
+// given with ApproveAssetAction together with invoker's own satisfy approval rule of action
+func checkAssetApproval(ctx contractapi.TransactionContextInterface, asset *DigitalAsset, action string, target string, invoker string) error {
+	if asset.ShareOf(invoker) == 0 {
+		return fmt.Errorf("Digtal asset with id %s not owned by %s", asset.UniqueAssetID, invoker)
+	}
+	rule := asset.ApprovalRule(action)
+	if rule == ApprovalAny {
+		return nil
+	}
+
+	approvals, err := getAssetApprovals(ctx, []string{asset.UniqueAssetID, action, target})
+	if err != nil {
+		return fmt.Errorf("Error while fetching approvals: %s", err.Error())
+	}
+	approvers := map[string]bool{invoker: true}
+	for _, approval := range approvals {
+		approvers[approval.Approver] = true
+	}
+	approved := 0
+	missing := []string{}
+	for _, owner := range asset.OwnerShares() {
+		if approvers[owner.AkcessID] {
+			approved += owner.Share
+		} else {
+			missing = append(missing, owner.AkcessID)
+		}
+	}
+	if rule == ApprovalAll && len(missing) > 0 {
+		return fmt.Errorf("Action %s of %s on digital asset %s needs approval of all co-owners, missing approval of %s", action, target, asset.UniqueAssetID, strings.Join(missing, ", "))
+	}
+	if rule == ApprovalMajority && approved*2 <= FullShare {
+		return fmt.Errorf("Action %s of %s on digital asset %s needs approval of co-owners holding majority, approved share is %d of %d", action, target, asset.UniqueAssetID, approved, FullShare)
+	}
+	return nil
+}
+
+// clearAssetApprovals deletes approvals used by action so they can't be used again
+func clearAssetApprovals(ctx contractapi.TransactionContextInterface, assetID string, action string, target string) error {
+	resultIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(AssetApprovalObject, []string{assetID, action, target})
+	if err != nil {
+		return err
+	}
+	defer resultIterator.Close()
+
+	for resultIterator.HasNext() {
+		queryResponse, err := resultIterator.Next()
+		if err != nil {
+			return err
+		}
+		err = ctx.GetStub().DelState(queryResponse.Key)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// getAssetApprovals reads approvals under given leading key attributes
+func getAssetApprovals(ctx contractapi.TransactionContextInterface, attributes []string) ([]AssetApproval, error) {
+	resultIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(AssetApprovalObject, attributes)
+	if err != nil {
+		return nil, err
+	}
+	defer resultIterator.Close()
+
+	approvals := []AssetApproval{}
+	for resultIterator.HasNext() {
+		queryResponse, err := resultIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+		var approval AssetApproval
+		json.Unmarshal(queryResponse.Value, &approval)
+		approvals = append(approvals, approval)
+	}
+	return approvals, nil
+}
+
+// assetActions actions co-owners can approve
+func assetActions() []string {
+	return []string{AssetActionTransfer, AssetActionLink, AssetActionRemove, AssetActionLien, AssetActionMetadata, AssetActionRule}
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestCheckAssetApproval(t *testing.T) {
+	asset := DigitalAsset{
+		ObjectType:    "asset",
+		UniqueAssetID: "asset1",
+		Owner:         "alice",
+		Owners: []AssetOwner{
+			{AkcessID: "alice", Share: 5000},
+			{AkcessID: "bob", Share: 3000},
+			{AkcessID: "carol", Share: 2000},
+		},
+		ApprovalRules: map[string]string{
+			AssetActionMetadata: ApprovalMajority,
+			AssetActionRemove:   ApprovalAny,
+			AssetActionRule:     ApprovalAny, // ignored, changing rules always needs all co-owners
+		},
+	}
+
+	tests := []struct {
+		name          string
+		action        string
+		invoker       string
+		approvers     []string
+		expectedError string
+	}{
+		{name: "non owner", action: AssetActionRemove, invoker: "mallory", expectedError: "Digtal asset with id asset1 not owned by mallory"},
+		{name: "any rule lets co-owner act alone", action: AssetActionRemove, invoker: "carol"},
+		{name: "default any rule", action: AssetActionLink, invoker: "bob"},
+		{name: "all rule missing approvals", action: AssetActionTransfer, invoker: "alice", approvers: []string{"bob"}, expectedError: "missing approval of carol"},
+		{name: "all rule approved by every co-owner", action: AssetActionTransfer, invoker: "alice", approvers: []string{"bob", "carol"}},
+		{name: "approval of non owner doesn't count", action: AssetActionTransfer, invoker: "alice", approvers: []string{"bob", "mallory"}, expectedError: "missing approval of carol"},
+		{name: "majority rule with half of shares", action: AssetActionMetadata, invoker: "alice", expectedError: "approved share is 5000 of 10000"},
+		{name: "majority rule with more than half of shares", action: AssetActionMetadata, invoker: "bob", approvers: []string{"carol", "alice"}},
+		{name: "majority rule with minority", action: AssetActionMetadata, invoker: "bob", approvers: []string{"carol"}, expectedError: "approved share is 5000 of 10000"},
+		{name: "rule change always needs all co-owners", action: AssetActionRule, invoker: "alice", approvers: []string{"bob"}, expectedError: "needs approval of all co-owners"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := newTestLedger(t)
+			target := "target"
+			for _, approver := range tt.approvers {
+				approval := AssetApproval{ObjectType: AssetApprovalObject, AssetID: "asset1", Action: tt.action, Target: target, Approver: approver}
+				l.put(l.compositeKey(AssetApprovalObject, "asset1", tt.action, target, approver), approval)
+			}
+			// approval given for other target must not count
+			l.put(l.compositeKey(AssetApprovalObject, "asset1", tt.action, "other", "carol"), AssetApproval{AssetID: "asset1", Action: tt.action, Target: "other", Approver: "carol"})
+
+			ctx := l.as("Org1MSP", tt.invoker, nil)
+			expectErr(t, checkAssetApproval(ctx, &asset, tt.action, target, tt.invoker), tt.expectedError)
+		})
+	}
+}
+
+func TestApproveAssetAction(t *testing.T) {
+	l := newAssetLedger(t)
+	asset := DigitalAsset{ObjectType: "asset", UniqueAssetID: "asset1", Owner: "alice"}
+	setAssetOwners(&asset, []AssetOwner{{AkcessID: "alice", Share: 6000}, {AkcessID: "bank", Share: 4000}})
+	l.put(l.compositeKey(AssetObject, "asset1"), asset)
+
+	tests := []struct {
+		name          string
+		invoker       string
+		action        string
+		target        string
+		expectedError string
+	}{
+		{name: "non owner can't approve", invoker: "mallory", action: AssetActionTransfer, target: "mallory", expectedError: "Identity mallory is not an owner of digital asset asset1"},
+		{name: "unknown action", invoker: "bank", action: "sell", target: "mallory", expectedError: "Unknown action sell"},
+		{name: "missing target", invoker: "bank", action: AssetActionTransfer, expectedError: "Target of approved action can't be empty"},
+		{name: "co-owner approves", invoker: "bank", action: AssetActionTransfer, target: "mallory"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := new(DigitalAssetContract).ApproveAssetAction(l.as("Org1MSP", tt.invoker, nil), "asset1", tt.action, tt.target)
+			expectResponse(t, response, tt.expectedError)
+		})
+	}
+
+	// approval is used up by transfer it approved
+	expectResponse(t, offerAsset1ToMallory(l.as("Org1MSP", "alice", nil)), "")
+	approvals, err := getAssetApprovals(l.as("Org1MSP", "alice", nil), []string{"asset1"})
+	expectErr(t, err, "")
+	if len(approvals) != 0 {
+		t.Fatalf("expected approvals to be cleared, got %+v", approvals)
+	}
+}
@@ -0,0 +1,225 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// DocumentMatch document version whose content hash matches looked up hash
+type DocumentMatch struct {
+	DocumentID    string            `json:"documentID"`
+	DocumentType  string            `json:"documentType"`
+	AkcessID      string            `json:"akcessId"` // owner of the document
+	Status        string            `json:"status"`   // effective lifecycle status of the document
+	ReplacedBy    string            `json:"replacedBy,omitempty"`
+	Version       DocumentVersion   `json:"version"`
+	IsLatest      bool              `json:"isLatest"`      // false when document has newer version than the matching one
+	SigningStatus string            `json:"signingStatus"` // current signing status of the document
+	Signatures    []Signature       `json:"signatures"`    // signatures made on the matching version
+	Verification  VerificationsView `json:"verification"`  // current verifications, those of other versions are stale
+}
+
+// IndexResult result of one batch of document hash indexing
+type IndexResult struct {
+	Indexed []string `json:"indexed"`
+	NextKey string   `json:"nextKey"` // pass as startKey of next batch, empty when indexing is done
+}
+
+// FindDocumentByHash returns document versions with given hex encoded content hash,
+// used by relying parties holding only the file to check its authenticity
+func (d *DocContract) FindDocumentByHash(ctx contractapi.TransactionContextInterface, hash string) Response {
+	response := Response{
+		TxID:    ctx.GetStub().GetTxID(),
+		Success: false,
+		Message: "",
+		Data:    nil,
+	}
+
+	hash = strings.ToLower(hash)
+	if hash == "" {
+		response.Message = fmt.Sprint("Hash can't be empty")
+		logger.Info(response.Message)
+		return response
+	}
+	txTime, err := getTxTimestamp(ctx)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while getting tx timestamp: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+
+	resultIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(DocHashIndex, []string{hash})
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while fetching doc hash index: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+	defer resultIterator.Close()
+
+	result := []DocumentMatch{}
+	for resultIterator.HasNext() {
+		queryResponse, err := resultIterator.Next()
+		if err != nil {
+			response.Message = fmt.Sprintf("Error while iterating doc hash index: %s", err.Error())
+			logger.Error(response.Message)
+			return response
+		}
+		_, attributes, err := ctx.GetStub().SplitCompositeKey(queryResponse.Key)
+		if err != nil || len(attributes) != 3 {
+			continue
+		}
+		documentid := attributes[1]
+		version, err := strconv.Atoi(attributes[2])
+		if err != nil {
+			continue
+		}
+
+		doc, err := getDocument(ctx, documentid)
+		if err != nil {
+			response.Message = fmt.Sprintf("Error while fetching doc from world state: %s", err.Error())
+			logger.Error(response.Message)
+			return response
+		}
+		// skip entries of documents which no longer exist
+		if doc == nil || version < 1 || version > len(doc.Versions) {
+			continue
+		}
+
+		matched := doc.Versions[version-1]
+		view, err := buildVerificationsView(ctx, PolicyScopeDocument, doc.DocumentType, doc.Verifications, nil, matched.Hash, false)
+		if err != nil {
+			response.Message = fmt.Sprintf("Error while evaluating verifications: %s", err.Error())
+			logger.Error(response.Message)
+			return response
+		}
+		view.DocumentStatus = doc.EffectiveStatus(txTime)
+		view.Verified = view.Verified && view.DocumentStatus == DocumentActive
+		match := DocumentMatch{
+			DocumentID:    doc.DocumentID,
+			DocumentType:  doc.DocumentType,
+			AkcessID:      doc.AkcessID,
+			Status:        view.DocumentStatus,
+			ReplacedBy:    doc.ReplacedBy,
+			Version:       matched,
+			IsLatest:      version == len(doc.Versions),
+			SigningStatus: doc.EffectiveSigningStatus(txTime),
+			Signatures:    []Signature{},
+			Verification:  view,
+		}
+		for _, signature := range doc.Signature {
+			if signature.DocumentVersion == version {
+				match.Signatures = append(match.Signatures, signature)
+			}
+		}
+		result = append(result, match)
+	}
+	if len(result) == 0 {
+		response.Message = fmt.Sprintf("No document has hash %s", hash)
+		logger.Info(response.Message)
+		return response
+	}
+
+	response.Success = true
+	response.Message = fmt.Sprintf("Found %d documents with hash %s", len(result), hash)
+	logger.Info(response.Message)
+	response.Data = result
+	return response
+}
+
+// IndexDocumentHashes admin indexes hashes of documents created before hash index was introduced.
+// Indexing runs in batches of at most limit documents starting from document with id startKey,
+// until returned nextKey is empty. Documents still under legacy keys are indexed by MigrateLegacyKeys
+func (d *DocContract) IndexDocumentHashes(ctx contractapi.TransactionContextInterface, startKey string, limit int) Response {
+	response := Response{
+		TxID:    ctx.GetStub().GetTxID(),
+		Success: false,
+		Message: "",
+		Data:    nil,
+	}
+
+	invoker := invokerName(ctx)
+	if !IsAdmin(ctx) {
+		response.Message = fmt.Sprintf("Identity %s is not an admin, only admin can index documents", invoker)
+		logger.Info(response.Message)
+		return response
+	}
+	if limit <= 0 {
+		response.Message = fmt.Sprint("Limit must be greater than zero")
+		logger.Info(response.Message)
+		return response
+	}
+
+	resultIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(DocumentObject, []string{})
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while fetching documents: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+	defer resultIterator.Close()
+
+	result := IndexResult{
+		Indexed: []string{},
+	}
+	for resultIterator.HasNext() {
+		queryResponse, err := resultIterator.Next()
+		if err != nil {
+			response.Message = fmt.Sprintf("Error while iterating documents: %s", err.Error())
+			logger.Error(response.Message)
+			return response
+		}
+		_, attributes, err := ctx.GetStub().SplitCompositeKey(queryResponse.Key)
+		if err != nil || len(attributes) != 1 || attributes[0] < startKey {
+			continue
+		}
+		if len(result.Indexed) == limit {
+			result.NextKey = attributes[0]
+			break
+		}
+
+		err = indexDocumentHashes(ctx, queryResponse.Value)
+		if err != nil {
+			response.Message = fmt.Sprintf("Error while indexing document %s: %s", attributes[0], err.Error())
+			logger.Error(response.Message)
+			return response
+		}
+		result.Indexed = append(result.Indexed, attributes[0])
+	}
+
+	response.Success = true
+	response.Message = fmt.Sprintf("Indexed hashes of %d documents", len(result.Indexed))
+	logger.Info(response.Message)
+	response.Data = result
+	return response
+}
+
+// putDocumentHashIndex adds index entry of document version under its content hash
+func putDocumentHashIndex(ctx contractapi.TransactionContextInterface, documentid string, version DocumentVersion) error {
+	indexKey, err := ctx.GetStub().CreateCompositeKey(DocHashIndex, []string{strings.ToLower(version.Hash), documentid, strconv.Itoa(version.Version)})
+	if err != nil {
+		return err
+	}
+	return ctx.GetStub().PutState(indexKey, []byte{0x00})
+}
+
+// indexDocumentHashes indexes all versions of stored document
+func indexDocumentHashes(ctx contractapi.TransactionContextInterface, value []byte) error {
+	var doc Document
+	err := json.Unmarshal(value, &doc)
+	if err != nil {
+		return err
+	}
+	for _, version := range doc.Versions {
+		if version.Hash == "" {
+			continue
+		}
+		err = putDocumentHashIndex(ctx, doc.DocumentID, version)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFindDocumentByHash(t *testing.T) {
+	l := newDocLedger(t)
+	requestID := requestVerification(l)
+	expectResponse(t, new(DocContract).ApproveDocVerification(l.as("Org1MSP", "vera", verifierAttrs), requestID, l.now().AddDate(1, 0, 0).Format(time.RFC3339)), "")
+	expectResponse(t, new(DocContract).AddDocumentVersion(l.as("Org1MSP", "bob", nil), "doc1", saltedHash("", "v2"), "sha256", ""), "")
+	expectResponse(t, new(DocContract).CreateDocWithHash(l.as("Org1MSP", "mallory", nil), "doc2", "passport", saltedHash("", "v2"), "sha256"), "")
+	expectResponse(t, new(DocContract).CreateDocWithHash(l.as("Org1MSP", "bob", nil), "doc3", "passport", saltedHash("", "lost"), "sha256"), "")
+	expectResponse(t, new(DocContract).RevokeDocument(l.as("Org1MSP", "bob", nil), "doc3", "lost"), "")
+
+	tests := []struct {
+		name            string
+		hash            string
+		expectedMatches []string
+		expectedError   string
+	}{
+		{
+			name:            "hash of older version in upper case",
+			hash:            strings.ToUpper(saltedHash("", "content")),
+			expectedMatches: []string{"doc1 of bob version 1 latest false status active verifications 1"},
+		},
+		{
+			name: "hash of latest version of two documents",
+			hash: saltedHash("", "v2"),
+			expectedMatches: []string{
+				"doc1 of bob version 2 latest true status active verifications 0",
+				"doc2 of mallory version 1 latest true status active verifications 0",
+			},
+		},
+		{
+			name:            "hash of revoked document",
+			hash:            saltedHash("", "lost"),
+			expectedMatches: []string{"doc3 of bob version 1 latest true status revoked verifications 0"},
+		},
+		{name: "unknown hash", hash: saltedHash("", "forged"), expectedError: "No document has hash " + saltedHash("", "forged")},
+		{name: "empty hash", hash: "", expectedError: "Hash can't be empty"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := new(DocContract).FindDocumentByHash(l.as("Org1MSP", "relyingparty", nil), tt.hash)
+			expectResponse(t, response, tt.expectedError)
+			if tt.expectedError != "" {
+				return
+			}
+			matches := []string{}
+			for _, match := range response.Data.([]DocumentMatch) {
+				matches = append(matches, fmt.Sprintf("%s of %s version %d latest %t status %s verifications %d",
+					match.DocumentID, match.AkcessID, match.Version.Version, match.IsLatest, match.Status, match.Verification.ActiveCount))
+			}
+			if !equalStrings(matches, tt.expectedMatches) {
+				t.Fatalf("expected matches %v, got %v", tt.expectedMatches, matches)
+			}
+		})
+	}
+}
+
+func TestIndexDocumentHashes(t *testing.T) {
+	l := newTestLedger(t)
+	l.initLedger()
+	// documents stored before hash index was introduced
+	for _, documentid := range []string{"doc1", "doc2", "doc3"} {
+		l.put(l.compositeKey(DocumentObject, documentid), Document{
+			ObjectType: "document",
+			DocumentID: documentid,
+			AkcessID:   "bob",
+			Versions:   []DocumentVersion{{Version: 1, Hash: "AB" + documentid}, {Version: 2}},
+		})
+	}
+
+	tests := []struct {
+		name            string
+		mspID           string
+		startKey        string
+		limit           int
+		expectedIndexed []string
+		expectedNextKey string
+		expectedError   string
+	}{
+		{name: "non admin can't index", mspID: "Org1MSP", limit: 10, expectedError: "only admin can index documents"},
+		{name: "limit must be positive", mspID: "AdminMSP", limit: 0, expectedError: "Limit must be greater than zero"},
+		{name: "first batch", mspID: "AdminMSP", limit: 2, expectedIndexed: []string{"doc1", "doc2"}, expectedNextKey: "doc3"},
+		{name: "last batch", mspID: "AdminMSP", startKey: "doc3", limit: 2, expectedIndexed: []string{"doc3"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := new(DocContract).IndexDocumentHashes(l.as(tt.mspID, "admin", nil), tt.startKey, tt.limit)
+			expectResponse(t, response, tt.expectedError)
+			if tt.expectedError != "" {
+				return
+			}
+			result := response.Data.(IndexResult)
+			if !equalStrings(result.Indexed, tt.expectedIndexed) || result.NextKey != tt.expectedNextKey {
+				t.Fatalf("expected indexed %v, next key %q, got %+v", tt.expectedIndexed, tt.expectedNextKey, result)
+			}
+		})
+	}
+
+	for _, documentid := range []string{"doc1", "doc2", "doc3"} {
+		if l.stub.State[l.compositeKey(DocHashIndex, "ab"+documentid, documentid, "1")] == nil {
+			t.Fatalf("expected version 1 of %s to be indexed by lower case hash", documentid)
+		}
+		if l.stub.State[l.compositeKey(DocHashIndex, "", documentid, "2")] != nil {
+			t.Fatalf("expected version 2 of %s without hash not to be indexed", documentid)
+		}
+	}
+	response := new(DocContract).FindDocumentByHash(l.as("Org1MSP", "relyingparty", nil), "ABdoc3")
+	expectResponse(t, response, "")
+	if matches := response.Data.([]DocumentMatch); len(matches) != 1 || matches[0].DocumentID != "doc3" {
+		t.Fatalf("expected indexed doc3 to be found by hash, got %+v", matches)
+	}
+}
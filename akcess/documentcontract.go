@@ -3,9 +3,12 @@ package main
 import (
 	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+
+	"otp"
 )
 
 // DocContract contract for storing user in blockchain
@@ -13,7 +16,17 @@ type DocContract struct {
 	contractapi.Contract
 }
 
-// CreateDoc creates doc
+// DocAPIVersion version of DocContract transactions. Version 2 replaced CreateDoc with CreateDocWithHash and
+// SendDoc with ShareDoc, both keep working with their original arguments but SendDoc takes receivers from transient data
+const DocAPIVersion = 2
+
+// GetAPIVersion returns version of DocContract transactions, clients check it before calling changed transactions
+func (d *DocContract) GetAPIVersion(ctx contractapi.TransactionContextInterface) int {
+	return DocAPIVersion
+}
+
+// CreateDoc creates doc from content hashes, kept with its original arguments for clients written before document
+// versioning. Every hash becomes a version, hash algorithm is detected from digest size. New clients use CreateDocWithHash
 func (d *DocContract) CreateDoc(ctx contractapi.TransactionContextInterface, documentid string, documenthash []string) Response {
 	response := Response{
 		TxID:    ctx.GetStub().GetTxID(),
@@ -22,10 +35,15 @@ func (d *DocContract) CreateDoc(ctx contractapi.TransactionContextInterface, doc
 		Data:    nil,
 	}
 
-	invoker, _ := getCommonName(ctx)
-	docAsBytes, err := ctx.GetStub().GetState(documentid)
+	invoker, err := resolveAkcessID(ctx)
 	if err != nil {
-		response.Message = fmt.Sprintf("Error while fetching doc from world state: %s" + err.Error())
+		response.Message = err.Error()
+		logger.Info(response.Message)
+		return response
+	}
+	docAsBytes, err := getObject(ctx, DocumentObject, documentid)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while fetching doc from world state: %s", err.Error())
 		logger.Error(response.Message)
 		return response
 	}
@@ -34,23 +52,136 @@ func (d *DocContract) CreateDoc(ctx contractapi.TransactionContextInterface, doc
 		logger.Info(response.Message)
 		return response
 	}
+	if len(documenthash) == 0 {
+		response.Message = fmt.Sprint("Pass at least one document hash")
+		logger.Info(response.Message)
+		return response
+	}
+
+	txTime, err := getTxTimestamp(ctx)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while getting tx timestamp: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
 
+	versions := []DocumentVersion{}
+	for index, hash := range documenthash {
+		hash = strings.ToLower(hash)
+		versions = append(versions, DocumentVersion{
+			Version:       index + 1,
+			Hash:          hash,
+			HashAlgorithm: detectHashAlgorithm(hash),
+			Author:        invoker,
+			CreatedAt:     txTime,
+		})
+	}
 	doc := Document{
 		ObjectType:    "document",
 		DocumentID:    documentid,
-		DocumentHash:  documenthash,
+		Versions:      versions,
 		Signature:     []Signature{},
 		AkcessID:      invoker,
 		Verifications: []Verification{},
+		SigningStatus: SigningDraft,
+		Status:        DocumentActive,
 	}
 
 	newDocAsBytes, _ := json.Marshal(doc)
-	err = ctx.GetStub().PutState(documentid, newDocAsBytes)
+	err = putObject(ctx, DocumentObject, documentid, newDocAsBytes)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while creating doc: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+	err = indexDocumentHashes(ctx, newDocAsBytes)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while indexing doc hash: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+
+	response.Success = true
+	response.Message = fmt.Sprintf("Document with id %s created", documentid)
+	logger.Info(response.Message)
+	return response
+}
+
+// CreateDocWithHash creates doc of given type with its first version
+func (d *DocContract) CreateDocWithHash(ctx contractapi.TransactionContextInterface, documentid string, documentType string, hash string, hashAlgorithm string) Response {
+	response := Response{
+		TxID:    ctx.GetStub().GetTxID(),
+		Success: false,
+		Message: "",
+		Data:    nil,
+	}
+
+	invoker, err := resolveAkcessID(ctx)
+	if err != nil {
+		response.Message = err.Error()
+		logger.Info(response.Message)
+		return response
+	}
+	docAsBytes, err := getObject(ctx, DocumentObject, documentid)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while fetching doc from world state: %s" + err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+	if docAsBytes != nil {
+		response.Message = fmt.Sprintf("Document with id %s already exist", documentid)
+		logger.Info(response.Message)
+		return response
+	}
+
+	hash = strings.ToLower(hash)
+	err = validateDocumentHash(hash, hashAlgorithm)
+	if err != nil {
+		response.Message = err.Error()
+		logger.Info(response.Message)
+		return response
+	}
+
+	txTime, err := getTxTimestamp(ctx)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while getting tx timestamp: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+
+	doc := Document{
+		ObjectType:   "document",
+		DocumentID:   documentid,
+		DocumentType: documentType,
+		Versions: []DocumentVersion{
+			{
+				Version:       1,
+				Hash:          hash,
+				HashAlgorithm: hashAlgorithm,
+				Author:        invoker,
+				CreatedAt:     txTime,
+			},
+		},
+		Signature:     []Signature{},
+		AkcessID:      invoker,
+		Verifications: []Verification{},
+		SigningStatus: SigningDraft,
+		Status:        DocumentActive,
+	}
+
+	newDocAsBytes, _ := json.Marshal(doc)
+	err = putObject(ctx, DocumentObject, documentid, newDocAsBytes)
 	if err != nil {
 		response.Message = fmt.Sprintf("Error while creating doc: %s" + err.Error())
 		logger.Error(response.Message)
 		return response
 	}
+	err = putDocumentHashIndex(ctx, documentid, doc.Versions[0])
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while indexing doc hash: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
 
 	response.Success = true
 	response.Message = fmt.Sprintf("Document with id %s created", documentid)
@@ -58,8 +189,12 @@ func (d *DocContract) CreateDoc(ctx contractapi.TransactionContextInterface, doc
 	return response
 }
 
-// SignDoc signs doc with signature Hash
-func (d *DocContract) SignDoc(ctx contractapi.TransactionContextInterface, documentid string, signhash string, signDate string, otpCode string) Response {
+// SignDoc signs doc with base64 encoded signature over hash of version being signed, made with one of signer's
+// active signing keys. Invoker must be a signer of document's signing workflow whose turn it is. Documents without
+// signing workflow can be signed once per version by their owner and receivers of their shares.
+// OTP is passed in transient data under "otp" key and must answer unexpired challenge issued to signer with IssueOTPChallenge
+// Wrong OTP fails signing but is recorded as failed attempt on signer's open challenges
+func (d *DocContract) SignDoc(ctx contractapi.TransactionContextInterface, documentid string, signature string, signDate string) Response {
 	response := Response{
 		TxID:    ctx.GetStub().GetTxID(),
 		Success: false,
@@ -67,8 +202,13 @@ func (d *DocContract) SignDoc(ctx contractapi.TransactionContextInterface, docum
 		Data:    nil,
 	}
 
-	invoker, _ := getCommonName(ctx)
-	docAsBytes, err := ctx.GetStub().GetState(documentid)
+	invoker, err := resolveAkcessID(ctx)
+	if err != nil {
+		response.Message = err.Error()
+		logger.Info(response.Message)
+		return response
+	}
+	docAsBytes, err := getObject(ctx, DocumentObject, documentid)
 	if err != nil {
 		response.Message = fmt.Sprintf("Error while fetching doc from world state: %s" + err.Error())
 		logger.Error(response.Message)
@@ -80,13 +220,13 @@ func (d *DocContract) SignDoc(ctx contractapi.TransactionContextInterface, docum
 		return response
 	}
 
-	userAsBytes, err := ctx.GetStub().GetState(invoker)
+	user, err := getUser(ctx, invoker)
 	if err != nil {
 		response.Message = fmt.Sprintf("Error while fetching user from world state: %s" + err.Error())
 		logger.Error(response.Message)
 		return response
 	}
-	if userAsBytes == nil {
+	if user == nil {
 		response.Message = fmt.Sprintf("User with id %s doesn't exist", invoker)
 		logger.Info(response.Message)
 		return response
@@ -99,18 +239,88 @@ func (d *DocContract) SignDoc(ctx contractapi.TransactionContextInterface, docum
 		return response
 	}
 
+	txTime, err := getTxTimestamp(ctx)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while getting tx timestamp: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+	policy, err := getTimePolicy(ctx)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while fetching time policy: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+	err = policy.CheckSignDate(signdate, txTime)
+	if err != nil {
+		response.Message = err.Error()
+		logger.Info(response.Message)
+		return response
+	}
 	var doc Document
 	json.Unmarshal(docAsBytes, &doc)
-	signature := Signature{
-		SignatureHash: signhash,
-		OTP:           otpCode,
-		AkcessID:      invoker,
-		TimeStamp:     signdate,
+	if doc.Workflow == nil {
+		err = checkCanSignWithoutWorkflow(ctx, doc, invoker, txTime)
+	} else {
+		err = checkCanSign(doc, invoker, txTime)
+	}
+	if err != nil {
+		response.Message = err.Error()
+		logger.Info(response.Message)
+		return response
+	}
+	signedVersion, _ := doc.LatestVersion()
+	if doc.Workflow != nil {
+		if doc.Workflow.DocumentVersion < 1 || doc.Workflow.DocumentVersion > len(doc.Versions) {
+			response.Message = fmt.Sprintf("Document %s has no version %d to sign", documentid, doc.Workflow.DocumentVersion)
+			logger.Info(response.Message)
+			return response
+		}
+		signedVersion = doc.Versions[doc.Workflow.DocumentVersion-1]
+	}
+	keyID, err := verifyUserSignature(*user, signedVersion.Hash, signature)
+	if err != nil {
+		response.Message = err.Error()
+		logger.Info(response.Message)
+		return response
+	}
+
+	otpCode, err := getTransientValue(ctx, "otp")
+	if err != nil {
+		response.Message = err.Error()
+		logger.Info(response.Message)
+		return response
+	}
+	collections, err := getPrivateDataConfig(ctx)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while fetching private data config: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+	challenge, err := otp.Consume(ctx, collections.OTPCollection, "document", documentid, invoker, otpCode, txTime)
+	if err != nil {
+		response.Message = err.Error()
+		logger.Info(response.Message)
+		return response
+	}
+
+	docSignature := Signature{
+		SignatureHash:   signature,
+		KeyID:           keyID,
+		OTPHash:         challenge.OTPHash,
+		OTPChallenge:    challenge.ChallengeID,
+		AkcessID:        invoker,
+		TimeStamp:       signdate,
+		TxTimestamp:     txTime,
+		DocumentVersion: signedVersion.Version,
+	}
+	doc.Signature = append(doc.Signature, docSignature)
+	if signed, _ := doc.WorkflowSigners(); doc.Workflow != nil && len(signed) >= doc.Workflow.Required() {
+		doc.SigningStatus = SigningCompleted
 	}
-	doc.Signature = append(doc.Signature, signature)
 	docAsBytes, _ = json.Marshal(doc)
 
-	err = ctx.GetStub().PutState(documentid, docAsBytes)
+	err = putObject(ctx, DocumentObject, documentid, docAsBytes)
 	if err != nil {
 		response.Message = fmt.Sprintf("Error while updating signature in doc: %s" + err.Error())
 		logger.Error(response.Message)
@@ -118,12 +328,15 @@ func (d *DocContract) SignDoc(ctx contractapi.TransactionContextInterface, docum
 	}
 
 	response.Success = true
-	response.Message = fmt.Sprintf("Document %s signed by %s", documentid, invoker)
+	response.Message = fmt.Sprintf("Version %d of document %s signed by %s", signedVersion.Version, documentid, invoker)
 	logger.Info(response.Message)
+	response.Data = signingState(doc, txTime)
 	return response
 }
 
-// SendDoc shares document from sender to verifier
+// SendDoc original share transaction, kept with its original arguments for clients written before shares had
+// permissions. Receivers argument is stored on the ledger with the transaction, so it must be left empty and receivers
+// are passed in transient data as with ShareDoc. Document is shared for viewing and verification without validity window
 func (d *DocContract) SendDoc(ctx contractapi.TransactionContextInterface, sharingid string, receivers []string, documentid string) Response {
 	response := Response{
 		TxID:    ctx.GetStub().GetTxID(),
@@ -132,8 +345,31 @@ func (d *DocContract) SendDoc(ctx contractapi.TransactionContextInterface, shari
 		Data:    nil,
 	}
 
-	sender, _ := getCommonName(ctx)
-	senderAsBytes, err := ctx.GetStub().GetState(sender)
+	if len(receivers) > 0 {
+		response.Message = fmt.Sprintf("Receivers of document %s are refused as arguments since API version %d, leave receivers empty and pass them in transient data under \"receivers\" key together with \"salt\"", documentid, DocAPIVersion)
+		logger.Info(response.Message)
+		return response
+	}
+	return d.ShareDoc(ctx, sharingid, documentid, []string{SharePermissionView, SharePermissionVerify}, "", "")
+}
+
+// ShareDoc owner shares document with receivers for given permissions and validity window. Receivers are passed
+// in transient data as JSON array under "receivers" key together with "salt" and stored in share private data collection
+func (d *DocContract) ShareDoc(ctx contractapi.TransactionContextInterface, sharingid string, documentid string, permissions []string, validFrom string, validUntil string) Response {
+	response := Response{
+		TxID:    ctx.GetStub().GetTxID(),
+		Success: false,
+		Message: "",
+		Data:    nil,
+	}
+
+	sender, err := resolveAkcessID(ctx)
+	if err != nil {
+		response.Message = err.Error()
+		logger.Info(response.Message)
+		return response
+	}
+	senderAsBytes, err := getObject(ctx, UserObject, sender)
 	if err != nil {
 		response.Message = fmt.Sprintf("Error while fetching user from world state: %s" + err.Error())
 		logger.Error(response.Message)
@@ -144,40 +380,69 @@ func (d *DocContract) SendDoc(ctx contractapi.TransactionContextInterface, shari
 		logger.Info(response.Message)
 		return response
 	}
-	docAsBytes, err := ctx.GetStub().GetState(documentid)
+	doc, err := getDocument(ctx, documentid)
 	if err != nil {
-		response.Message = fmt.Sprintf("Error while fetching doc from world state: %s" + err.Error())
+		response.Message = fmt.Sprintf("Error while fetching doc from world state: %s", err.Error())
 		logger.Error(response.Message)
 		return response
 	}
-	if docAsBytes == nil {
+	if doc == nil {
 		response.Message = fmt.Sprintf("Document with id %s doesn't exist", documentid)
 		logger.Info(response.Message)
 		return response
 	}
+	if doc.AkcessID != sender {
+		response.Message = fmt.Sprintf("Identity %s is not owner of document %s", sender, documentid)
+		logger.Info(response.Message)
+		return response
+	}
 
-	sharedoc := DocumentShare{
-		ObjectType: "docshare",
-		SharingID:  sharingid,
-		Sender:     sender,
-		Receivers:  receivers,
-		DocumentID: documentid,
+	txTime, err := getTxTimestamp(ctx)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while getting tx timestamp: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+	err = checkDocumentActive(doc, txTime)
+	if err != nil {
+		response.Message = err.Error()
+		logger.Info(response.Message)
+		return response
+	}
+	share, err := newShare(ctx, sharingid, sender, documentid, permissions, validFrom, validUntil, txTime)
+	if err != nil {
+		response.Message = err.Error()
+		logger.Info(response.Message)
+		return response
 	}
-	shareSDocAdBytes, _ := json.Marshal(sharedoc)
-	err = ctx.GetStub().PutState(sharingid, shareSDocAdBytes)
+	receivers, salt, err := getShareReceiversInput(ctx)
 	if err != nil {
-		response.Message = fmt.Sprintf("Error while sending doc: %s" + err.Error())
+		response.Message = err.Error()
+		logger.Info(response.Message)
+		return response
+	}
+	collections, err := getPrivateDataConfig(ctx)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while fetching private data config: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+	err = saveShare(ctx, collections.ShareCollection, share, receivers, salt)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while sending doc: %s", err.Error())
 		logger.Error(response.Message)
 		return response
 	}
 
 	response.Success = true
-	response.Message = fmt.Sprintf("Document %s shared from %s to %s", documentid, sender, receivers)
+	response.Message = fmt.Sprintf("Document %s shared from %s to %d receivers", documentid, sender, len(receivers))
 	logger.Info(response.Message)
+	response.Data = share
 	return response
 }
 
-// VerifyDoc verify the doc
+// VerifyDoc verifier verifies current version of the doc. Verifier other than owner must hold share of the doc
+// with verify permission, verifiers asked by owner verify through ApproveDocVerification
 func (d *DocContract) VerifyDoc(ctx contractapi.TransactionContextInterface, documentid string, expiryDate string) Response {
 	response := Response{
 		TxID:    ctx.GetStub().GetTxID(),
@@ -186,55 +451,119 @@ func (d *DocContract) VerifyDoc(ctx contractapi.TransactionContextInterface, doc
 		Data:    nil,
 	}
 
-	invoker, _ := getCommonName(ctx)
-	docAsBytes, err := ctx.GetStub().GetState(documentid)
+	invoker, err := resolveAkcessID(ctx)
 	if err != nil {
-		response.Message = fmt.Sprintf("Error while fetching doc from world state: %s" + err.Error())
+		response.Message = err.Error()
+		logger.Info(response.Message)
+		return response
+	}
+	doc, err := getDocument(ctx, documentid)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while fetching doc from world state: %s", err.Error())
 		logger.Error(response.Message)
 		return response
 	}
-	if docAsBytes != nil {
-		response.Message = fmt.Sprintf("Document with id %s already exist", documentid)
+	if doc == nil {
+		response.Message = fmt.Sprintf("Document with id %s doesn't exist", documentid)
 		logger.Info(response.Message)
 		return response
 	}
 
+	if doc.AkcessID != invoker {
+		txTime, err := getTxTimestamp(ctx)
+		if err != nil {
+			response.Message = fmt.Sprintf("Error while getting tx timestamp: %s", err.Error())
+			logger.Error(response.Message)
+			return response
+		}
+		err = checkSharePermission(ctx, documentid, invoker, SharePermissionVerify, txTime)
+		if err != nil {
+			response.Message = err.Error()
+			logger.Info(response.Message)
+			return response
+		}
+	}
+
 	expirydate, err := time.Parse(time.RFC3339, expiryDate)
 	if err != nil {
-		response.Message = fmt.Sprintf("Error while parsing error pass date in ISO format", err.Error())
+		response.Message = fmt.Sprintf("Error while parsing date pass date in ISO format: %s", err.Error())
 		logger.Error(response.Message)
 		return response
 	}
 
-	verifierAsBytes, err := ctx.GetStub().GetState(invoker)
+	verification, err := verifyDocument(ctx, invoker, doc, expirydate)
 	if err != nil {
-		response.Message = fmt.Sprintf("Error while fetching verifier from world state: %s" + err.Error())
-		logger.Error(response.Message)
+		response.Message = err.Error()
+		logger.Info(response.Message)
 		return response
 	}
+
+	response.Success = true
+	response.Message = fmt.Sprintf("Version %d of document %s verified by %s", verification.DocumentVersion, documentid, invoker)
+	logger.Info(response.Message)
+	response.Data = verification
+	return response
+}
+
+// verifyDocument adds verification of current version of document by verifier, or renews verification
+// verifier made before, and saves the document
+func verifyDocument(ctx contractapi.TransactionContextInterface, verifierID string, doc *Document, expirydate time.Time) (*Verification, error) {
+	txTime, err := getTxTimestamp(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("Error while getting tx timestamp: %s", err.Error())
+	}
+	err = checkDocumentActive(doc, txTime)
+	if err != nil {
+		return nil, err
+	}
+	policy, err := getTimePolicy(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("Error while fetching time policy: %s", err.Error())
+	}
+	err = policy.CheckExpiryDate(expirydate, txTime)
+	if err != nil {
+		return nil, err
+	}
+
+	verifierAsBytes, err := getObject(ctx, VerifierObject, verifierID)
+	if err != nil {
+		return nil, fmt.Errorf("Error while fetching verifier from world state: %s", err.Error())
+	}
 	if verifierAsBytes == nil {
-		response.Message = fmt.Sprintf("Verifier with id %s doesn't exist", invoker)
-		logger.Info(response.Message)
-		return response
+		return nil, fmt.Errorf("Verifier with id %s doesn't exist", verifierID)
 	}
 
 	var verifier Verifier
 	json.Unmarshal(verifierAsBytes, &verifier)
+	if !verifier.IsApproved() {
+		return nil, fmt.Errorf("Verifier %s is not approved, current status is %s", verifierID, verifier.CurrentStatus())
+	}
 
-	var doc Document
-	json.Unmarshal(docAsBytes, &doc)
+	err = checkGradePolicy(ctx, PolicyScopeDocument, doc.DocumentType, verifier.VerifierGrade)
+	if err != nil {
+		return nil, fmt.Errorf("Verifier %s can't verify document %s: %s", verifierID, doc.DocumentID, err.Error())
+	}
+
+	latest, found := doc.LatestVersion()
+	if !found {
+		return nil, fmt.Errorf("Document %s has no version to verify", doc.DocumentID)
+	}
 
 	verification := Verification{
-		VerifierObj: verifier,
-		ExpirtyDate: expirydate,
+		VerifierID:      verifier.AkcessID,
+		VerifierVersion: verifier.Version,
+		ExpirtyDate:     expirydate,
+		Commitment:      latest.Hash,
+		DocumentVersion: latest.Version,
+		TxTimestamp:     txTime,
 	}
 
 	verifierList := VerifiersList(doc.Verifications)
-	_, found := Find(verifierList, invoker)
+	_, found = Find(verifierList, verifierID)
 	if found {
 		for i, v := range doc.Verifications {
-			if v.VerifierObj.AkcessID == invoker {
-				doc.Verifications[i].ExpirtyDate = expirydate
+			if v.VerifierID == verifierID {
+				doc.Verifications[i] = verification
 				break
 			}
 		}
@@ -242,55 +571,17 @@ func (d *DocContract) VerifyDoc(ctx contractapi.TransactionContextInterface, doc
 		doc.Verifications = append(doc.Verifications, verification)
 	}
 
-	docAsBytes, _ = json.Marshal(doc)
-	err = ctx.GetStub().PutState(documentid, docAsBytes)
+	docAsBytes, _ := json.Marshal(doc)
+	err = putObject(ctx, DocumentObject, doc.DocumentID, docAsBytes)
 	if err != nil {
-		response.Message = fmt.Sprintf("Error while updating verification in doc: %s" + err.Error())
-		logger.Error(response.Message)
-		return response
+		return nil, fmt.Errorf("Error while updating verification in doc: %s", err.Error())
 	}
-
-	response.Success = true
-	response.Message = fmt.Sprintf("Document %s verified by %s", documentid, invoker)
-	logger.Info(response.Message)
-	return response
+	return &verification, nil
 }
 
-// GetTxForDoc get document details for perticular transaction
-// func (d *DocContract) GetTxForDoc(ctx contractapi.TransactionContextInterface, documentid string, txid string) (*Document, error) {
-// 	resultIterator, err := ctx.GetStub().GetHistoryForKey(documentid)
-// 	if err != nil {
-// 		return nil, err
-// 	}
-// 	defer resultIterator.Close()
-
-// 	for resultIterator.HasNext() {
-// 		queryResponse, err := resultIterator.Next()
-// 		if err != nil {
-// 			return nil, err
-// 		}
-// 		d := new(Document)
-// 		_ = json.Unmarshal(queryResponse.Value, d)
-// 		fmt.Println(queryResponse.TxId)
-// 		fmt.Println(queryResponse.TxId == txid)
-// 		if queryResponse.TxId == txid {
-// 			queryResult := Document{
-// 				ObjectType:        "document",
-// 				DocumentID:        d.DocumentID,
-// 				DocumentHash:      d.DocumentHash,
-// 				SignatureHash:     d.SignatureHash,
-// 				AkcessID:          d.AkcessID,
-// 				VerifiedBy:        d.VerifiedBy,
-// 				VerificationGrade: d.VerificationGrade,
-// 			}
-// 			return &queryResult, nil
-// 		}
-// 	}
-// 	return nil, fmt.Errorf("there is not tx with %s for document %s", txid, documentid)
-// }
-
-// GetVerifiersOfDoc get verifiers of perticular doc
-func (d *DocContract) GetVerifiersOfDoc(ctx contractapi.TransactionContextInterface, documentid string) Response {
+// GetVerifiersOfDoc get verifiers of perticular doc with status of each verification,
+// pass activeOnly to skip expired verifications and verifications of earlier versions
+func (d *DocContract) GetVerifiersOfDoc(ctx contractapi.TransactionContextInterface, documentid string, activeOnly bool) Response {
 	response := Response{
 		TxID:    ctx.GetStub().GetTxID(),
 		Success: false,
@@ -298,7 +589,7 @@ func (d *DocContract) GetVerifiersOfDoc(ctx contractapi.TransactionContextInterf
 		Data:    nil,
 	}
 
-	docAsBytes, err := ctx.GetStub().GetState(documentid)
+	docAsBytes, err := getObject(ctx, DocumentObject, documentid)
 	if err != nil {
 		response.Message = fmt.Sprintf("Error while fetching doc from world state: %s" + err.Error())
 		logger.Error(response.Message)
@@ -313,7 +604,24 @@ func (d *DocContract) GetVerifiersOfDoc(ctx contractapi.TransactionContextInterf
 	var doc Document
 	json.Unmarshal(docAsBytes, &doc)
 
-	response.Data = doc.Verifications
+	// verifications of earlier versions are reported as stale
+	latest, _ := doc.LatestVersion()
+	view, err := buildVerificationsView(ctx, PolicyScopeDocument, doc.DocumentType, doc.Verifications, nil, latest.Hash, activeOnly)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while evaluating verifications: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+	txTime, err := getTxTimestamp(ctx)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while getting tx timestamp: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+	view.DocumentStatus = doc.EffectiveStatus(txTime)
+	view.Verified = view.Verified && view.DocumentStatus == DocumentActive
+
+	response.Data = view
 	response.Success = true
 	response.Message = fmt.Sprintf("Successfully fetched verifications of doc %s", documentid)
 	logger.Info(response.Message)
@@ -348,12 +656,19 @@ func (d *DocContract) GetSignature(ctx contractapi.TransactionContextInterface,
 	}
 	defer resultIterator.Close()
 
+	txTime, err := getTxTimestamp(ctx)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while getting tx timestamp: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
 	result := []Document{}
 	for resultIterator.HasNext() {
 		queryResponse, _ := resultIterator.Next()
 
 		doc := new(Document)
 		_ = json.Unmarshal(queryResponse.Value, doc)
+		doc.Status = doc.EffectiveStatus(txTime)
 		result = append(result, *doc)
 	}
 
@@ -363,3 +678,76 @@ func (d *DocContract) GetSignature(ctx contractapi.TransactionContextInterface,
 	logger.Info(response.Message)
 	return response
 }
+
+// CheckSignatureOTP checks OTP and salt disclosed off-chain against OTP hash of signer's signatures on document
+func (d *DocContract) CheckSignatureOTP(ctx contractapi.TransactionContextInterface, documentid string, signer string, otpCode string, salt string) Response {
+	response := Response{
+		TxID:    ctx.GetStub().GetTxID(),
+		Success: false,
+		Message: "",
+		Data:    nil,
+	}
+
+	docAsBytes, err := getObject(ctx, DocumentObject, documentid)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while fetching doc from world state: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+	if docAsBytes == nil {
+		response.Message = fmt.Sprintf("Document with id %s doesn't exist", documentid)
+		logger.Info(response.Message)
+		return response
+	}
+
+	var doc Document
+	json.Unmarshal(docAsBytes, &doc)
+
+	otpHash := saltedHash(salt, otpCode)
+	matches := false
+	for _, signature := range doc.Signature {
+		if signature.AkcessID == signer && signature.OTPHash == otpHash {
+			matches = true
+			break
+		}
+	}
+
+	response.Data = matches
+	response.Success = true
+	response.Message = fmt.Sprintf("Checked OTP of %s's signature on document %s", signer, documentid)
+	logger.Info(response.Message)
+	return response
+}
+
+// CheckShareReceiver checks receiver and salt disclosed off-chain against receiver hashes of document share
+func (d *DocContract) CheckShareReceiver(ctx contractapi.TransactionContextInterface, sharingid string, receiver string, salt string) Response {
+	response := Response{
+		TxID:    ctx.GetStub().GetTxID(),
+		Success: false,
+		Message: "",
+		Data:    nil,
+	}
+
+	shareAsBytes, err := getObject(ctx, DocShareObject, sharingid)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while fetching share from world state: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+	if shareAsBytes == nil {
+		response.Message = fmt.Sprintf("Share with id %s doesn't exist", sharingid)
+		logger.Info(response.Message)
+		return response
+	}
+
+	var share DocumentShare
+	json.Unmarshal(shareAsBytes, &share)
+
+	_, matches := Find(share.ReceiverHashes, saltedHash(salt, receiver))
+
+	response.Data = matches
+	response.Success = true
+	response.Message = fmt.Sprintf("Checked receiver of share %s", sharingid)
+	logger.Info(response.Message)
+	return response
+}
@@ -0,0 +1,336 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// RequestDocVerification owner, or receiver of share with verify permission, asks approved verifier to verify
+// current version of document. Request ID is the tx ID returned in response
+func (d *DocContract) RequestDocVerification(ctx contractapi.TransactionContextInterface, documentid string, verifierID string) Response {
+	response := Response{
+		TxID:    ctx.GetStub().GetTxID(),
+		Success: false,
+		Message: "",
+		Data:    nil,
+	}
+
+	invoker, err := resolveAkcessID(ctx)
+	if err != nil {
+		response.Message = err.Error()
+		logger.Info(response.Message)
+		return response
+	}
+	doc, err := getDocument(ctx, documentid)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while fetching doc from world state: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+	if doc == nil {
+		response.Message = fmt.Sprintf("Document with id %s doesn't exist", documentid)
+		logger.Info(response.Message)
+		return response
+	}
+	txTime, err := getTxTimestamp(ctx)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while getting tx timestamp: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+	if doc.AkcessID != invoker {
+		err = checkSharePermission(ctx, documentid, invoker, SharePermissionVerify, txTime)
+		if err != nil {
+			response.Message = err.Error()
+			logger.Info(response.Message)
+			return response
+		}
+	}
+	latest, found := doc.LatestVersion()
+	if !found {
+		response.Message = fmt.Sprintf("Document %s has no version to verify", documentid)
+		logger.Info(response.Message)
+		return response
+	}
+	err = checkDocumentActive(doc, txTime)
+	if err != nil {
+		response.Message = err.Error()
+		logger.Info(response.Message)
+		return response
+	}
+
+	verifierAsBytes, err := getObject(ctx, VerifierObject, verifierID)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while fetching verifier from world state: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+	if verifierAsBytes == nil {
+		response.Message = fmt.Sprintf("Verifier with id %s doesn't exist", verifierID)
+		logger.Info(response.Message)
+		return response
+	}
+	var verifier Verifier
+	json.Unmarshal(verifierAsBytes, &verifier)
+	if !verifier.IsApproved() {
+		response.Message = fmt.Sprintf("Verifier %s is not approved, current status is %s", verifierID, verifier.CurrentStatus())
+		logger.Info(response.Message)
+		return response
+	}
+
+	open, err := queryDocVerificationRequests(ctx, fmt.Sprintf(`{
+		"selector": {
+		   "docType": "%s",
+		   "documentID": "%s",
+		   "verifierId": "%s",
+		   "status": "%s"
+		}
+	}`, DocRequestObject, documentid, verifierID, RequestPending))
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while fetching verification requests: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+	if len(open) > 0 {
+		response.Message = fmt.Sprintf("Verification of document %s by %s is already requested in request %s", documentid, verifierID, open[0].RequestID)
+		logger.Info(response.Message)
+		return response
+	}
+
+	request := DocVerificationRequest{
+		ObjectType:      DocRequestObject,
+		RequestID:       response.TxID,
+		DocumentID:      documentid,
+		DocumentVersion: latest.Version,
+		Owner:           doc.AkcessID,
+		RequestedBy:     invoker,
+		VerifierID:      verifierID,
+		Status:          RequestPending,
+		RequestedAt:     txTime,
+	}
+	requestAsBytes, _ := json.Marshal(request)
+	err = putObject(ctx, DocRequestObject, request.RequestID, requestAsBytes)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while saving verification request: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+
+	response.Success = true
+	response.Message = fmt.Sprintf("Verification of document %s requested from %s", documentid, verifierID)
+	logger.Info(response.Message)
+	response.Data = request
+	return response
+}
+
+// ApproveDocVerification verifier approves verification request and verifies current version of document
+func (d *DocContract) ApproveDocVerification(ctx contractapi.TransactionContextInterface, requestID string, expiryDate string) Response {
+	response := Response{
+		TxID:    ctx.GetStub().GetTxID(),
+		Success: false,
+		Message: "",
+		Data:    nil,
+	}
+
+	invoker, err := resolveAkcessID(ctx)
+	if err != nil {
+		response.Message = err.Error()
+		logger.Info(response.Message)
+		return response
+	}
+	request, err := getOpenDocVerificationRequest(ctx, requestID, invoker)
+	if err != nil {
+		response.Message = err.Error()
+		logger.Info(response.Message)
+		return response
+	}
+
+	expirydate, err := time.Parse(time.RFC3339, expiryDate)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while parsing date pass date in ISO format: %s", err.Error())
+		logger.Info(response.Message)
+		return response
+	}
+
+	doc, err := getDocument(ctx, request.DocumentID)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while fetching doc from world state: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+	if doc == nil {
+		response.Message = fmt.Sprintf("Document with id %s doesn't exist", request.DocumentID)
+		logger.Info(response.Message)
+		return response
+	}
+	verification, err := verifyDocument(ctx, invoker, doc, expirydate)
+	if err != nil {
+		response.Message = err.Error()
+		logger.Info(response.Message)
+		return response
+	}
+
+	txTime, err := getTxTimestamp(ctx)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while getting tx timestamp: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+	request.Status = RequestApproved
+	request.DocumentVersion = verification.DocumentVersion
+	request.RespondedAt = &txTime
+	requestAsBytes, _ := json.Marshal(request)
+	err = putObject(ctx, DocRequestObject, requestID, requestAsBytes)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while updating verification request: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+
+	response.Success = true
+	response.Message = fmt.Sprintf("Verification request %s approved, version %d of document %s verified by %s", requestID, verification.DocumentVersion, request.DocumentID, invoker)
+	logger.Info(response.Message)
+	response.Data = request
+	return response
+}
+
+// RejectDocVerification verifier rejects verification request with a reason
+func (d *DocContract) RejectDocVerification(ctx contractapi.TransactionContextInterface, requestID string, reason string) Response {
+	response := Response{
+		TxID:    ctx.GetStub().GetTxID(),
+		Success: false,
+		Message: "",
+		Data:    nil,
+	}
+
+	invoker, err := resolveAkcessID(ctx)
+	if err != nil {
+		response.Message = err.Error()
+		logger.Info(response.Message)
+		return response
+	}
+	request, err := getOpenDocVerificationRequest(ctx, requestID, invoker)
+	if err != nil {
+		response.Message = err.Error()
+		logger.Info(response.Message)
+		return response
+	}
+	if reason == "" {
+		response.Message = fmt.Sprint("Reason of rejection can't be empty")
+		logger.Info(response.Message)
+		return response
+	}
+
+	txTime, err := getTxTimestamp(ctx)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while getting tx timestamp: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+	request.Status = RequestRejected
+	request.Reason = reason
+	request.RespondedAt = &txTime
+	requestAsBytes, _ := json.Marshal(request)
+	err = putObject(ctx, DocRequestObject, requestID, requestAsBytes)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while updating verification request: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+
+	response.Success = true
+	response.Message = fmt.Sprintf("Verification request %s rejected by %s", requestID, invoker)
+	logger.Info(response.Message)
+	response.Data = request
+	return response
+}
+
+// GetDocVerificationRequests returns verification requests of documents invoker owns, requests invoker made
+// and requests invoker received as verifier
+// in given status, pass pending to list open requests or empty status to list all
+func (d *DocContract) GetDocVerificationRequests(ctx contractapi.TransactionContextInterface, status string) Response {
+	response := Response{
+		TxID:    ctx.GetStub().GetTxID(),
+		Success: false,
+		Message: "",
+		Data:    nil,
+	}
+
+	invoker, err := resolveAkcessID(ctx)
+	if err != nil {
+		response.Message = err.Error()
+		logger.Info(response.Message)
+		return response
+	}
+
+	statusSelector := ""
+	if status != "" {
+		statusSelector = fmt.Sprintf(`"status": "%s",`, status)
+	}
+	result, err := queryDocVerificationRequests(ctx, fmt.Sprintf(`{
+		"selector": {
+		   "docType": "%s",
+		   %s
+		   "$or": [
+			  { "owner": "%s" },
+			  { "requestedBy": "%s" },
+			  { "verifierId": "%s" }
+		   ]
+		}
+	}`, DocRequestObject, statusSelector, invoker, invoker, invoker))
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while fetching verification requests: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+
+	response.Success = true
+	response.Message = fmt.Sprintf("Successfully fetched verification requests of %s", invoker)
+	logger.Info(response.Message)
+	response.Data = result
+	return response
+}
+
+// getOpenDocVerificationRequest returns pending verification request addressed to verifier
+func getOpenDocVerificationRequest(ctx contractapi.TransactionContextInterface, requestID string, verifierID string) (*DocVerificationRequest, error) {
+	requestAsBytes, err := getObject(ctx, DocRequestObject, requestID)
+	if err != nil {
+		return nil, fmt.Errorf("Error while fetching verification request: %s", err.Error())
+	}
+	if requestAsBytes == nil {
+		return nil, fmt.Errorf("Verification request %s doesn't exist", requestID)
+	}
+	var request DocVerificationRequest
+	json.Unmarshal(requestAsBytes, &request)
+	if request.VerifierID != verifierID {
+		return nil, fmt.Errorf("Verification request %s is not addressed to %s", requestID, verifierID)
+	}
+	if request.Status != RequestPending {
+		return nil, fmt.Errorf("Verification request %s is already %s", requestID, request.Status)
+	}
+	return &request, nil
+}
+
+// queryDocVerificationRequests returns verification requests matching rich query
+func queryDocVerificationRequests(ctx contractapi.TransactionContextInterface, queryString string) ([]DocVerificationRequest, error) {
+	resultIterator, err := ctx.GetStub().GetQueryResult(queryString)
+	if err != nil {
+		return nil, err
+	}
+	defer resultIterator.Close()
+
+	result := []DocVerificationRequest{}
+	for resultIterator.HasNext() {
+		queryResponse, err := resultIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+		request := new(DocVerificationRequest)
+		_ = json.Unmarshal(queryResponse.Value, request)
+		result = append(result, *request)
+	}
+	return result, nil
+}
@@ -0,0 +1,210 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+// requestVerification owner bob asks vera to verify doc1 and returns ID of the request
+func requestVerification(l *testLedger) string {
+	l.t.Helper()
+	response := new(DocContract).RequestDocVerification(l.as("Org1MSP", "bob", nil), "doc1", "vera")
+	expectResponse(l.t, response, "")
+	return response.Data.(DocVerificationRequest).RequestID
+}
+
+func TestRequestDocVerification(t *testing.T) {
+	tests := []struct {
+		name          string
+		setup         func(l *testLedger)
+		requester     string
+		verifier      string
+		expectedError string
+	}{
+		{name: "owner requests verification", setup: func(l *testLedger) {}, requester: "bob", verifier: "vera"},
+		{
+			name:          "user without share",
+			setup:         func(l *testLedger) {},
+			requester:     "mallory",
+			verifier:      "vera",
+			expectedError: "Identity mallory holds no share of document doc1 with verify permission",
+		},
+		{
+			name: "receiver of view only share",
+			setup: func(l *testLedger) {
+				expectResponse(l.t, shareDoc(l, "bob", "share1", []string{"mallory"}, []string{SharePermissionView}), "")
+			},
+			requester:     "mallory",
+			verifier:      "vera",
+			expectedError: "Identity mallory holds no share of document doc1 with verify permission",
+		},
+		{
+			name: "receiver of verify share",
+			setup: func(l *testLedger) {
+				expectResponse(l.t, shareDoc(l, "bob", "share1", []string{"mallory"}, []string{SharePermissionVerify}), "")
+			},
+			requester: "mallory",
+			verifier:  "vera",
+		},
+		{name: "unknown verifier", setup: func(l *testLedger) {}, requester: "bob", verifier: "ghost", expectedError: "Verifier with id ghost doesn't exist"},
+		{
+			name: "verifier which is not approved",
+			setup: func(l *testLedger) {
+				expectResponse(l.t, new(UserContract).CreateVerifier(l.as("Org1MSP", "vince", verifierAttrs), "Vince", "gold"), "")
+			},
+			requester:     "bob",
+			verifier:      "vince",
+			expectedError: "Verifier vince is not approved",
+		},
+		{
+			name:          "verification already requested",
+			setup:         func(l *testLedger) { requestVerification(l) },
+			requester:     "bob",
+			verifier:      "vera",
+			expectedError: "Verification of document doc1 by vera is already requested",
+		},
+		{
+			name: "revoked document",
+			setup: func(l *testLedger) {
+				expectResponse(l.t, new(DocContract).RevokeDocument(l.as("Org1MSP", "bob", nil), "doc1", "lost"), "")
+			},
+			requester:     "bob",
+			verifier:      "vera",
+			expectedError: "Document doc1 is revoked",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := newDocLedger(t)
+			tt.setup(l)
+			response := new(DocContract).RequestDocVerification(l.as("Org1MSP", tt.requester, nil), "doc1", tt.verifier)
+			expectResponse(t, response, tt.expectedError)
+			if tt.expectedError != "" {
+				return
+			}
+			request := response.Data.(DocVerificationRequest)
+			if request.Status != RequestPending || request.Owner != "bob" || request.RequestedBy != tt.requester || request.DocumentVersion != 1 {
+				t.Fatalf("expected pending request of version 1 by %s, got %+v", tt.requester, request)
+			}
+		})
+	}
+}
+
+func TestApproveDocVerification(t *testing.T) {
+	tests := []struct {
+		name          string
+		setup         func(l *testLedger, requestID string)
+		verifier      string
+		requestID     string
+		expiryDate    string
+		expectedError string
+	}{
+		{name: "addressed verifier approves", setup: func(l *testLedger, requestID string) {}, verifier: "vera"},
+		{name: "other verifier", setup: func(l *testLedger, requestID string) {}, verifier: "vic", expectedError: "is not addressed to vic"},
+		{name: "unknown request", setup: func(l *testLedger, requestID string) {}, verifier: "vera", requestID: "tx0", expectedError: "Verification request tx0 doesn't exist"},
+		{name: "invalid expiry date", setup: func(l *testLedger, requestID string) {}, verifier: "vera", expiryDate: "tomorrow", expectedError: "Error while parsing date"},
+		{
+			name: "rejected request",
+			setup: func(l *testLedger, requestID string) {
+				expectResponse(l.t, new(DocContract).RejectDocVerification(l.as("Org1MSP", "vera", verifierAttrs), requestID, "blurry"), "")
+			},
+			verifier:      "vera",
+			expectedError: "is already rejected",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := newDocLedger(t)
+			requestID := requestVerification(l)
+			tt.setup(l, requestID)
+			if tt.requestID != "" {
+				requestID = tt.requestID
+			}
+			expiryDate := tt.expiryDate
+			if expiryDate == "" {
+				expiryDate = l.now().AddDate(1, 0, 0).Format(time.RFC3339)
+			}
+			response := new(DocContract).ApproveDocVerification(l.as("Org1MSP", tt.verifier, verifierAttrs), requestID, expiryDate)
+			expectResponse(t, response, tt.expectedError)
+			if tt.expectedError != "" {
+				return
+			}
+			if request := response.Data.(*DocVerificationRequest); request.Status != RequestApproved || request.RespondedAt == nil {
+				t.Fatalf("expected approved request, got %+v", request)
+			}
+			doc, _ := getDocument(l.as("Org1MSP", "bob", nil), "doc1")
+			if len(doc.Verifications) != 1 || doc.Verifications[0].VerifierID != "vera" || doc.Verifications[0].DocumentVersion != 1 {
+				t.Fatalf("expected version 1 verified by vera, got %+v", doc.Verifications)
+			}
+			expectResponse(t, new(DocContract).ApproveDocVerification(l.as("Org1MSP", "vera", verifierAttrs), requestID, expiryDate), "is already approved")
+		})
+	}
+}
+
+func TestRejectDocVerification(t *testing.T) {
+	tests := []struct {
+		name          string
+		verifier      string
+		reason        string
+		expectedError string
+	}{
+		{name: "addressed verifier rejects", verifier: "vera", reason: "blurry"},
+		{name: "other verifier", verifier: "vic", reason: "blurry", expectedError: "is not addressed to vic"},
+		{name: "no reason", verifier: "vera", expectedError: "Reason of rejection can't be empty"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := newDocLedger(t)
+			requestID := requestVerification(l)
+			response := new(DocContract).RejectDocVerification(l.as("Org1MSP", tt.verifier, verifierAttrs), requestID, tt.reason)
+			expectResponse(t, response, tt.expectedError)
+			if tt.expectedError != "" {
+				return
+			}
+			if request := response.Data.(*DocVerificationRequest); request.Status != RequestRejected || request.Reason != tt.reason {
+				t.Fatalf("expected request rejected for %s, got %+v", tt.reason, request)
+			}
+			doc, _ := getDocument(l.as("Org1MSP", "bob", nil), "doc1")
+			if len(doc.Verifications) != 0 {
+				t.Fatalf("expected rejected request not to verify document, got %+v", doc.Verifications)
+			}
+		})
+	}
+}
+
+func TestGetDocVerificationRequests(t *testing.T) {
+	l := newDocLedger(t)
+	requestID := requestVerification(l)
+	rejected := new(DocContract).RequestDocVerification(l.as("Org1MSP", "bob", nil), "doc1", "vic")
+	expectResponse(t, rejected, "")
+	expectResponse(t, new(DocContract).RejectDocVerification(l.as("Org1MSP", "vic", verifierAttrs), rejected.TxID, "blurry"), "")
+
+	tests := []struct {
+		name     string
+		invoker  string
+		status   string
+		expected int
+	}{
+		{name: "owner sees all requests", invoker: "bob", expected: 2},
+		{name: "owner filters by status", invoker: "bob", status: RequestPending, expected: 1},
+		{name: "verifier sees requests addressed to it", invoker: "vera", expected: 1},
+		{name: "other user sees none", invoker: "mallory", expected: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := new(DocContract).GetDocVerificationRequests(l.as("Org1MSP", tt.invoker, nil), tt.status)
+			expectResponse(t, response, "")
+			requests := response.Data.([]DocVerificationRequest)
+			if len(requests) != tt.expected {
+				t.Fatalf("expected %d requests, got %+v", tt.expected, requests)
+			}
+			if tt.invoker == "vera" && requests[0].RequestID != requestID {
+				t.Fatalf("expected request %s, got %+v", requestID, requests[0])
+			}
+		})
+	}
+}
@@ -0,0 +1,234 @@
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// AddDocumentVersion owner adds new version of document, signatures and verifications
+// made before stay tied to the version they were made on
+func (d *DocContract) AddDocumentVersion(ctx contractapi.TransactionContextInterface, documentid string, hash string, hashAlgorithm string, note string) Response {
+	response := Response{
+		TxID:    ctx.GetStub().GetTxID(),
+		Success: false,
+		Message: "",
+		Data:    nil,
+	}
+
+	invoker, err := resolveAkcessID(ctx)
+	if err != nil {
+		response.Message = err.Error()
+		logger.Info(response.Message)
+		return response
+	}
+	doc, err := getDocument(ctx, documentid)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while fetching doc from world state: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+	if doc == nil {
+		response.Message = fmt.Sprintf("Document with id %s doesn't exist", documentid)
+		logger.Info(response.Message)
+		return response
+	}
+	if doc.AkcessID != invoker {
+		response.Message = fmt.Sprintf("Identity %s is not owner of document %s", invoker, documentid)
+		logger.Info(response.Message)
+		return response
+	}
+
+	hash = strings.ToLower(hash)
+	err = validateDocumentHash(hash, hashAlgorithm)
+	if err != nil {
+		response.Message = err.Error()
+		logger.Info(response.Message)
+		return response
+	}
+	if latest, found := doc.LatestVersion(); found && latest.Hash == hash {
+		response.Message = fmt.Sprintf("Hash is the same as of current version %d of document %s", latest.Version, documentid)
+		logger.Info(response.Message)
+		return response
+	}
+
+	txTime, err := getTxTimestamp(ctx)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while getting tx timestamp: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+	err = checkDocumentActive(doc, txTime)
+	if err != nil {
+		response.Message = err.Error()
+		logger.Info(response.Message)
+		return response
+	}
+	if doc.EffectiveSigningStatus(txTime) == SigningAwaitingSignatures {
+		response.Message = fmt.Sprintf("Document %s is awaiting signatures, new version can't be added until signing ends", documentid)
+		logger.Info(response.Message)
+		return response
+	}
+
+	version := DocumentVersion{
+		Version:       len(doc.Versions) + 1,
+		Hash:          hash,
+		HashAlgorithm: hashAlgorithm,
+		Note:          note,
+		Author:        invoker,
+		CreatedAt:     txTime,
+	}
+	doc.Versions = append(doc.Versions, version)
+	// new version has to be signed again under the same workflow
+	doc.SigningStatus = SigningDraft
+	docAsBytes, _ := json.Marshal(doc)
+	err = putObject(ctx, DocumentObject, documentid, docAsBytes)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while adding document version: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+	err = putDocumentHashIndex(ctx, documentid, version)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while indexing doc hash: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+
+	response.Success = true
+	response.Message = fmt.Sprintf("Version %d of document %s added", version.Version, documentid)
+	logger.Info(response.Message)
+	response.Data = version
+	return response
+}
+
+// DocumentVersionView version of document together with effective lifecycle status of document
+type DocumentVersionView struct {
+	DocumentVersion
+	DocumentStatus string `json:"documentStatus"`
+}
+
+// GetLatestDocumentVersion returns current version of document
+func (d *DocContract) GetLatestDocumentVersion(ctx contractapi.TransactionContextInterface, documentid string) Response {
+	response := Response{
+		TxID:    ctx.GetStub().GetTxID(),
+		Success: false,
+		Message: "",
+		Data:    nil,
+	}
+
+	doc, err := getDocument(ctx, documentid)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while fetching doc from world state: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+	if doc == nil {
+		response.Message = fmt.Sprintf("Document with id %s doesn't exist", documentid)
+		logger.Info(response.Message)
+		return response
+	}
+	latest, found := doc.LatestVersion()
+	if !found {
+		response.Message = fmt.Sprintf("Document %s has no versions", documentid)
+		logger.Info(response.Message)
+		return response
+	}
+	txTime, err := getTxTimestamp(ctx)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while getting tx timestamp: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+
+	response.Success = true
+	response.Message = fmt.Sprintf("Successfully fetched version %d of document %s", latest.Version, documentid)
+	logger.Info(response.Message)
+	response.Data = DocumentVersionView{
+		DocumentVersion: latest,
+		DocumentStatus:  doc.EffectiveStatus(txTime),
+	}
+	return response
+}
+
+// GetDocumentVersion returns given version of document, versions are numbered from 1
+func (d *DocContract) GetDocumentVersion(ctx contractapi.TransactionContextInterface, documentid string, version int) Response {
+	response := Response{
+		TxID:    ctx.GetStub().GetTxID(),
+		Success: false,
+		Message: "",
+		Data:    nil,
+	}
+
+	doc, err := getDocument(ctx, documentid)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while fetching doc from world state: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+	if doc == nil {
+		response.Message = fmt.Sprintf("Document with id %s doesn't exist", documentid)
+		logger.Info(response.Message)
+		return response
+	}
+	if version < 1 || version > len(doc.Versions) {
+		response.Message = fmt.Sprintf("Document %s has no version %d", documentid, version)
+		logger.Info(response.Message)
+		return response
+	}
+	txTime, err := getTxTimestamp(ctx)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while getting tx timestamp: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+
+	response.Success = true
+	response.Message = fmt.Sprintf("Successfully fetched version %d of document %s", version, documentid)
+	logger.Info(response.Message)
+	response.Data = DocumentVersionView{
+		DocumentVersion: doc.Versions[version-1],
+		DocumentStatus:  doc.EffectiveStatus(txTime),
+	}
+	return response
+}
+
+// detectHashAlgorithm returns supported hash algorithm of hex encoded digest by its size, unspecified for other hashes
+func detectHashAlgorithm(hash string) string {
+	for hashAlgorithm := range hashAlgorithms {
+		if validateDocumentHash(hash, hashAlgorithm) == nil {
+			return hashAlgorithm
+		}
+	}
+	return LegacyHashAlgorithm
+}
+
+// getDocument reads document, nil if it doesn't exist
+func getDocument(ctx contractapi.TransactionContextInterface, documentid string) (*Document, error) {
+	docAsBytes, err := getObject(ctx, DocumentObject, documentid)
+	if err != nil || docAsBytes == nil {
+		return nil, err
+	}
+	var doc Document
+	err = json.Unmarshal(docAsBytes, &doc)
+	if err != nil {
+		return nil, err
+	}
+	return &doc, nil
+}
+
+// validateDocumentHash checks hash is hex encoded digest of supported hash algorithm
+func validateDocumentHash(hash string, hashAlgorithm string) error {
+	size, found := hashAlgorithms[hashAlgorithm]
+	if !found {
+		return fmt.Errorf("Hash algorithm %s is not supported, use sha256, sha384 or sha512", hashAlgorithm)
+	}
+	decoded, err := hex.DecodeString(hash)
+	if err != nil || len(decoded) != size {
+		return fmt.Errorf("Hash must be hex encoded %s digest", hashAlgorithm)
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestCreateDoc(t *testing.T) {
+	sha256Hash := saltedHash("", "content")
+	tests := []struct {
+		name               string
+		hashes             []string
+		expectedAlgorithms []string
+		expectedError      string
+	}{
+		{name: "single hash", hashes: []string{sha256Hash}, expectedAlgorithms: []string{"sha256"}},
+		{name: "hash of every version", hashes: []string{"abcd", strings.ToUpper(sha256Hash)}, expectedAlgorithms: []string{LegacyHashAlgorithm, "sha256"}},
+		{name: "no hash", hashes: []string{}, expectedError: "Pass at least one document hash"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := newTestLedger(t)
+			expectResponse(t, new(UserContract).CreateUser(l.as("Org1MSP", "bob", nil)), "")
+			expectResponse(t, new(DocContract).CreateDoc(l.as("Org1MSP", "bob", nil), "doc1", tt.hashes), tt.expectedError)
+			if tt.expectedError != "" {
+				return
+			}
+			doc, _ := getDocument(l.as("Org1MSP", "bob", nil), "doc1")
+			algorithms := []string{}
+			for i, version := range doc.Versions {
+				algorithms = append(algorithms, version.HashAlgorithm)
+				if version.Version != i+1 || version.Hash != strings.ToLower(tt.hashes[i]) || version.Author != "bob" {
+					t.Fatalf("expected version %d with hash %s by bob, got %+v", i+1, tt.hashes[i], version)
+				}
+			}
+			if !equalStrings(algorithms, tt.expectedAlgorithms) {
+				t.Fatalf("expected hash algorithms %v, got %v", tt.expectedAlgorithms, algorithms)
+			}
+			expectResponse(t, new(DocContract).CreateDoc(l.as("Org1MSP", "bob", nil), "doc1", tt.hashes), "Document with id doc1 already exist")
+		})
+	}
+}
+
+func TestAddDocumentVersion(t *testing.T) {
+	tests := []struct {
+		name            string
+		setup           func(l *testLedger)
+		owner           string
+		hash            string
+		hashAlgorithm   string
+		expectedVersion int
+		expectedError   string
+	}{
+		{name: "owner adds version", setup: func(l *testLedger) {}, owner: "bob", hash: saltedHash("", "v2"), hashAlgorithm: "sha256", expectedVersion: 2},
+		{name: "wrong owner", setup: func(l *testLedger) {}, owner: "mallory", hash: saltedHash("", "v2"), hashAlgorithm: "sha256", expectedError: "Identity mallory is not owner of document doc1"},
+		{name: "unsupported algorithm", setup: func(l *testLedger) {}, owner: "bob", hash: saltedHash("", "v2"), hashAlgorithm: "md5", expectedError: "Hash algorithm md5 is not supported"},
+		{name: "hash of other algorithm", setup: func(l *testLedger) {}, owner: "bob", hash: saltedHash("", "v2"), hashAlgorithm: "sha512", expectedError: "Hash must be hex encoded sha512 digest"},
+		{name: "hash of current version", setup: func(l *testLedger) {}, owner: "bob", hash: saltedHash("", "content"), hashAlgorithm: "sha256", expectedError: "Hash is the same as of current version 1 of document doc1"},
+		{
+			name: "document awaiting signatures",
+			setup: func(l *testLedger) {
+				expectResponse(l.t, defineWorkflow(l, []string{"vera"}, SigningParallel, 0), "")
+				expectResponse(l.t, new(DocContract).StartSigning(l.as("Org1MSP", "bob", nil), "doc1"), "")
+			},
+			owner:         "bob",
+			hash:          saltedHash("", "v2"),
+			hashAlgorithm: "sha256",
+			expectedError: "Document doc1 is awaiting signatures, new version can't be added until signing ends",
+		},
+		{
+			name: "revoked document",
+			setup: func(l *testLedger) {
+				expectResponse(l.t, new(DocContract).RevokeDocument(l.as("Org1MSP", "bob", nil), "doc1", "lost"), "")
+			},
+			owner:         "bob",
+			hash:          saltedHash("", "v2"),
+			hashAlgorithm: "sha256",
+			expectedError: "Document doc1 is revoked",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := newDocLedger(t)
+			tt.setup(l)
+			response := new(DocContract).AddDocumentVersion(l.as("Org1MSP", tt.owner, nil), "doc1", tt.hash, tt.hashAlgorithm, "")
+			expectResponse(t, response, tt.expectedError)
+			if tt.expectedError != "" {
+				return
+			}
+			latest := new(DocContract).GetLatestDocumentVersion(l.as("Org1MSP", "vera", nil), "doc1")
+			expectResponse(t, latest, "")
+			if version := latest.Data.(DocumentVersionView); version.Version != tt.expectedVersion || version.Hash != tt.hash || version.DocumentStatus != DocumentActive {
+				t.Fatalf("expected active version %d with hash %s, got %+v", tt.expectedVersion, tt.hash, version)
+			}
+		})
+	}
+}
+
+func TestGetDocumentVersion(t *testing.T) {
+	l := newDocLedger(t)
+	expectResponse(t, new(DocContract).AddDocumentVersion(l.as("Org1MSP", "bob", nil), "doc1", saltedHash("", "v2"), "sha256", "second"), "")
+
+	tests := []struct {
+		name          string
+		version       int
+		expectedHash  string
+		expectedError string
+	}{
+		{name: "first version", version: 1, expectedHash: saltedHash("", "content")},
+		{name: "second version", version: 2, expectedHash: saltedHash("", "v2")},
+		{name: "version zero", version: 0, expectedError: "Document doc1 has no version 0"},
+		{name: "version after latest", version: 3, expectedError: "Document doc1 has no version 3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := new(DocContract).GetDocumentVersion(l.as("Org1MSP", "vera", nil), "doc1", tt.version)
+			expectResponse(t, response, tt.expectedError)
+			if tt.expectedError == "" && response.Data.(DocumentVersionView).Hash != tt.expectedHash {
+				t.Fatalf("expected hash %s, got %+v", tt.expectedHash, response.Data)
+			}
+		})
+	}
+}
+
+func TestLegacyDocumentVersions(t *testing.T) {
+	legacy := `{"docType":"document","documentId":"doc1","documentHash":["aa","bb"],"akcessId":"bob",` +
+		`"signature":[{"signatureHash":"sig","akcessId":"vera"}],"verifications":[{"verifierId":"vic"}]}`
+	var doc Document
+	if err := json.Unmarshal([]byte(legacy), &doc); err != nil {
+		t.Fatalf("unmarshalling legacy document: %s", err.Error())
+	}
+	if len(doc.Versions) != 2 || doc.Versions[1].Hash != "bb" || doc.Versions[1].HashAlgorithm != LegacyHashAlgorithm || doc.Versions[1].Author != "bob" {
+		t.Fatalf("expected legacy hashes to become versions, got %+v", doc.Versions)
+	}
+	if doc.Signature[0].DocumentVersion != 2 || doc.Verifications[0].DocumentVersion != 2 || doc.Verifications[0].Commitment != "bb" {
+		t.Fatalf("expected legacy signature and verification to be tied to last version, got %+v and %+v", doc.Signature, doc.Verifications)
+	}
+}
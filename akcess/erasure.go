@@ -0,0 +1,231 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+
+	"otp"
+)
+
+// ErasureResult summary of data erased together with a user
+type ErasureResult struct {
+	Tombstone            UserTombstone `json:"tombstone"`
+	ProfileValues        int           `json:"profileValues"`        // profile values deleted from private data collection
+	SignatureOTPs        int           `json:"signatureOTPs"`        // OTPs deleted from private data collection
+	OTPChallenges        int           `json:"otpChallenges"`        // OTP challenges deleted from private data collection
+	SignaturesAnonymized int           `json:"signaturesAnonymized"` // signatures whose signer was replaced by ErasedAkcessID
+	SharesDeleted        int           `json:"sharesDeleted"`        // shares sent by the user
+	SharesUpdated        int           `json:"sharesUpdated"`        // shares the user was removed from as receiver
+	IdentityBindings     int           `json:"identityBindings"`     // identities unbound from AKcessID
+}
+
+// eraseUserData deletes or anonymizes private data entries, shares and signatures of user
+func eraseUserData(ctx contractapi.TransactionContextInterface, akcessID string) (ErasureResult, error) {
+	result := ErasureResult{}
+	collections, err := getPrivateDataConfig(ctx)
+	if err != nil {
+		return result, err
+	}
+
+	result.ProfileValues, err = deletePrivateObjects(ctx, collections.ProfileCollection, "profilevalue", []string{akcessID})
+	if err != nil {
+		return result, err
+	}
+	result.OTPChallenges, err = deletePrivateObjects(ctx, collections.OTPCollection, otp.ObjectType, []string{akcessID})
+	if err != nil {
+		return result, err
+	}
+
+	signedDocs, err := queryDocuments(ctx, fmt.Sprintf(`{
+		"selector": {
+		   "docType": "document",
+		   "signature": {
+			  "$elemMatch": {
+				 "akcessId": "%s"
+			  }
+		   }
+		}
+	 }`, akcessID))
+	if err != nil {
+		return result, err
+	}
+	for _, doc := range signedDocs {
+		deleted, err := deletePrivateObjects(ctx, collections.OTPCollection, "signatureotp", []string{doc.DocumentID, akcessID})
+		if err != nil {
+			return result, err
+		}
+		result.SignatureOTPs += deleted
+
+		for i := range doc.Signature {
+			if doc.Signature[i].AkcessID == akcessID {
+				doc.Signature[i].AkcessID = ErasedAkcessID
+				doc.Signature[i].OTPHash = ""
+				doc.Signature[i].OTPChallenge = ""
+				result.SignaturesAnonymized++
+			}
+		}
+		docAsBytes, _ := json.Marshal(doc)
+		err = putObject(ctx, DocumentObject, doc.DocumentID, docAsBytes)
+		if err != nil {
+			return result, err
+		}
+	}
+
+	sentShares, err := ctx.GetStub().GetQueryResult(fmt.Sprintf(`{
+		"selector": {
+		   "docType": "docshare",
+		   "sender": "%s"
+		}
+	}`, akcessID))
+	if err != nil {
+		return result, err
+	}
+	defer sentShares.Close()
+	for sentShares.HasNext() {
+		queryResponse, err := sentShares.Next()
+		if err != nil {
+			return result, err
+		}
+		var share DocumentShare
+		json.Unmarshal(queryResponse.Value, &share)
+
+		receiversKey, _ := ctx.GetStub().CreateCompositeKey("sharereceivers", []string{share.SharingID})
+		err = ctx.GetStub().DelPrivateData(collections.ShareCollection, receiversKey)
+		if err != nil {
+			return result, err
+		}
+		err = deleteObject(ctx, DocShareObject, share.SharingID)
+		if err != nil {
+			return result, err
+		}
+		result.SharesDeleted++
+	}
+
+	receivedShares, err := ctx.GetStub().GetPrivateDataQueryResult(collections.ShareCollection, fmt.Sprintf(`{
+		"selector": {
+		   "docType": "sharereceivers",
+		   "receivers": {
+			  "$elemMatch": {
+				 "$eq": "%s"
+			  }
+		   }
+		}
+	}`, akcessID))
+	if err != nil {
+		return result, err
+	}
+	defer receivedShares.Close()
+	for receivedShares.HasNext() {
+		queryResponse, err := receivedShares.Next()
+		if err != nil {
+			return result, err
+		}
+		var receivers ShareReceivers
+		json.Unmarshal(queryResponse.Value, &receivers)
+		err = removeShareReceiver(ctx, collections.ShareCollection, queryResponse.Key, receivers, akcessID)
+		if err != nil {
+			return result, err
+		}
+		result.SharesUpdated++
+	}
+
+	// verifier keeps acting under identities bound to its AKcessID
+	verifierAsBytes, err := getObject(ctx, VerifierObject, akcessID)
+	if err != nil || verifierAsBytes != nil {
+		return result, err
+	}
+	bindings, err := getIdentityBindings(ctx, akcessID)
+	if err != nil {
+		return result, err
+	}
+	for _, binding := range bindings {
+		err = deleteIdentityBinding(ctx, binding)
+		if err != nil {
+			return result, err
+		}
+		result.IdentityBindings++
+	}
+
+	return result, nil
+}
+
+// removeShareReceiver removes receiver from private receivers of share and its hash from public share
+func removeShareReceiver(ctx contractapi.TransactionContextInterface, collection string, receiversKey string, receivers ShareReceivers, receiver string) error {
+	remaining := []string{}
+	for _, r := range receivers.Receivers {
+		if r != receiver {
+			remaining = append(remaining, r)
+		}
+	}
+	receivers.Receivers = remaining
+	receiversAsBytes, _ := json.Marshal(receivers)
+	err := ctx.GetStub().PutPrivateData(collection, receiversKey, receiversAsBytes)
+	if err != nil {
+		return err
+	}
+
+	shareAsBytes, err := getObject(ctx, DocShareObject, receivers.SharingID)
+	if err != nil || shareAsBytes == nil {
+		return err
+	}
+	var share DocumentShare
+	json.Unmarshal(shareAsBytes, &share)
+	receiverHash := saltedHash(receivers.Salt, receiver)
+	hashes := []string{}
+	for _, h := range share.ReceiverHashes {
+		if h != receiverHash {
+			hashes = append(hashes, h)
+		}
+	}
+	share.ReceiverHashes = hashes
+	delete(share.Responses, receiverHash)
+	shareAsBytes, _ = json.Marshal(share)
+	return putObject(ctx, DocShareObject, share.SharingID, shareAsBytes)
+}
+
+// deletePrivateObjects deletes all objects of given type whose composite key starts with attributes
+// from private data collection and returns number of deleted objects
+func deletePrivateObjects(ctx contractapi.TransactionContextInterface, collection string, objectType string, attributes []string) (int, error) {
+	resultIterator, err := ctx.GetStub().GetPrivateDataByPartialCompositeKey(collection, objectType, attributes)
+	if err != nil {
+		return 0, err
+	}
+	defer resultIterator.Close()
+
+	deleted := 0
+	for resultIterator.HasNext() {
+		queryResponse, err := resultIterator.Next()
+		if err != nil {
+			return deleted, err
+		}
+		err = ctx.GetStub().DelPrivateData(collection, queryResponse.Key)
+		if err != nil {
+			return deleted, err
+		}
+		deleted++
+	}
+	return deleted, nil
+}
+
+// queryDocuments returns documents matching rich query
+func queryDocuments(ctx contractapi.TransactionContextInterface, queryString string) ([]Document, error) {
+	resultIterator, err := ctx.GetStub().GetQueryResult(queryString)
+	if err != nil {
+		return nil, err
+	}
+	defer resultIterator.Close()
+
+	result := []Document{}
+	for resultIterator.HasNext() {
+		queryResponse, err := resultIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+		doc := new(Document)
+		_ = json.Unmarshal(queryResponse.Value, doc)
+		result = append(result, *doc)
+	}
+	return result, nil
+}
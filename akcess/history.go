@@ -0,0 +1,198 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// Kinds of change between two states of an object
+const (
+	ChangeCreated = "created"
+	ChangeDeleted = "deleted"
+	ChangeAdded   = "added"
+	ChangeUpdated = "updated"
+	ChangeRemoved = "removed"
+)
+
+// HistoryChange one change made by a transaction
+type HistoryChange struct {
+	Field  string `json:"field"`
+	Change string `json:"change"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// DocumentHistoryEntry state of document written by a transaction together with what it changed
+type DocumentHistoryEntry struct {
+	TxID            string          `json:"txId"`
+	Timestamp       time.Time       `json:"timestamp"`
+	IsDelete        bool            `json:"isDelete"`
+	Document        *Document       `json:"document,omitempty"` // nil when the transaction deleted the document
+	Changes         []HistoryChange `json:"changes"`
+	EffectiveStatus string          `json:"effectiveStatus,omitempty"` // status this state of document has at tx time of the query
+}
+
+// GetTxForDoc returns every past state of document with changes made by each transaction, oldest first.
+// Pass txid to get only the state written by that transaction
+func (d *DocContract) GetTxForDoc(ctx contractapi.TransactionContextInterface, documentid string, txid string) Response {
+	response := Response{
+		TxID:    ctx.GetStub().GetTxID(),
+		Success: false,
+		Message: "",
+		Data:    nil,
+	}
+
+	states, err := getObjectHistory(ctx, DocumentObject, documentid)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while fetching history of doc: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+	if len(states) == 0 {
+		response.Message = fmt.Sprintf("Document with id %s has no history", documentid)
+		logger.Info(response.Message)
+		return response
+	}
+
+	txTime, err := getTxTimestamp(ctx)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while getting tx timestamp: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+
+	history := []DocumentHistoryEntry{}
+	var previous *Document
+	for _, state := range states {
+		entry := DocumentHistoryEntry{
+			TxID:      state.TxID,
+			Timestamp: state.Timestamp,
+			IsDelete:  state.IsDelete,
+		}
+		if !state.IsDelete {
+			entry.Document = new(Document)
+			err = json.Unmarshal(state.Value, entry.Document)
+			if err != nil {
+				response.Message = fmt.Sprintf("Error while reading state of doc written by tx %s: %s", state.TxID, err.Error())
+				logger.Error(response.Message)
+				return response
+			}
+			entry.EffectiveStatus = entry.Document.EffectiveStatus(txTime)
+		}
+		entry.Changes = diffDocuments(previous, entry.Document)
+		previous = entry.Document
+
+		if txid == "" {
+			history = append(history, entry)
+			continue
+		}
+		if entry.TxID == txid {
+			response.Success = true
+			response.Message = fmt.Sprintf("Successfully fetched state of document %s written by tx %s", documentid, txid)
+			logger.Info(response.Message)
+			response.Data = entry
+			return response
+		}
+	}
+	if txid != "" {
+		response.Message = fmt.Sprintf("There is no tx %s for document %s", txid, documentid)
+		logger.Info(response.Message)
+		return response
+	}
+
+	response.Success = true
+	response.Message = fmt.Sprintf("Successfully fetched %d states of document %s", len(history), documentid)
+	logger.Info(response.Message)
+	response.Data = history
+	return response
+}
+
+// diffDocuments lists changes between two states of document, nil state means document doesn't exist
+func diffDocuments(previous *Document, current *Document) []HistoryChange {
+	changes := []HistoryChange{}
+	if current == nil {
+		if previous != nil {
+			changes = append(changes, HistoryChange{Field: "document", Change: ChangeDeleted})
+		}
+		return changes
+	}
+	if previous == nil {
+		changes = append(changes, HistoryChange{Field: "document", Change: ChangeCreated})
+		previous = &Document{}
+	}
+
+	if previous.AkcessID != "" && previous.AkcessID != current.AkcessID {
+		changes = append(changes, HistoryChange{Field: "akcessId", Change: ChangeUpdated, Detail: fmt.Sprintf("%s -> %s", previous.AkcessID, current.AkcessID)})
+	}
+	if previous.DocumentType != current.DocumentType && previous.DocumentID != "" {
+		changes = append(changes, HistoryChange{Field: "documentType", Change: ChangeUpdated, Detail: fmt.Sprintf("%s -> %s", previous.DocumentType, current.DocumentType)})
+	}
+	for i := len(previous.Versions); i < len(current.Versions); i++ {
+		changes = append(changes, HistoryChange{Field: "versions", Change: ChangeAdded, Detail: fmt.Sprintf("version %d", current.Versions[i].Version)})
+	}
+
+	signatureKey := func(s Signature) string {
+		return fmt.Sprintf("%s on version %d", s.AkcessID, s.DocumentVersion)
+	}
+	previousSignatures := map[string]Signature{}
+	for _, s := range previous.Signature {
+		previousSignatures[signatureKey(s)] = s
+	}
+	currentSignatures := map[string]bool{}
+	for _, s := range current.Signature {
+		key := signatureKey(s)
+		currentSignatures[key] = true
+		old, found := previousSignatures[key]
+		if !found {
+			changes = append(changes, HistoryChange{Field: "signature", Change: ChangeAdded, Detail: "by " + key})
+		} else if !reflect.DeepEqual(old, s) {
+			changes = append(changes, HistoryChange{Field: "signature", Change: ChangeUpdated, Detail: "by " + key})
+		}
+	}
+	for _, s := range previous.Signature {
+		if key := signatureKey(s); !currentSignatures[key] {
+			changes = append(changes, HistoryChange{Field: "signature", Change: ChangeRemoved, Detail: "by " + key})
+		}
+	}
+
+	previousVerifications := map[string]Verification{}
+	for _, v := range previous.Verifications {
+		previousVerifications[v.VerifierID] = v
+	}
+	currentVerifications := map[string]bool{}
+	for _, v := range current.Verifications {
+		currentVerifications[v.VerifierID] = true
+		old, found := previousVerifications[v.VerifierID]
+		if !found {
+			changes = append(changes, HistoryChange{Field: "verifications", Change: ChangeAdded, Detail: fmt.Sprintf("by %s on version %d", v.VerifierID, v.DocumentVersion)})
+		} else if !reflect.DeepEqual(old, v) {
+			changes = append(changes, HistoryChange{Field: "verifications", Change: ChangeUpdated, Detail: fmt.Sprintf("by %s on version %d", v.VerifierID, v.DocumentVersion)})
+		}
+	}
+	for _, v := range previous.Verifications {
+		if !currentVerifications[v.VerifierID] {
+			changes = append(changes, HistoryChange{Field: "verifications", Change: ChangeRemoved, Detail: "by " + v.VerifierID})
+		}
+	}
+
+	if previous.Workflow == nil && current.Workflow != nil {
+		changes = append(changes, HistoryChange{Field: "workflow", Change: ChangeAdded})
+	} else if previous.Workflow != nil && current.Workflow == nil {
+		changes = append(changes, HistoryChange{Field: "workflow", Change: ChangeRemoved})
+	} else if !reflect.DeepEqual(previous.Workflow, current.Workflow) {
+		changes = append(changes, HistoryChange{Field: "workflow", Change: ChangeUpdated})
+	}
+	if previous.SigningStatus != current.SigningStatus && previous.DocumentID != "" {
+		changes = append(changes, HistoryChange{Field: "signingStatus", Change: ChangeUpdated, Detail: fmt.Sprintf("%s -> %s", previous.SigningStatus, current.SigningStatus)})
+	}
+	if previous.Status != current.Status && previous.DocumentID != "" {
+		changes = append(changes, HistoryChange{Field: "status", Change: ChangeUpdated, Detail: fmt.Sprintf("%s -> %s", previous.Status, current.Status)})
+	}
+	if !reflect.DeepEqual(previous.ExpiresAt, current.ExpiresAt) {
+		changes = append(changes, HistoryChange{Field: "expiresAt", Change: ChangeUpdated})
+	}
+	return changes
+}
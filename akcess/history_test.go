@@ -0,0 +1,98 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+// changeSummaries lists changes as field and kind of change
+func changeSummaries(changes []HistoryChange) []string {
+	summaries := []string{}
+	for _, change := range changes {
+		summaries = append(summaries, change.Field+" "+change.Change)
+	}
+	return summaries
+}
+
+func TestGetTxForDoc(t *testing.T) {
+	l := newDocLedger(t)
+	versioned := new(DocContract).AddDocumentVersion(l.as("Org1MSP", "bob", nil), "doc1", saltedHash("", "v2"), "sha256", "")
+	expectResponse(t, versioned, "")
+	revoked := new(DocContract).RevokeDocument(l.as("Org1MSP", "bob", nil), "doc1", "lost")
+	expectResponse(t, revoked, "")
+
+	tests := []struct {
+		name            string
+		documentid      string
+		txid            string
+		expectedChanges [][]string
+		expectedError   string
+	}{
+		{
+			name:       "whole history",
+			documentid: "doc1",
+			expectedChanges: [][]string{
+				{"document created", "versions added"},
+				{"versions added"},
+				{"status updated"},
+			},
+		},
+		{name: "state written by tx", documentid: "doc1", txid: versioned.TxID, expectedChanges: [][]string{{"versions added"}}},
+		{name: "tx which didn't write document", documentid: "doc1", txid: "tx0", expectedError: "There is no tx tx0 for document doc1"},
+		{name: "unknown document", documentid: "doc2", expectedError: "Document with id doc2 has no history"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := new(DocContract).GetTxForDoc(l.as("Org1MSP", "vera", nil), tt.documentid, tt.txid)
+			expectResponse(t, response, tt.expectedError)
+			if tt.expectedError != "" {
+				return
+			}
+			entries := []DocumentHistoryEntry{}
+			if tt.txid != "" {
+				entries = append(entries, response.Data.(DocumentHistoryEntry))
+			} else {
+				entries = response.Data.([]DocumentHistoryEntry)
+			}
+			if len(entries) != len(tt.expectedChanges) {
+				t.Fatalf("expected %d states, got %+v", len(tt.expectedChanges), entries)
+			}
+			for i, entry := range entries {
+				if changes := changeSummaries(entry.Changes); !equalStrings(changes, tt.expectedChanges[i]) {
+					t.Fatalf("expected changes %v of state %d, got %v", tt.expectedChanges[i], i, changes)
+				}
+			}
+			if tt.txid != "" && entries[0].TxID != tt.txid {
+				t.Fatalf("expected state written by %s, got %s", tt.txid, entries[0].TxID)
+			}
+			if last := entries[len(entries)-1]; tt.txid == "" && (last.TxID != revoked.TxID || last.EffectiveStatus != DocumentRevoked) {
+				t.Fatalf("expected last state to be revoked by %s, got %+v", revoked.TxID, last)
+			}
+		})
+	}
+}
+
+func TestGetTxForLegacyDoc(t *testing.T) {
+	l := newTestLedger(t)
+	l.initLedger()
+	// legacy document written under plain key by its own transaction, put would give it wall clock timestamp
+	ctx := l.as("Org1MSP", "bob", nil)
+	legacy, _ := json.Marshal(Document{ObjectType: "document", DocumentID: "doc1", AkcessID: "bob", Versions: []DocumentVersion{{Version: 1, Hash: "abcd"}}})
+	expectErr(t, ctx.GetStub().PutState("doc1", legacy), "")
+	legacyTxID := ctx.GetStub().GetTxID()
+	expectResponse(t, new(UserContract).MigrateLegacyKeys(l.as("AdminMSP", "admin", nil), "", 10), "")
+
+	response := new(DocContract).GetTxForDoc(l.as("Org1MSP", "vera", nil), "doc1", "")
+	expectResponse(t, response, "")
+	entries := response.Data.([]DocumentHistoryEntry)
+	if len(entries) != 2 || entries[0].TxID != legacyTxID || entries[1].IsDelete {
+		t.Fatalf("expected legacy state followed by migrated state without delete of legacy key, got %+v", entries)
+	}
+	if changes := changeSummaries(entries[0].Changes); !equalStrings(changes, []string{"document created", "versions added"}) {
+		t.Fatalf("expected legacy state to create document, got %v", changes)
+	}
+	if len(entries[1].Changes) != 0 {
+		t.Fatalf("expected migration not to change document, got %+v", entries[1].Changes)
+	}
+}
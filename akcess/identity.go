@@ -0,0 +1,639 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// IdentityBinding binds certificate identity, MSP ID plus enrollment ID, to AKcessID
+type IdentityBinding struct {
+	ObjectType   string    `json:"docType"`
+	MSPID        string    `json:"mspId"`
+	EnrollmentID string    `json:"enrollmentId"`
+	AkcessID     string    `json:"akcessId"`
+	BoundBy      string    `json:"boundBy"`
+	BoundAt      time.Time `json:"boundAt"`
+}
+
+// BindIdentity admin binds identity to existing user or verifier, e.g. after re-enrollment with new enrollment ID
+// or for users registered before identity bindings were introduced. One AKcessID may have many identities
+func (u *UserContract) BindIdentity(ctx contractapi.TransactionContextInterface, mspID string, enrollmentID string, akcessID string) Response {
+	response := Response{
+		TxID:    ctx.GetStub().GetTxID(),
+		Success: false,
+		Message: "",
+		Data:    nil,
+	}
+
+	invoker := invokerName(ctx)
+	if !IsAdmin(ctx) {
+		response.Message = fmt.Sprintf("Identity %s is not an admin, only admin can bind identities", invoker)
+		logger.Info(response.Message)
+		return response
+	}
+	if mspID == "" || enrollmentID == "" || akcessID == "" {
+		response.Message = fmt.Sprint("MSP ID, enrollment ID and AKcessID can't be empty")
+		logger.Info(response.Message)
+		return response
+	}
+
+	userAsBytes, err := getObject(ctx, UserObject, akcessID)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while fetching user from world state: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+	verifierAsBytes, err := getObject(ctx, VerifierObject, akcessID)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while fetching verifier from world state: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+	if userAsBytes == nil && verifierAsBytes == nil {
+		response.Message = fmt.Sprintf("AKcessID %s doesn't exist", akcessID)
+		logger.Info(response.Message)
+		return response
+	}
+
+	binding, err := getIdentityBinding(ctx, mspID, enrollmentID)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while fetching identity binding: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+	if binding != nil && binding.AkcessID != akcessID {
+		response.Message = fmt.Sprintf("Identity %s::%s is already bound to AKcessID %s, unbind it first", mspID, enrollmentID, binding.AkcessID)
+		logger.Info(response.Message)
+		return response
+	}
+
+	binding, err = putIdentityBinding(ctx, mspID, enrollmentID, akcessID, invoker)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while saving identity binding: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+
+	response.Success = true
+	response.Message = fmt.Sprintf("Identity %s::%s bound to AKcessID %s", mspID, enrollmentID, akcessID)
+	logger.Info(response.Message)
+	response.Data = binding
+	return response
+}
+
+// UnbindIdentity admin removes binding of identity, identity can't act as its AKcessID anymore
+func (u *UserContract) UnbindIdentity(ctx contractapi.TransactionContextInterface, mspID string, enrollmentID string) Response {
+	response := Response{
+		TxID:    ctx.GetStub().GetTxID(),
+		Success: false,
+		Message: "",
+		Data:    nil,
+	}
+
+	invoker := invokerName(ctx)
+	if !IsAdmin(ctx) {
+		response.Message = fmt.Sprintf("Identity %s is not an admin, only admin can unbind identities", invoker)
+		logger.Info(response.Message)
+		return response
+	}
+
+	binding, err := getIdentityBinding(ctx, mspID, enrollmentID)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while fetching identity binding: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+	if binding == nil {
+		response.Message = fmt.Sprintf("Identity %s::%s is not bound to any AKcessID", mspID, enrollmentID)
+		logger.Info(response.Message)
+		return response
+	}
+
+	err = deleteIdentityBinding(ctx, *binding)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while removing identity binding: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+
+	response.Success = true
+	response.Message = fmt.Sprintf("Identity %s::%s unbound from AKcessID %s", mspID, enrollmentID, binding.AkcessID)
+	logger.Info(response.Message)
+	return response
+}
+
+// GetIdentityBindings returns all identities bound to AKcessID
+func (u *UserContract) GetIdentityBindings(ctx contractapi.TransactionContextInterface, akcessID string) Response {
+	response := Response{
+		TxID:    ctx.GetStub().GetTxID(),
+		Success: false,
+		Message: "",
+		Data:    nil,
+	}
+
+	result, err := getIdentityBindings(ctx, akcessID)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while fetching identity bindings: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+
+	response.Success = true
+	response.Message = fmt.Sprintf("Successfully fetched identities of AKcessID %s", akcessID)
+	logger.Info(response.Message)
+	response.Data = result
+	return response
+}
+
+// LegacyBindingResult result of binding legacy AKcessIDs
+type LegacyBindingResult struct {
+	Bound   []IdentityBinding `json:"bound"`
+	Skipped []string          `json:"skipped"` // AKcessIDs which are not registered or are already bound
+}
+
+// SetLegacyMSPID admin sets MSP users and verifiers registered before identity bindings were enrolled with.
+// Legacy identities can't be claimed or migrated until it is set
+func (u *UserContract) SetLegacyMSPID(ctx contractapi.TransactionContextInterface, mspID string) Response {
+	response := Response{
+		TxID:    ctx.GetStub().GetTxID(),
+		Success: false,
+		Message: "",
+		Data:    nil,
+	}
+
+	invoker := invokerName(ctx)
+	if !IsAdmin(ctx) {
+		response.Message = fmt.Sprintf("Identity %s is not an admin, only admin can set legacy MSP", invoker)
+		logger.Info(response.Message)
+		return response
+	}
+	if mspID == "" {
+		response.Message = fmt.Sprint("MSP ID can't be empty")
+		logger.Info(response.Message)
+		return response
+	}
+	config, err := getAccessConfig(ctx)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while fetching access config: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+	if config == nil {
+		response.Message = fmt.Sprint("Ledger is not initialized, run InitLedger first")
+		logger.Info(response.Message)
+		return response
+	}
+
+	config.LegacyMSPID = mspID
+	config.UpdatedBy = invoker
+	err = saveAccessConfig(ctx, config)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while saving access config: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+
+	response.Success = true
+	response.Message = fmt.Sprintf("Legacy MSP set to %s", mspID)
+	logger.Info(response.Message)
+	response.Data = config
+	return response
+}
+
+// ClaimLegacyIdentity binds invoker to user or verifier registered under its enrollment ID before identity bindings
+// were introduced. Invoker must be the identity which registered the user, or member of legacy MSP when user
+// was registered before its identity was recorded
+func (u *UserContract) ClaimLegacyIdentity(ctx contractapi.TransactionContextInterface) Response {
+	response := Response{
+		TxID:    ctx.GetStub().GetTxID(),
+		Success: false,
+		Message: "",
+		Data:    nil,
+	}
+
+	mspID, enrollmentID, err := getIdentity(ctx)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while reading invoker identity: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+	binding, err := getIdentityBinding(ctx, mspID, enrollmentID)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while fetching identity binding: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+	if binding != nil {
+		response.Message = fmt.Sprintf("Identity %s::%s is already bound to AKcessID %s", mspID, enrollmentID, binding.AkcessID)
+		logger.Info(response.Message)
+		return response
+	}
+	// legacy AKcessIDs are common names of identities which registered them
+	akcessID := enrollmentID
+	identityHash, err := getLegacyIdentityHash(ctx, akcessID)
+	if err != nil {
+		response.Message = err.Error()
+		logger.Info(response.Message)
+		return response
+	}
+
+	if identityHash != "" {
+		invokerHash, err := getIdentityHash(ctx)
+		if err != nil {
+			response.Message = fmt.Sprintf("Error while reading invoker identity: %s", err.Error())
+			logger.Error(response.Message)
+			return response
+		}
+		if invokerHash != identityHash {
+			response.Message = fmt.Sprintf("Identity %s::%s didn't register AKcessID %s", mspID, enrollmentID, akcessID)
+			logger.Info(response.Message)
+			return response
+		}
+	} else {
+		legacyMSPID, err := getLegacyMSPID(ctx)
+		if err != nil {
+			response.Message = err.Error()
+			logger.Info(response.Message)
+			return response
+		}
+		if mspID != legacyMSPID {
+			response.Message = fmt.Sprintf("AKcessID %s was registered in MSP %s, identity of MSP %s can't claim it", akcessID, legacyMSPID, mspID)
+			logger.Info(response.Message)
+			return response
+		}
+	}
+
+	binding, err = putIdentityBinding(ctx, mspID, enrollmentID, akcessID, akcessID)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while saving identity binding: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+
+	response.Success = true
+	response.Message = fmt.Sprintf("Identity %s::%s bound to legacy AKcessID %s", mspID, enrollmentID, akcessID)
+	logger.Info(response.Message)
+	response.Data = binding
+	return response
+}
+
+// MigrateLegacyIdentities admin binds users and verifiers registered before identity bindings were introduced
+// to identity with the same enrollment ID in legacy MSP. AKcessIDs which are not registered or already bound are skipped
+func (u *UserContract) MigrateLegacyIdentities(ctx contractapi.TransactionContextInterface, akcessIDs []string) Response {
+	response := Response{
+		TxID:    ctx.GetStub().GetTxID(),
+		Success: false,
+		Message: "",
+		Data:    nil,
+	}
+
+	invoker := invokerName(ctx)
+	if !IsAdmin(ctx) {
+		response.Message = fmt.Sprintf("Identity %s is not an admin, only admin can migrate identities", invoker)
+		logger.Info(response.Message)
+		return response
+	}
+	legacyMSPID, err := getLegacyMSPID(ctx)
+	if err != nil {
+		response.Message = err.Error()
+		logger.Info(response.Message)
+		return response
+	}
+
+	result := LegacyBindingResult{
+		Bound:   []IdentityBinding{},
+		Skipped: []string{},
+	}
+	for _, akcessID := range akcessIDs {
+		_, err := getLegacyIdentityHash(ctx, akcessID)
+		if err != nil {
+			result.Skipped = append(result.Skipped, akcessID)
+			continue
+		}
+		binding, err := getIdentityBinding(ctx, legacyMSPID, akcessID)
+		if err != nil {
+			response.Message = fmt.Sprintf("Error while fetching identity binding: %s", err.Error())
+			logger.Error(response.Message)
+			return response
+		}
+		if binding != nil {
+			result.Skipped = append(result.Skipped, akcessID)
+			continue
+		}
+		binding, err = putIdentityBinding(ctx, legacyMSPID, akcessID, akcessID, invoker)
+		if err != nil {
+			response.Message = fmt.Sprintf("Error while saving identity binding: %s", err.Error())
+			logger.Error(response.Message)
+			return response
+		}
+		result.Bound = append(result.Bound, *binding)
+	}
+
+	response.Success = true
+	response.Message = fmt.Sprintf("Bound %d legacy AKcessIDs to MSP %s", len(result.Bound), legacyMSPID)
+	logger.Info(response.Message)
+	response.Data = result
+	return response
+}
+
+// ResolveIdentity returns binding of identity invoking transaction, used by other chaincodes
+// to resolve AKcessID of their invoker
+func (u *UserContract) ResolveIdentity(ctx contractapi.TransactionContextInterface) (*IdentityBinding, error) {
+	mspID, enrollmentID, err := getIdentity(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = resolveAkcessID(ctx); err != nil {
+		return nil, err
+	}
+	return getIdentityBinding(ctx, mspID, enrollmentID)
+}
+
+// getEnrollmentID returns enrollment ID of identity invoking transaction. It is taken from hf.EnrollmentID
+// attribute set by Fabric CA, or from certificate common name which Fabric CA sets to enrollment ID
+func getEnrollmentID(ctx contractapi.TransactionContextInterface) (string, error) {
+	enrollmentID, found, err := ctx.GetClientIdentity().GetAttributeValue("hf.EnrollmentID")
+	if err == nil && found && enrollmentID != "" {
+		return enrollmentID, nil
+	}
+	x509, err := ctx.GetClientIdentity().GetX509Certificate()
+	if err != nil {
+		return "", err
+	}
+	if x509.Subject.CommonName == "" {
+		return "", fmt.Errorf("certificate of invoker has no enrollment ID")
+	}
+	return x509.Subject.CommonName, nil
+}
+
+// getIdentity returns MSP ID and enrollment ID of identity invoking transaction
+func getIdentity(ctx contractapi.TransactionContextInterface) (string, string, error) {
+	mspID, err := ctx.GetClientIdentity().GetMSPID()
+	if err != nil {
+		return "", "", err
+	}
+	enrollmentID, err := getEnrollmentID(ctx)
+	if err != nil {
+		return "", "", err
+	}
+	return mspID, enrollmentID, nil
+}
+
+// getAkcessIDAttribute returns akcessId attribute of invoker certificate, empty if it is not set
+func getAkcessIDAttribute(ctx contractapi.TransactionContextInterface) string {
+	akcessID, found, err := ctx.GetClientIdentity().GetAttributeValue("akcessId")
+	if err != nil || !found {
+		return ""
+	}
+	return akcessID
+}
+
+// resolveAkcessID returns AKcessID identity invoking transaction is bound to. Unbound identities and identities
+// whose akcessId certificate attribute names other AKcessID than their binding are rejected
+func resolveAkcessID(ctx contractapi.TransactionContextInterface) (string, error) {
+	mspID, enrollmentID, err := getIdentity(ctx)
+	if err != nil {
+		return "", fmt.Errorf("Error while reading invoker identity: %s", err.Error())
+	}
+	binding, err := getIdentityBinding(ctx, mspID, enrollmentID)
+	if err != nil {
+		return "", fmt.Errorf("Error while fetching identity binding: %s", err.Error())
+	}
+	if binding == nil {
+		return "", fmt.Errorf("Identity %s::%s is not bound to any AKcessID, register it or ask admin to bind it", mspID, enrollmentID)
+	}
+	if attribute := getAkcessIDAttribute(ctx); attribute != "" && attribute != binding.AkcessID {
+		return "", fmt.Errorf("Identity %s::%s is ambiguous, it is bound to AKcessID %s but its certificate names AKcessID %s", mspID, enrollmentID, binding.AkcessID, attribute)
+	}
+	return binding.AkcessID, nil
+}
+
+// registrationAkcessID returns AKcessID invoker registers under: AKcessID it is already bound to, otherwise
+// akcessId certificate attribute or its enrollment ID. bound is false when identity still has to be bound
+func registrationAkcessID(ctx contractapi.TransactionContextInterface) (akcessID string, bound bool, err error) {
+	mspID, enrollmentID, err := getIdentity(ctx)
+	if err != nil {
+		return "", false, fmt.Errorf("Error while reading invoker identity: %s", err.Error())
+	}
+	binding, err := getIdentityBinding(ctx, mspID, enrollmentID)
+	if err != nil {
+		return "", false, fmt.Errorf("Error while fetching identity binding: %s", err.Error())
+	}
+	if binding != nil {
+		akcessID, err = resolveAkcessID(ctx)
+		return akcessID, true, err
+	}
+
+	akcessID = getAkcessIDAttribute(ctx)
+	if akcessID == "" {
+		akcessID = enrollmentID
+	}
+	// identities of other organizations may use the same enrollment ID, they can't take over AKcessID
+	bindings, err := getIdentityBindings(ctx, akcessID)
+	if err != nil {
+		return "", false, fmt.Errorf("Error while fetching identity bindings: %s", err.Error())
+	}
+	if len(bindings) > 0 {
+		return "", false, fmt.Errorf("AKcessID %s is bound to another identity, identity %s::%s can't register it", akcessID, mspID, enrollmentID)
+	}
+	// users and verifiers registered before identity bindings have no binding, they must be claimed first
+	err = checkAkcessIDUnused(ctx, akcessID)
+	if err != nil {
+		return "", false, err
+	}
+	return akcessID, false, nil
+}
+
+// checkAkcessIDUnused refuses AKcessID which already holds user or verifier, under composite or legacy key,
+// or tombstone of user erased by another identity
+func checkAkcessIDUnused(ctx contractapi.TransactionContextInterface, akcessID string) error {
+	for _, objectType := range []string{UserObject, VerifierObject} {
+		objectAsBytes, err := getObject(ctx, objectType, akcessID)
+		if err != nil {
+			return fmt.Errorf("Error while fetching %s from world state: %s", objectType, err.Error())
+		}
+		if objectAsBytes != nil {
+			return fmt.Errorf("AKcessID %s is already registered, its owner has to claim it with ClaimLegacyIdentity", akcessID)
+		}
+	}
+
+	tombstoneAsBytes, err := getObject(ctx, TombstoneObject, akcessID)
+	if err != nil {
+		return fmt.Errorf("Error while fetching tombstone from world state: %s", err.Error())
+	}
+	if tombstoneAsBytes == nil {
+		return nil
+	}
+	identityHash, err := getIdentityHash(ctx)
+	if err != nil {
+		return fmt.Errorf("Error while reading invoker identity: %s", err.Error())
+	}
+	var tombstone UserTombstone
+	json.Unmarshal(tombstoneAsBytes, &tombstone)
+	if tombstone.IdentityHash == "" || tombstone.IdentityHash != identityHash {
+		return fmt.Errorf("AKcessID %s was erased and can't be registered by another identity", akcessID)
+	}
+	return nil
+}
+
+// getLegacyIdentityHash checks AKcessID holds user or verifier which has no identity bound yet and returns
+// identity hash recorded when user was registered, empty if it wasn't recorded
+func getLegacyIdentityHash(ctx contractapi.TransactionContextInterface, akcessID string) (string, error) {
+	bindings, err := getIdentityBindings(ctx, akcessID)
+	if err != nil {
+		return "", fmt.Errorf("Error while fetching identity bindings: %s", err.Error())
+	}
+	if len(bindings) > 0 {
+		return "", fmt.Errorf("AKcessID %s is already bound to an identity", akcessID)
+	}
+	userAsBytes, err := getObject(ctx, UserObject, akcessID)
+	if err != nil {
+		return "", fmt.Errorf("Error while fetching user from world state: %s", err.Error())
+	}
+	if userAsBytes != nil {
+		var user User
+		json.Unmarshal(userAsBytes, &user)
+		return user.IdentityHash, nil
+	}
+	verifierAsBytes, err := getObject(ctx, VerifierObject, akcessID)
+	if err != nil {
+		return "", fmt.Errorf("Error while fetching verifier from world state: %s", err.Error())
+	}
+	if verifierAsBytes == nil {
+		return "", fmt.Errorf("AKcessID %s is not registered", akcessID)
+	}
+	return "", nil
+}
+
+// getLegacyMSPID returns legacy MSP admin set in access config, error if admin didn't set any
+func getLegacyMSPID(ctx contractapi.TransactionContextInterface) (string, error) {
+	config, err := getAccessConfig(ctx)
+	if err != nil {
+		return "", fmt.Errorf("Error while fetching access config: %s", err.Error())
+	}
+	if config == nil || config.LegacyMSPID == "" {
+		return "", fmt.Errorf("Legacy MSP is not set, admin has to set it with SetLegacyMSPID first")
+	}
+	return config.LegacyMSPID, nil
+}
+
+// bindInvoker binds identity invoking transaction to AKcessID it registered
+func bindInvoker(ctx contractapi.TransactionContextInterface, akcessID string) error {
+	mspID, enrollmentID, err := getIdentity(ctx)
+	if err != nil {
+		return err
+	}
+	_, err = putIdentityBinding(ctx, mspID, enrollmentID, akcessID, akcessID)
+	return err
+}
+
+// invokerName returns AKcessID of invoker, or its MSP qualified enrollment ID when identity isn't bound.
+// Used to record who changed configuration, as admins don't need an AKcessID
+func invokerName(ctx contractapi.TransactionContextInterface) string {
+	if akcessID, err := resolveAkcessID(ctx); err == nil {
+		return akcessID
+	}
+	mspID, enrollmentID, err := getIdentity(ctx)
+	if err != nil {
+		return ""
+	}
+	return mspID + "::" + enrollmentID
+}
+
+// getIdentityBinding returns binding of identity, nil if identity is not bound
+func getIdentityBinding(ctx contractapi.TransactionContextInterface, mspID string, enrollmentID string) (*IdentityBinding, error) {
+	key, err := ctx.GetStub().CreateCompositeKey(IdentityObject, []string{mspID, enrollmentID})
+	if err != nil {
+		return nil, err
+	}
+	bindingAsBytes, err := ctx.GetStub().GetState(key)
+	if err != nil || bindingAsBytes == nil {
+		return nil, err
+	}
+	var binding IdentityBinding
+	err = json.Unmarshal(bindingAsBytes, &binding)
+	if err != nil {
+		return nil, err
+	}
+	return &binding, nil
+}
+
+// getIdentityBindings returns all bindings of AKcessID using AKcessID index
+func getIdentityBindings(ctx contractapi.TransactionContextInterface, akcessID string) ([]IdentityBinding, error) {
+	resultIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(IdentityIndex, []string{akcessID})
+	if err != nil {
+		return nil, err
+	}
+	defer resultIterator.Close()
+
+	result := []IdentityBinding{}
+	for resultIterator.HasNext() {
+		queryResponse, err := resultIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+		_, attributes, err := ctx.GetStub().SplitCompositeKey(queryResponse.Key)
+		if err != nil || len(attributes) != 3 {
+			continue
+		}
+		binding, err := getIdentityBinding(ctx, attributes[1], attributes[2])
+		if err != nil {
+			return nil, err
+		}
+		if binding != nil {
+			result = append(result, *binding)
+		}
+	}
+	return result, nil
+}
+
+// putIdentityBinding writes binding of identity together with its AKcessID index entry
+func putIdentityBinding(ctx contractapi.TransactionContextInterface, mspID string, enrollmentID string, akcessID string, boundBy string) (*IdentityBinding, error) {
+	txTime, err := getTxTimestamp(ctx)
+	if err != nil {
+		return nil, err
+	}
+	binding := IdentityBinding{
+		ObjectType:   "identitybinding",
+		MSPID:        mspID,
+		EnrollmentID: enrollmentID,
+		AkcessID:     akcessID,
+		BoundBy:      boundBy,
+		BoundAt:      txTime,
+	}
+
+	key, err := ctx.GetStub().CreateCompositeKey(IdentityObject, []string{mspID, enrollmentID})
+	if err != nil {
+		return nil, err
+	}
+	bindingAsBytes, _ := json.Marshal(binding)
+	err = ctx.GetStub().PutState(key, bindingAsBytes)
+	if err != nil {
+		return nil, err
+	}
+	indexKey, err := ctx.GetStub().CreateCompositeKey(IdentityIndex, []string{akcessID, mspID, enrollmentID})
+	if err != nil {
+		return nil, err
+	}
+	return &binding, ctx.GetStub().PutState(indexKey, []byte{0x00})
+}
+
+// deleteIdentityBinding deletes binding of identity together with its AKcessID index entry
+func deleteIdentityBinding(ctx contractapi.TransactionContextInterface, binding IdentityBinding) error {
+	key, err := ctx.GetStub().CreateCompositeKey(IdentityObject, []string{binding.MSPID, binding.EnrollmentID})
+	if err != nil {
+		return err
+	}
+	err = ctx.GetStub().DelState(key)
+	if err != nil {
+		return err
+	}
+	indexKey, err := ctx.GetStub().CreateCompositeKey(IdentityIndex, []string{binding.AkcessID, binding.MSPID, binding.EnrollmentID})
+	if err != nil {
+		return err
+	}
+	return ctx.GetStub().DelState(indexKey)
+}
@@ -0,0 +1,195 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestRegistrationCollision(t *testing.T) {
+	tests := []struct {
+		name          string
+		setup         func(l *testLedger)
+		register      func(l *testLedger) Response
+		expectedError string
+	}{
+		{
+			name: "legacy user under plain key blocks verifier of other MSP",
+			setup: func(l *testLedger) {
+				l.put("alice", User{ObjectType: "user", AkcessID: "alice"})
+			},
+			register: func(l *testLedger) Response {
+				return new(UserContract).CreateVerifier(l.as("Org2MSP", "alice", verifierAttrs), "Alice", "gold")
+			},
+			expectedError: "AKcessID alice is already registered",
+		},
+		{
+			name: "legacy verifier under composite key blocks user of other MSP",
+			setup: func(l *testLedger) {
+				l.put(l.compositeKey(VerifierObject, "alice"), Verifier{ObjectType: "verifier", AkcessID: "alice"})
+			},
+			register: func(l *testLedger) Response {
+				return new(UserContract).CreateUser(l.as("Org2MSP", "alice", nil))
+			},
+			expectedError: "AKcessID alice is already registered",
+		},
+		{
+			name: "bound user blocks user of other MSP",
+			setup: func(l *testLedger) {
+				expectResponse(l.t, new(UserContract).CreateUser(l.as("AKcessMSP", "alice", nil)), "")
+			},
+			register: func(l *testLedger) Response {
+				return new(UserContract).CreateUser(l.as("Org2MSP", "alice", nil))
+			},
+			expectedError: "AKcessID alice is bound to another identity",
+		},
+		{
+			name: "tombstone of other identity blocks user",
+			setup: func(l *testLedger) {
+				l.put(l.compositeKey(TombstoneObject, "alice"), UserTombstone{AkcessID: "alice", IdentityHash: "other"})
+			},
+			register: func(l *testLedger) Response {
+				return new(UserContract).CreateUser(l.as("Org2MSP", "alice", nil))
+			},
+			expectedError: "AKcessID alice was erased",
+		},
+		{
+			name:  "unused AKcessID is registered",
+			setup: func(l *testLedger) {},
+			register: func(l *testLedger) Response {
+				return new(UserContract).CreateUser(l.as("Org2MSP", "alice", nil))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := newTestLedger(t)
+			tt.setup(l)
+			expectResponse(t, tt.register(l), tt.expectedError)
+		})
+	}
+}
+
+func TestClaimLegacyIdentity(t *testing.T) {
+	tests := []struct {
+		name          string
+		setup         func(l *testLedger)
+		mspID         string
+		expectedError string
+	}{
+		{
+			name: "user without identity hash claimed from legacy MSP",
+			setup: func(l *testLedger) {
+				l.put("alice", User{ObjectType: "user", AkcessID: "alice"})
+			},
+			mspID: "AKcessMSP",
+		},
+		{
+			name: "user without identity hash can't be claimed from other MSP",
+			setup: func(l *testLedger) {
+				l.put("alice", User{ObjectType: "user", AkcessID: "alice"})
+			},
+			mspID:         "Org2MSP",
+			expectedError: "was registered in MSP AKcessMSP",
+		},
+		{
+			name: "user claimed by identity which registered it",
+			setup: func(l *testLedger) {
+				registerUnbound(l, "Org2MSP", "alice")
+			},
+			mspID: "Org2MSP",
+		},
+		{
+			name: "user can't be claimed by other identity with the same enrollment ID",
+			setup: func(l *testLedger) {
+				registerUnbound(l, "Org2MSP", "alice")
+			},
+			mspID:         "AKcessMSP",
+			expectedError: "didn't register AKcessID alice",
+		},
+		{
+			name: "bound user can't be claimed",
+			setup: func(l *testLedger) {
+				expectResponse(l.t, new(UserContract).CreateUser(l.as("Org2MSP", "alice", nil)), "")
+			},
+			mspID:         "AKcessMSP",
+			expectedError: "AKcessID alice is already bound",
+		},
+		{
+			name: "user without identity hash can't be claimed before legacy MSP is set",
+			setup: func(l *testLedger) {
+				l.put("alice", User{ObjectType: "user", AkcessID: "alice"})
+				l.put(l.compositeKey(ConfigObject, "access"), AccessConfig{ObjectType: "accessconfig", AdminMSPIDs: []string{"AdminMSP"}})
+			},
+			mspID:         "AKcessMSP",
+			expectedError: "Legacy MSP is not set, admin has to set it with SetLegacyMSPID first",
+		},
+		{
+			name:          "unregistered AKcessID can't be claimed",
+			setup:         func(l *testLedger) {},
+			mspID:         "AKcessMSP",
+			expectedError: "AKcessID alice is not registered",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := newTestLedger(t)
+			l.initLedger()
+			expectResponse(t, new(UserContract).SetLegacyMSPID(l.as("AdminMSP", "admin", nil), "AKcessMSP"), "")
+			tt.setup(l)
+			expectResponse(t, new(UserContract).ClaimLegacyIdentity(l.as(tt.mspID, "alice", nil)), tt.expectedError)
+			if tt.expectedError != "" {
+				return
+			}
+			akcessID, err := resolveAkcessID(l.as(tt.mspID, "alice", nil))
+			expectErr(t, err, "")
+			if akcessID != "alice" {
+				t.Fatalf("expected identity bound to alice, got %s", akcessID)
+			}
+		})
+	}
+}
+
+func TestMigrateLegacyIdentities(t *testing.T) {
+	l := newTestLedger(t)
+	l.initLedger()
+	l.put("alice", User{ObjectType: "user", AkcessID: "alice"})
+	l.put(l.compositeKey(VerifierObject, "vera"), Verifier{ObjectType: "verifier", AkcessID: "vera"})
+	expectResponse(t, new(UserContract).CreateUser(l.as("Org2MSP", "bob", nil)), "")
+
+	expectResponse(t, new(UserContract).MigrateLegacyIdentities(l.as("Org2MSP", "bob", nil), []string{"alice"}), "only admin can migrate identities")
+	expectResponse(t, new(UserContract).MigrateLegacyIdentities(l.as("AdminMSP", "admin", nil), []string{"alice"}), "Legacy MSP is not set")
+	expectResponse(t, new(UserContract).SetLegacyMSPID(l.as("Org2MSP", "bob", nil), "AKcessMSP"), "only admin can set legacy MSP")
+	expectResponse(t, new(UserContract).SetLegacyMSPID(l.as("AdminMSP", "admin", nil), "AKcessMSP"), "")
+
+	response := new(UserContract).MigrateLegacyIdentities(l.as("AdminMSP", "admin", nil), []string{"alice", "vera", "bob", "carol"})
+	expectResponse(t, response, "")
+	result := response.Data.(LegacyBindingResult)
+	if len(result.Bound) != 2 || result.Bound[0].AkcessID != "alice" || result.Bound[1].AkcessID != "vera" {
+		t.Fatalf("expected alice and vera bound, got %+v", result.Bound)
+	}
+	if len(result.Skipped) != 2 || result.Skipped[0] != "bob" || result.Skipped[1] != "carol" {
+		t.Fatalf("expected bob and carol skipped, got %v", result.Skipped)
+	}
+
+	for _, akcessID := range []string{"alice", "vera"} {
+		resolved, err := resolveAkcessID(l.as("AKcessMSP", akcessID, nil))
+		expectErr(t, err, "")
+		if resolved != akcessID {
+			t.Fatalf("expected identity bound to %s, got %s", akcessID, resolved)
+		}
+	}
+	expectResponse(t, new(UserContract).CreateUser(l.as("Org3MSP", "alice", nil)), "AKcessID alice is bound to another identity")
+}
+
+// registerUnbound registers user and removes its binding, as users were registered before identity bindings
+func registerUnbound(l *testLedger, mspID string, akcessID string) {
+	l.t.Helper()
+	ctx := l.as(mspID, akcessID, nil)
+	expectResponse(l.t, new(UserContract).CreateUser(ctx), "")
+	binding, err := getIdentityBinding(ctx, mspID, akcessID)
+	if err != nil || binding == nil {
+		l.t.Fatalf("expected binding of %s::%s", mspID, akcessID)
+	}
+	expectErr(l.t, deleteIdentityBinding(ctx, *binding), "")
+}
@@ -0,0 +1,295 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// Object types, each one is stored in its own composite key namespace
+const (
+	UserObject          = "user"
+	VerifierObject      = "verifier"
+	DocumentObject      = "document"
+	DocShareObject      = "docshare"
+	AssetObject         = "asset"
+	ConfigObject        = "config"
+	PolicyObject        = "verificationpolicy"
+	TombstoneObject     = "tombstone"
+	IdentityObject      = "identity"
+	IdentityIndex       = "akcessid~identity" // index of identities bound to AKcessID
+	DocRequestObject    = "docverificationrequest"
+	DocHashIndex        = "dochash~document" // index of document versions by content hash
+	TransferObject      = "assettransfer"
+	AssetApprovalObject = "assetapproval" // co-owner approvals keyed by asset, action, target and approver
+	LienObject          = "lien"
+	AssetLienIndex      = "asset~lien"      // index of unreleased liens on asset
+	LienholderIndex     = "lienholder~lien" // index of liens by lienholder
+	AssetTypeObject     = "assettype"
+)
+
+// legacyObjectTypes object types which were stored under plain keys before composite keys were introduced
+var legacyObjectTypes = []string{UserObject, VerifierObject, DocumentObject, DocShareObject, AssetObject}
+
+// MigrationResult result of one batch of legacy key migration
+type MigrationResult struct {
+	Migrated []string `json:"migrated"`
+	Skipped  []string `json:"skipped"` // plain keys which don't hold any known object
+	NextKey  string   `json:"nextKey"` // pass as startKey of next batch, empty when migration is done
+}
+
+// objectKey returns composite key object of given type and id is stored under
+func objectKey(ctx contractapi.TransactionContextInterface, objectType string, id string) (string, error) {
+	return ctx.GetStub().CreateCompositeKey(objectType, []string{id})
+}
+
+// legacyObjectType returns type of object stored under plain key, assets were stored without docType
+func legacyObjectType(value []byte) string {
+	var object struct {
+		ObjectType    string `json:"docType"`
+		UniqueAssetID string `json:"uniqueAssetID"`
+	}
+	if err := json.Unmarshal(value, &object); err != nil {
+		return ""
+	}
+	if object.ObjectType == "" && object.UniqueAssetID != "" {
+		return AssetObject
+	}
+	return object.ObjectType
+}
+
+// getObject reads object of given type and id. Until migration is done objects may still be under
+// plain legacy key, those are returned only if they hold object of the requested type
+func getObject(ctx contractapi.TransactionContextInterface, objectType string, id string) ([]byte, error) {
+	key, err := objectKey(ctx, objectType, id)
+	if err != nil {
+		return nil, err
+	}
+	value, err := ctx.GetStub().GetState(key)
+	if err != nil || value != nil {
+		return value, err
+	}
+
+	legacyValue, err := ctx.GetStub().GetState(id)
+	if err != nil || legacyValue == nil {
+		return nil, err
+	}
+	if legacyObjectType(legacyValue) != objectType {
+		return nil, nil
+	}
+	return legacyValue, nil
+}
+
+// putObject writes object under its composite key and removes legacy plain key holding the same object
+func putObject(ctx contractapi.TransactionContextInterface, objectType string, id string, value []byte) error {
+	key, err := objectKey(ctx, objectType, id)
+	if err != nil {
+		return err
+	}
+	err = ctx.GetStub().PutState(key, value)
+	if err != nil {
+		return err
+	}
+	return deleteLegacyObject(ctx, objectType, id)
+}
+
+// deleteObject deletes object of given type and id from both composite and legacy key
+func deleteObject(ctx contractapi.TransactionContextInterface, objectType string, id string) error {
+	key, err := objectKey(ctx, objectType, id)
+	if err != nil {
+		return err
+	}
+	err = ctx.GetStub().DelState(key)
+	if err != nil {
+		return err
+	}
+	return deleteLegacyObject(ctx, objectType, id)
+}
+
+// objectState one past state of object read from key history
+type objectState struct {
+	TxID      string
+	Timestamp time.Time
+	IsDelete  bool
+	Value     []byte
+}
+
+// getObjectHistory returns past states of object of given type and id, oldest first. States written under
+// plain legacy key before migration are included, legacy key deletes done by migration itself are skipped
+func getObjectHistory(ctx contractapi.TransactionContextInterface, objectType string, id string) ([]objectState, error) {
+	key, err := objectKey(ctx, objectType, id)
+	if err != nil {
+		return nil, err
+	}
+	states, err := getKeyHistory(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	compositeTxs := map[string]bool{}
+	for _, state := range states {
+		compositeTxs[state.TxID] = true
+	}
+
+	legacyStates, err := getKeyHistory(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(legacyStates, func(i, j int) bool {
+		return legacyStates[i].Timestamp.Before(legacyStates[j].Timestamp)
+	})
+	holdsObject := false
+	for _, state := range legacyStates {
+		if state.IsDelete {
+			if holdsObject && !compositeTxs[state.TxID] {
+				states = append(states, state)
+			}
+			holdsObject = false
+			continue
+		}
+		holdsObject = legacyObjectType(state.Value) == objectType
+		if holdsObject {
+			states = append(states, state)
+		}
+	}
+
+	sort.SliceStable(states, func(i, j int) bool {
+		return states[i].Timestamp.Before(states[j].Timestamp)
+	})
+	return states, nil
+}
+
+// getKeyHistory reads history of single key
+func getKeyHistory(ctx contractapi.TransactionContextInterface, key string) ([]objectState, error) {
+	resultIterator, err := ctx.GetStub().GetHistoryForKey(key)
+	if err != nil {
+		return nil, err
+	}
+	defer resultIterator.Close()
+
+	states := []objectState{}
+	for resultIterator.HasNext() {
+		modification, err := resultIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+		state := objectState{
+			TxID:     modification.TxId,
+			IsDelete: modification.IsDelete,
+			Value:    modification.Value,
+		}
+		if modification.Timestamp != nil {
+			state.Timestamp = time.Unix(modification.Timestamp.Seconds, int64(modification.Timestamp.Nanos)).UTC()
+		}
+		states = append(states, state)
+	}
+	return states, nil
+}
+
+// deleteLegacyObject deletes plain legacy key if it holds object of given type
+func deleteLegacyObject(ctx contractapi.TransactionContextInterface, objectType string, id string) error {
+	legacyValue, err := ctx.GetStub().GetState(id)
+	if err != nil {
+		return err
+	}
+	if legacyValue == nil || legacyObjectType(legacyValue) != objectType {
+		return nil
+	}
+	return ctx.GetStub().DelState(id)
+}
+
+// MigrateLegacyKeys admin moves objects stored under plain keys to their composite keys.
+// Migration runs in batches of at most limit keys starting from startKey, until returned nextKey is empty.
+// Objects keep being readable through both keys while migration is running
+func (u *UserContract) MigrateLegacyKeys(ctx contractapi.TransactionContextInterface, startKey string, limit int) Response {
+	response := Response{
+		TxID:    ctx.GetStub().GetTxID(),
+		Success: false,
+		Message: "",
+		Data:    nil,
+	}
+
+	invoker := invokerName(ctx)
+	if !IsAdmin(ctx) {
+		response.Message = fmt.Sprintf("Identity %s is not an admin, only admin can migrate keys", invoker)
+		logger.Info(response.Message)
+		return response
+	}
+	if limit <= 0 {
+		response.Message = fmt.Sprint("Limit must be greater than zero")
+		logger.Info(response.Message)
+		return response
+	}
+
+	// range queries over plain keys never return composite keys
+	resultIterator, err := ctx.GetStub().GetStateByRange(startKey, "")
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while fetching legacy keys: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+	defer resultIterator.Close()
+
+	result := MigrationResult{
+		Migrated: []string{},
+		Skipped:  []string{},
+	}
+	for resultIterator.HasNext() {
+		queryResponse, err := resultIterator.Next()
+		if err != nil {
+			response.Message = fmt.Sprintf("Error while iterating legacy keys: %s", err.Error())
+			logger.Error(response.Message)
+			return response
+		}
+		if len(result.Migrated)+len(result.Skipped) == limit {
+			result.NextKey = queryResponse.Key
+			break
+		}
+
+		objectType := legacyObjectType(queryResponse.Value)
+		if _, known := Find(legacyObjectTypes, objectType); !known {
+			result.Skipped = append(result.Skipped, queryResponse.Key)
+			continue
+		}
+
+		key, _ := objectKey(ctx, objectType, queryResponse.Key)
+		existing, err := ctx.GetStub().GetState(key)
+		if err != nil {
+			response.Message = fmt.Sprintf("Error while fetching %s %s: %s", objectType, queryResponse.Key, err.Error())
+			logger.Error(response.Message)
+			return response
+		}
+		// object already written under composite key is newer than legacy copy
+		if existing == nil {
+			err = ctx.GetStub().PutState(key, queryResponse.Value)
+			if err != nil {
+				response.Message = fmt.Sprintf("Error while migrating %s %s: %s", objectType, queryResponse.Key, err.Error())
+				logger.Error(response.Message)
+				return response
+			}
+		}
+		if objectType == DocumentObject {
+			err = indexDocumentHashes(ctx, queryResponse.Value)
+			if err != nil {
+				response.Message = fmt.Sprintf("Error while indexing document %s: %s", queryResponse.Key, err.Error())
+				logger.Error(response.Message)
+				return response
+			}
+		}
+		err = ctx.GetStub().DelState(queryResponse.Key)
+		if err != nil {
+			response.Message = fmt.Sprintf("Error while deleting legacy key %s: %s", queryResponse.Key, err.Error())
+			logger.Error(response.Message)
+			return response
+		}
+		result.Migrated = append(result.Migrated, queryResponse.Key)
+	}
+
+	response.Success = true
+	response.Message = fmt.Sprintf("Migrated %d legacy keys", len(result.Migrated))
+	logger.Info(response.Message)
+	response.Data = result
+	return response
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestGetObjectLegacyFallback(t *testing.T) {
+	tests := []struct {
+		name       string
+		setup      func(l *testLedger)
+		objectType string
+		expected   string // AKcessID or asset ID of object read, empty when nothing is read
+	}{
+		{
+			name: "object under composite key",
+			setup: func(l *testLedger) {
+				l.put(l.compositeKey(UserObject, "alice"), User{ObjectType: "user", AkcessID: "alice"})
+			},
+			objectType: UserObject,
+			expected:   "alice",
+		},
+		{
+			name: "object under legacy plain key",
+			setup: func(l *testLedger) {
+				l.put("alice", User{ObjectType: "user", AkcessID: "alice"})
+			},
+			objectType: UserObject,
+			expected:   "alice",
+		},
+		{
+			name: "legacy plain key holding object of other type",
+			setup: func(l *testLedger) {
+				l.put("alice", User{ObjectType: "user", AkcessID: "alice"})
+			},
+			objectType: VerifierObject,
+		},
+		{
+			name: "composite key takes precedence over legacy plain key",
+			setup: func(l *testLedger) {
+				l.put("alice", User{ObjectType: "user", AkcessID: "legacy"})
+				l.put(l.compositeKey(UserObject, "alice"), User{ObjectType: "user", AkcessID: "alice"})
+			},
+			objectType: UserObject,
+			expected:   "alice",
+		},
+		{
+			name: "legacy asset stored without docType",
+			setup: func(l *testLedger) {
+				l.put("alice", DigitalAsset{UniqueAssetID: "alice"})
+			},
+			objectType: AssetObject,
+			expected:   "alice",
+		},
+		{
+			name:       "missing object",
+			setup:      func(l *testLedger) {},
+			objectType: UserObject,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := newTestLedger(t)
+			tt.setup(l)
+			value, err := getObject(l.as("Org1MSP", "reader", nil), tt.objectType, "alice")
+			expectErr(t, err, "")
+			if tt.expected == "" {
+				if value != nil {
+					t.Fatalf("expected no object, got %s", value)
+				}
+				return
+			}
+			var object struct {
+				AkcessID      string `json:"akcessId"`
+				UniqueAssetID string `json:"uniqueAssetID"`
+			}
+			json.Unmarshal(value, &object)
+			if object.AkcessID != tt.expected && object.UniqueAssetID != tt.expected {
+				t.Fatalf("expected object %s, got %s", tt.expected, value)
+			}
+		})
+	}
+}
+
+func TestPutObjectRemovesLegacyKey(t *testing.T) {
+	l := newTestLedger(t)
+	l.put("alice", User{ObjectType: "user", AkcessID: "alice"})
+	l.put("bob", Verifier{ObjectType: "verifier", AkcessID: "bob"})
+
+	ctx := l.as("Org1MSP", "writer", nil)
+	userAsBytes, _ := json.Marshal(User{ObjectType: "user", AkcessID: "alice"})
+	expectErr(t, putObject(ctx, UserObject, "alice", userAsBytes), "")
+	expectErr(t, putObject(ctx, UserObject, "bob", userAsBytes), "")
+
+	if l.stub.State["alice"] != nil {
+		t.Fatalf("expected legacy key of user alice to be deleted")
+	}
+	if l.stub.State["bob"] == nil {
+		t.Fatalf("expected legacy key holding verifier bob to be kept")
+	}
+}
+
+func TestMigrateLegacyKeys(t *testing.T) {
+	l := newTestLedger(t)
+	l.initLedger()
+	l.put("alice", User{ObjectType: "user", AkcessID: "alice"})
+	l.put("asset1", DigitalAsset{UniqueAssetID: "asset1", Owner: "alice"})
+	l.put("doc1", Document{ObjectType: "document", DocumentID: "doc1", Versions: []DocumentVersion{{Version: 1, Hash: "ABCD"}}})
+	l.put("junk", map[string]string{"foo": "bar"})
+	// user already written under composite key is newer than its legacy copy
+	l.put("vera", Verifier{ObjectType: "verifier", AkcessID: "legacy"})
+	l.put(l.compositeKey(VerifierObject, "vera"), Verifier{ObjectType: "verifier", AkcessID: "vera"})
+
+	tests := []struct {
+		name             string
+		mspID            string
+		startKey         string
+		limit            int
+		expectedMigrated []string
+		expectedSkipped  []string
+		expectedNextKey  string
+		expectedError    string
+	}{
+		{
+			name:          "non admin can't migrate",
+			mspID:         "Org1MSP",
+			limit:         10,
+			expectedError: "only admin can migrate keys",
+		},
+		{
+			name:          "limit must be positive",
+			mspID:         "AdminMSP",
+			limit:         0,
+			expectedError: "Limit must be greater than zero",
+		},
+		{
+			name:             "first batch",
+			mspID:            "AdminMSP",
+			limit:            3,
+			expectedMigrated: []string{"alice", "asset1", "doc1"},
+			expectedSkipped:  []string{},
+			expectedNextKey:  "junk",
+		},
+		{
+			name:             "last batch",
+			mspID:            "AdminMSP",
+			startKey:         "junk",
+			limit:            3,
+			expectedMigrated: []string{"vera"},
+			expectedSkipped:  []string{"junk"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := new(UserContract).MigrateLegacyKeys(l.as(tt.mspID, "admin", nil), tt.startKey, tt.limit)
+			expectResponse(t, response, tt.expectedError)
+			if tt.expectedError != "" {
+				return
+			}
+			result := response.Data.(MigrationResult)
+			if !equalStrings(result.Migrated, tt.expectedMigrated) || !equalStrings(result.Skipped, tt.expectedSkipped) || result.NextKey != tt.expectedNextKey {
+				t.Fatalf("expected migrated %v, skipped %v, next key %q, got %+v", tt.expectedMigrated, tt.expectedSkipped, tt.expectedNextKey, result)
+			}
+		})
+	}
+
+	for _, key := range []string{"alice", "asset1", "doc1", "vera"} {
+		if l.stub.State[key] != nil {
+			t.Fatalf("expected legacy key %s to be deleted", key)
+		}
+	}
+	ctx := l.as("Org1MSP", "reader", nil)
+	verifier, err := new(UserContract).GetVerifier(ctx, "vera")
+	expectErr(t, err, "")
+	if verifier == nil || verifier.AkcessID != "vera" {
+		t.Fatalf("expected composite copy of verifier vera to be kept, got %+v", verifier)
+	}
+	if l.stub.State[l.compositeKey(DocHashIndex, "abcd", "doc1", "1")] == nil {
+		t.Fatalf("expected migrated document to be indexed by hash")
+	}
+}
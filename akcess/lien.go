@@ -0,0 +1,311 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// PlaceLien owner registers lien of lienholder on asset, co-owners need approval by lien rule of asset.
+// Lien blocks transfer of asset until lienholder releases it or it expires at expiry, pass empty expiry for lien without end
+func (da *DigitalAssetContract) PlaceLien(ctx contractapi.TransactionContextInterface, assetID string, lienholder string, amountRef string, expiry string) Response {
+	response := Response{
+		TxID:    ctx.GetStub().GetTxID(),
+		Success: false,
+		Message: "",
+		Data:    nil,
+	}
+
+	invoker, err := resolveAkcessID(ctx)
+	if err != nil {
+		response.Message = err.Error()
+		logger.Info(response.Message)
+		return response
+	}
+	if amountRef == "" {
+		response.Message = fmt.Sprint("Amount reference of lien can't be empty")
+		logger.Info(response.Message)
+		return response
+	}
+	asset, err := getAsset(ctx, assetID)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while getting asset from ledger: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+	if asset == nil {
+		response.Message = fmt.Sprintf("Digital asset with id %s not found", assetID)
+		logger.Info(response.Message)
+		return response
+	}
+	err = checkAssetApproval(ctx, asset, AssetActionLien, lienholder, invoker)
+	if err != nil {
+		response.Message = err.Error()
+		logger.Info(response.Message)
+		return response
+	}
+	user, err := getUser(ctx, lienholder)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while fetching user from world state: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+	if user == nil {
+		response.Message = fmt.Sprintf("User with id %s doesn't exist", lienholder)
+		logger.Info(response.Message)
+		return response
+	}
+
+	txTime, err := getTxTimestamp(ctx)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while getting tx timestamp: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+	lien := &Lien{
+		ObjectType: LienObject,
+		LienID:     response.TxID,
+		AssetID:    assetID,
+		Lienholder: lienholder,
+		AmountRef:  amountRef,
+		PlacedBy:   invoker,
+		PlacedAt:   txTime,
+		Status:     LienActive,
+	}
+	if expiry != "" {
+		expiresAt, err := time.Parse(time.RFC3339, expiry)
+		if err != nil {
+			response.Message = fmt.Sprintf("Error while parsing date pass date in ISO format: %s", err.Error())
+			logger.Info(response.Message)
+			return response
+		}
+		policy, err := getTimePolicy(ctx)
+		if err != nil {
+			response.Message = fmt.Sprintf("Error while fetching time policy: %s", err.Error())
+			logger.Error(response.Message)
+			return response
+		}
+		err = policy.CheckExpiryDate(expiresAt, txTime)
+		if err != nil {
+			response.Message = err.Error()
+			logger.Info(response.Message)
+			return response
+		}
+		lien.ExpiresAt = &expiresAt
+	}
+
+	err = saveLien(ctx, lien)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while saving lien: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+	err = putLienIndex(ctx, AssetLienIndex, assetID, lien.LienID)
+	if err == nil {
+		err = putLienIndex(ctx, LienholderIndex, lienholder, lien.LienID)
+	}
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while indexing lien: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+	err = clearAssetApprovals(ctx, assetID, AssetActionLien, lienholder)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while clearing approvals: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+
+	response.Success = true
+	response.Message = fmt.Sprintf("Lien %s of %s placed on digital asset %s", lien.LienID, lienholder, assetID)
+	logger.Info(response.Message)
+	response.Data = lien
+	return response
+}
+
+// ReleaseLien lienholder releases lien, asset can be transferred again once it has no other active lien
+func (da *DigitalAssetContract) ReleaseLien(ctx contractapi.TransactionContextInterface, lienID string) Response {
+	response := Response{
+		TxID:    ctx.GetStub().GetTxID(),
+		Success: false,
+		Message: "",
+		Data:    nil,
+	}
+
+	invoker, err := resolveAkcessID(ctx)
+	if err != nil {
+		response.Message = err.Error()
+		logger.Info(response.Message)
+		return response
+	}
+	lien, err := getLien(ctx, lienID)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while fetching lien from world state: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+	if lien == nil {
+		response.Message = fmt.Sprintf("Lien with id %s doesn't exist", lienID)
+		logger.Info(response.Message)
+		return response
+	}
+	if lien.Lienholder != invoker {
+		response.Message = fmt.Sprintf("Lien %s is not held by %s", lienID, invoker)
+		logger.Info(response.Message)
+		return response
+	}
+	if lien.Status == LienReleased {
+		response.Message = fmt.Sprintf("Lien %s is already released", lienID)
+		logger.Info(response.Message)
+		return response
+	}
+
+	txTime, err := getTxTimestamp(ctx)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while getting tx timestamp: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+	lien.Status = LienReleased
+	lien.ReleasedAt = &txTime
+	err = saveLien(ctx, lien)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while releasing lien: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+	indexKey, err := ctx.GetStub().CreateCompositeKey(AssetLienIndex, []string{lien.AssetID, lienID})
+	if err == nil {
+		err = ctx.GetStub().DelState(indexKey)
+	}
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while removing lien from asset index: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+
+	response.Success = true
+	response.Message = fmt.Sprintf("Lien %s on digital asset %s released by %s", lienID, lien.AssetID, invoker)
+	logger.Info(response.Message)
+	response.Data = lien
+	return response
+}
+
+// GetLiensByLienholder returns all liens of lienholder with their effective status, released ones included
+func (da *DigitalAssetContract) GetLiensByLienholder(ctx contractapi.TransactionContextInterface, lienholder string) Response {
+	response := Response{
+		TxID:    ctx.GetStub().GetTxID(),
+		Success: false,
+		Message: "",
+		Data:    nil,
+	}
+
+	txTime, err := getTxTimestamp(ctx)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while getting tx timestamp: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+	liens, err := getIndexedLiens(ctx, LienholderIndex, lienholder, txTime)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while fetching liens: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+
+	response.Success = true
+	response.Message = fmt.Sprintf("Successfully fetched liens of %s", lienholder)
+	logger.Info(response.Message)
+	response.Data = liens
+	return response
+}
+
+// EffectiveStatus status of lien at given time, active liens past their expiry are expired
+func (l Lien) EffectiveStatus(now time.Time) string {
+	if l.Status == LienActive && l.ExpiresAt != nil && !now.Before(*l.ExpiresAt) {
+		return LienExpired
+	}
+	return l.Status
+}
+
+// checkNoActiveLien refuses assets encumbered by active lien
+func checkNoActiveLien(ctx contractapi.TransactionContextInterface, assetID string, now time.Time) error {
+	liens, err := getIndexedLiens(ctx, AssetLienIndex, assetID, now)
+	if err != nil {
+		return fmt.Errorf("Error while fetching liens: %s", err.Error())
+	}
+	for _, lien := range liens {
+		if lien.Status == LienActive {
+			return fmt.Errorf("Digital asset %s is encumbered by lien %s of %s", assetID, lien.LienID, lien.Lienholder)
+		}
+	}
+	return nil
+}
+
+// getIndexedLiens reads liens listed in index under given key with their effective status
+func getIndexedLiens(ctx contractapi.TransactionContextInterface, index string, key string, now time.Time) ([]Lien, error) {
+	resultIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(index, []string{key})
+	if err != nil {
+		return nil, err
+	}
+	defer resultIterator.Close()
+
+	liens := []Lien{}
+	for resultIterator.HasNext() {
+		queryResponse, err := resultIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+		_, attributes, err := ctx.GetStub().SplitCompositeKey(queryResponse.Key)
+		if err != nil || len(attributes) != 2 {
+			continue
+		}
+		lien, err := getLien(ctx, attributes[1])
+		if err != nil {
+			return nil, err
+		}
+		if lien == nil {
+			continue
+		}
+		lien.Status = lien.EffectiveStatus(now)
+		liens = append(liens, *lien)
+	}
+	return liens, nil
+}
+
+// putLienIndex adds lien to index under given key
+func putLienIndex(ctx contractapi.TransactionContextInterface, index string, key string, lienID string) error {
+	indexKey, err := ctx.GetStub().CreateCompositeKey(index, []string{key, lienID})
+	if err != nil {
+		return err
+	}
+	return ctx.GetStub().PutState(indexKey, []byte{0x00})
+}
+
+// getLien reads lien, nil if lien doesn't exist
+func getLien(ctx contractapi.TransactionContextInterface, lienID string) (*Lien, error) {
+	lienAsBytes, err := getObject(ctx, LienObject, lienID)
+	if err != nil {
+		return nil, err
+	}
+	if lienAsBytes == nil {
+		return nil, nil
+	}
+	var lien Lien
+	err = json.Unmarshal(lienAsBytes, &lien)
+	if err != nil {
+		return nil, err
+	}
+	return &lien, nil
+}
+
+// saveLien writes lien to world state
+func saveLien(ctx contractapi.TransactionContextInterface, lien *Lien) error {
+	lienAsBytes, err := json.Marshal(lien)
+	if err != nil {
+		return err
+	}
+	return putObject(ctx, LienObject, lien.LienID, lienAsBytes)
+}
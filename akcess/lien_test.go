@@ -0,0 +1,138 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+func TestPlaceLien(t *testing.T) {
+	tests := []struct {
+		name          string
+		invoker       string
+		assetID       string
+		lienholder    string
+		amountRef     string
+		expiry        string
+		expectedError string
+	}{
+		{name: "owner places lien", invoker: "alice", assetID: "asset1", lienholder: "bank", amountRef: "loan-1"},
+		{name: "owner places expiring lien", invoker: "alice", assetID: "asset1", lienholder: "bank", amountRef: "loan-1", expiry: testTime.AddDate(1, 0, 0).Format(time.RFC3339)},
+		{name: "wrong owner", invoker: "mallory", assetID: "asset1", lienholder: "bank", amountRef: "loan-1", expectedError: "Digtal asset with id asset1 not owned by mallory"},
+		{name: "unknown asset", invoker: "alice", assetID: "asset2", lienholder: "bank", amountRef: "loan-1", expectedError: "Digital asset with id asset2 not found"},
+		{name: "unknown lienholder", invoker: "alice", assetID: "asset1", lienholder: "ghost", amountRef: "loan-1", expectedError: "User with id ghost doesn't exist"},
+		{name: "missing amount reference", invoker: "alice", assetID: "asset1", lienholder: "bank", expectedError: "Amount reference of lien can't be empty"},
+		{name: "expiry in the past", invoker: "alice", assetID: "asset1", lienholder: "bank", amountRef: "loan-1", expiry: testTime.AddDate(-1, 0, 0).Format(time.RFC3339), expectedError: "is not in the future"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := newAssetLedger(t)
+			response := new(DigitalAssetContract).PlaceLien(l.as("Org1MSP", tt.invoker, nil), tt.assetID, tt.lienholder, tt.amountRef, tt.expiry)
+			expectResponse(t, response, tt.expectedError)
+		})
+	}
+}
+
+func TestCheckNoActiveLien(t *testing.T) {
+	past := testTime.Add(-time.Hour)
+	future := testTime.AddDate(1, 0, 0)
+	tests := []struct {
+		name          string
+		liens         []Lien
+		expectedError string
+	}{
+		{name: "no lien"},
+		{name: "active lien", liens: []Lien{{LienID: "lien1", Lienholder: "bank", Status: LienActive}}, expectedError: "Digital asset asset1 is encumbered by lien lien1 of bank"},
+		{name: "active lien not expired yet", liens: []Lien{{LienID: "lien1", Lienholder: "bank", Status: LienActive, ExpiresAt: &future}}, expectedError: "encumbered by lien lien1"},
+		{name: "expired lien", liens: []Lien{{LienID: "lien1", Lienholder: "bank", Status: LienActive, ExpiresAt: &past}}},
+		{name: "released lien", liens: []Lien{{LienID: "lien1", Lienholder: "bank", Status: LienReleased}}},
+		{
+			name: "active lien next to released one",
+			liens: []Lien{
+				{LienID: "lien1", Lienholder: "bank", Status: LienReleased},
+				{LienID: "lien2", Lienholder: "bank", Status: LienActive},
+			},
+			expectedError: "encumbered by lien lien2",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := newTestLedger(t)
+			for _, lien := range tt.liens {
+				lien.ObjectType = LienObject
+				lien.AssetID = "asset1"
+				l.put(l.compositeKey(LienObject, lien.LienID), lien)
+				l.put(l.compositeKey(AssetLienIndex, "asset1", lien.LienID), nil)
+			}
+			ctx := l.as("Org1MSP", "reader", nil)
+			expectErr(t, checkNoActiveLien(ctx, "asset1", l.now()), tt.expectedError)
+		})
+	}
+}
+
+func TestLienBlocksTransfer(t *testing.T) {
+	l := newAssetLedger(t)
+	response := new(DigitalAssetContract).PlaceLien(l.as("Org1MSP", "alice", nil), "asset1", "bank", "loan-1", "")
+	expectResponse(t, response, "")
+	lienID := response.Data.(*Lien).LienID
+
+	// steps run in order against the same asset
+	tests := []struct {
+		name          string
+		invoker       string
+		action        func(ctx contractapi.TransactionContextInterface) Response
+		expectedError string
+	}{
+		{
+			name:          "asset with active lien can't be offered",
+			invoker:       "alice",
+			action:        offerAsset1ToMallory,
+			expectedError: "Digital asset asset1 is encumbered by lien " + lienID,
+		},
+		{
+			name:    "asset with active lien can't be removed",
+			invoker: "alice",
+			action: func(ctx contractapi.TransactionContextInterface) Response {
+				return new(DigitalAssetContract).RemoveAsset(ctx, "asset1")
+			},
+			expectedError: "encumbered by lien " + lienID,
+		},
+		{
+			name:    "lien can't be released by owner of asset",
+			invoker: "alice",
+			action: func(ctx contractapi.TransactionContextInterface) Response {
+				return new(DigitalAssetContract).ReleaseLien(ctx, lienID)
+			},
+			expectedError: "Lien " + lienID + " is not held by alice",
+		},
+		{
+			name:    "lienholder releases lien",
+			invoker: "bank",
+			action: func(ctx contractapi.TransactionContextInterface) Response {
+				return new(DigitalAssetContract).ReleaseLien(ctx, lienID)
+			},
+		},
+		{
+			name:    "released lien can't be released again",
+			invoker: "bank",
+			action: func(ctx contractapi.TransactionContextInterface) Response {
+				return new(DigitalAssetContract).ReleaseLien(ctx, lienID)
+			},
+			expectedError: "is already released",
+		},
+		{
+			name:    "asset without active lien is offered",
+			invoker: "alice",
+			action:  offerAsset1ToMallory,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expectResponse(t, tt.action(l.as("Org1MSP", tt.invoker, nil)), tt.expectedError)
+		})
+	}
+}
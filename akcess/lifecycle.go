@@ -0,0 +1,272 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// RevokeDocument owner or admin revokes document, revoked document can't be signed, verified, shared or linked
+func (d *DocContract) RevokeDocument(ctx contractapi.TransactionContextInterface, documentid string, reason string) Response {
+	response := Response{
+		TxID:    ctx.GetStub().GetTxID(),
+		Success: false,
+		Message: "",
+		Data:    nil,
+	}
+
+	invoker := invokerName(ctx)
+	doc, err := getManagedDocument(ctx, documentid)
+	if err != nil {
+		response.Message = err.Error()
+		logger.Info(response.Message)
+		return response
+	}
+	if reason == "" {
+		response.Message = fmt.Sprint("Reason of revocation can't be empty")
+		logger.Info(response.Message)
+		return response
+	}
+
+	txTime, err := getTxTimestamp(ctx)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while getting tx timestamp: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+	doc.Status = DocumentRevoked
+	doc.StatusReason = reason
+	doc.StatusChanged = &StatusChange{By: invoker, At: txTime}
+	docAsBytes, _ := json.Marshal(doc)
+	err = putObject(ctx, DocumentObject, documentid, docAsBytes)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while revoking doc: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+
+	response.Success = true
+	response.Message = fmt.Sprintf("Document %s revoked by %s", documentid, invoker)
+	logger.Info(response.Message)
+	response.Data = doc
+	return response
+}
+
+// SupersedeDocument owner or admin marks document as superseded by active replacement document of the same owner
+func (d *DocContract) SupersedeDocument(ctx contractapi.TransactionContextInterface, documentid string, replacementID string, reason string) Response {
+	response := Response{
+		TxID:    ctx.GetStub().GetTxID(),
+		Success: false,
+		Message: "",
+		Data:    nil,
+	}
+
+	invoker := invokerName(ctx)
+	doc, err := getManagedDocument(ctx, documentid)
+	if err != nil {
+		response.Message = err.Error()
+		logger.Info(response.Message)
+		return response
+	}
+	if replacementID == documentid {
+		response.Message = fmt.Sprintf("Document %s can't supersede itself", documentid)
+		logger.Info(response.Message)
+		return response
+	}
+	replacement, err := getDocument(ctx, replacementID)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while fetching doc from world state: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+	if replacement == nil {
+		response.Message = fmt.Sprintf("Document with id %s doesn't exist", replacementID)
+		logger.Info(response.Message)
+		return response
+	}
+	if replacement.AkcessID != doc.AkcessID {
+		response.Message = fmt.Sprintf("Replacement document %s is not owned by %s", replacementID, doc.AkcessID)
+		logger.Info(response.Message)
+		return response
+	}
+
+	txTime, err := getTxTimestamp(ctx)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while getting tx timestamp: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+	err = checkDocumentActive(replacement, txTime)
+	if err != nil {
+		response.Message = err.Error()
+		logger.Info(response.Message)
+		return response
+	}
+
+	doc.Status = DocumentSuperseded
+	doc.StatusReason = reason
+	doc.ReplacedBy = replacementID
+	doc.StatusChanged = &StatusChange{By: invoker, At: txTime}
+	docAsBytes, _ := json.Marshal(doc)
+	err = putObject(ctx, DocumentObject, documentid, docAsBytes)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while superseding doc: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+
+	response.Success = true
+	response.Message = fmt.Sprintf("Document %s superseded by %s", documentid, replacementID)
+	logger.Info(response.Message)
+	response.Data = doc
+	return response
+}
+
+// SetDocumentExpiry owner or admin sets date document expires at, pass empty date to remove expiry.
+// Expiry date must be in the future and within expiry horizon of time policy
+func (d *DocContract) SetDocumentExpiry(ctx contractapi.TransactionContextInterface, documentid string, expiryDate string) Response {
+	response := Response{
+		TxID:    ctx.GetStub().GetTxID(),
+		Success: false,
+		Message: "",
+		Data:    nil,
+	}
+
+	doc, err := getManagedDocument(ctx, documentid)
+	if err != nil {
+		response.Message = err.Error()
+		logger.Info(response.Message)
+		return response
+	}
+
+	doc.ExpiresAt = nil
+	if expiryDate != "" {
+		expiresAt, err := time.Parse(time.RFC3339, expiryDate)
+		if err != nil {
+			response.Message = fmt.Sprintf("Error while parsing date pass date in ISO format: %s", err.Error())
+			logger.Info(response.Message)
+			return response
+		}
+		txTime, err := getTxTimestamp(ctx)
+		if err != nil {
+			response.Message = fmt.Sprintf("Error while getting tx timestamp: %s", err.Error())
+			logger.Error(response.Message)
+			return response
+		}
+		policy, err := getTimePolicy(ctx)
+		if err != nil {
+			response.Message = fmt.Sprintf("Error while fetching time policy: %s", err.Error())
+			logger.Error(response.Message)
+			return response
+		}
+		err = policy.CheckExpiryDate(expiresAt, txTime)
+		if err != nil {
+			response.Message = err.Error()
+			logger.Info(response.Message)
+			return response
+		}
+		doc.ExpiresAt = &expiresAt
+	}
+	docAsBytes, _ := json.Marshal(doc)
+	err = putObject(ctx, DocumentObject, documentid, docAsBytes)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while updating doc expiry: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+
+	response.Success = true
+	if doc.ExpiresAt == nil {
+		response.Message = fmt.Sprintf("Expiry of document %s removed", documentid)
+	} else {
+		response.Message = fmt.Sprintf("Document %s expires at %s", documentid, doc.ExpiresAt.Format(time.RFC3339))
+	}
+	logger.Info(response.Message)
+	response.Data = doc
+	return response
+}
+
+// GetDocument returns document with its effective lifecycle status
+func (d *DocContract) GetDocument(ctx contractapi.TransactionContextInterface, documentid string) Response {
+	response := Response{
+		TxID:    ctx.GetStub().GetTxID(),
+		Success: false,
+		Message: "",
+		Data:    nil,
+	}
+
+	doc, err := getDocument(ctx, documentid)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while fetching doc from world state: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+	if doc == nil {
+		response.Message = fmt.Sprintf("Document with id %s doesn't exist", documentid)
+		logger.Info(response.Message)
+		return response
+	}
+	txTime, err := getTxTimestamp(ctx)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while getting tx timestamp: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+	doc.Status = doc.EffectiveStatus(txTime)
+
+	response.Success = true
+	response.Message = fmt.Sprintf("Successfully fetched document %s", documentid)
+	logger.Info(response.Message)
+	response.Data = doc
+	return response
+}
+
+// EffectiveStatus lifecycle status of document at given time, documents made before statuses were added are active
+func (d Document) EffectiveStatus(now time.Time) string {
+	if d.Status == DocumentRevoked || d.Status == DocumentSuperseded {
+		return d.Status
+	}
+	if d.ExpiresAt != nil && !now.Before(*d.ExpiresAt) {
+		return DocumentExpired
+	}
+	return DocumentActive
+}
+
+// checkDocumentActive refuses documents which are revoked, superseded or expired
+func checkDocumentActive(doc *Document, now time.Time) error {
+	status := doc.EffectiveStatus(now)
+	if status == DocumentActive {
+		return nil
+	}
+	if status == DocumentSuperseded {
+		return fmt.Errorf("Document %s is superseded by %s", doc.DocumentID, doc.ReplacedBy)
+	}
+	return fmt.Errorf("Document %s is %s", doc.DocumentID, status)
+}
+
+// getManagedDocument reads document whose lifecycle invoker can manage, that is owner bound to identity of invoker
+// or admin, revoked and superseded documents can't be changed anymore
+func getManagedDocument(ctx contractapi.TransactionContextInterface, documentid string) (*Document, error) {
+	doc, err := getDocument(ctx, documentid)
+	if err != nil {
+		return nil, fmt.Errorf("Error while fetching doc from world state: %s", err.Error())
+	}
+	if doc == nil {
+		return nil, fmt.Errorf("Document with id %s doesn't exist", documentid)
+	}
+	if !IsAdmin(ctx) {
+		owner, err := resolveAkcessID(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if doc.AkcessID != owner {
+			return nil, fmt.Errorf("Identity %s is neither owner of document %s nor an admin", owner, documentid)
+		}
+	}
+	if doc.Status == DocumentRevoked || doc.Status == DocumentSuperseded {
+		return nil, fmt.Errorf("Document %s is already %s", documentid, doc.Status)
+	}
+	return doc, nil
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// newLifecycleLedger ledger of newDocLedger where bob also owns doc2 and carol registered under AKcessID
+// Org2MSP::mallory owns doc3
+func newLifecycleLedger(t *testing.T) *testLedger {
+	l := newDocLedger(t)
+	expectResponse(t, new(DocContract).CreateDocWithHash(l.as("Org1MSP", "bob", nil), "doc2", "passport", saltedHash("", "renewed"), "sha256"), "")
+	carol := l.as("Org1MSP", "carol", map[string]string{"akcessId": "Org2MSP::mallory"})
+	expectResponse(t, new(UserContract).CreateUser(carol), "")
+	expectResponse(t, new(DocContract).CreateDocWithHash(carol, "doc3", "passport", saltedHash("", "carol"), "sha256"), "")
+	return l
+}
+
+func TestRevokeDocument(t *testing.T) {
+	tests := []struct {
+		name          string
+		invoker       func(l *testLedger) contractapi.TransactionContextInterface
+		documentid    string
+		reason        string
+		expectedError string
+	}{
+		{
+			name:       "owner revokes",
+			invoker:    func(l *testLedger) contractapi.TransactionContextInterface { return l.as("Org1MSP", "bob", nil) },
+			documentid: "doc1",
+			reason:     "lost",
+		},
+		{
+			name:       "admin revokes",
+			invoker:    func(l *testLedger) contractapi.TransactionContextInterface { return l.as("AdminMSP", "admin", nil) },
+			documentid: "doc1",
+			reason:     "forged",
+		},
+		{
+			name:          "wrong owner",
+			invoker:       func(l *testLedger) contractapi.TransactionContextInterface { return l.as("Org1MSP", "mallory", nil) },
+			documentid:    "doc1",
+			reason:        "lost",
+			expectedError: "Identity mallory is neither owner of document doc1 nor an admin",
+		},
+		{
+			name:          "unbound identity named as owner",
+			invoker:       func(l *testLedger) contractapi.TransactionContextInterface { return l.as("Org2MSP", "mallory", nil) },
+			documentid:    "doc3",
+			reason:        "lost",
+			expectedError: "Identity Org2MSP::mallory is not bound to any AKcessID",
+		},
+		{
+			name:          "no reason",
+			invoker:       func(l *testLedger) contractapi.TransactionContextInterface { return l.as("Org1MSP", "bob", nil) },
+			documentid:    "doc1",
+			expectedError: "Reason of revocation can't be empty",
+		},
+		{
+			name:          "unknown document",
+			invoker:       func(l *testLedger) contractapi.TransactionContextInterface { return l.as("Org1MSP", "bob", nil) },
+			documentid:    "doc9",
+			reason:        "lost",
+			expectedError: "Document with id doc9 doesn't exist",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := newLifecycleLedger(t)
+			expectResponse(t, new(DocContract).RevokeDocument(tt.invoker(l), tt.documentid, tt.reason), tt.expectedError)
+			if tt.expectedError != "" {
+				return
+			}
+			doc, _ := getDocument(l.as("Org1MSP", "bob", nil), tt.documentid)
+			if doc.Status != DocumentRevoked || doc.StatusReason != tt.reason || doc.StatusChanged == nil {
+				t.Fatalf("expected document revoked for %s, got %+v", tt.reason, doc)
+			}
+			expectErr(t, checkDocumentActive(doc, l.now()), "Document doc1 is revoked")
+			expectResponse(t, new(DocContract).RevokeDocument(tt.invoker(l), tt.documentid, tt.reason), "Document doc1 is already revoked")
+		})
+	}
+}
+
+func TestSupersedeDocument(t *testing.T) {
+	tests := []struct {
+		name          string
+		setup         func(l *testLedger)
+		invoker       string
+		replacementID string
+		expectedError string
+	}{
+		{name: "owner supersedes", setup: func(l *testLedger) {}, invoker: "bob", replacementID: "doc2"},
+		{name: "wrong owner", setup: func(l *testLedger) {}, invoker: "mallory", replacementID: "doc2", expectedError: "Identity mallory is neither owner of document doc1 nor an admin"},
+		{name: "document supersedes itself", setup: func(l *testLedger) {}, invoker: "bob", replacementID: "doc1", expectedError: "Document doc1 can't supersede itself"},
+		{name: "replacement of other owner", setup: func(l *testLedger) {}, invoker: "bob", replacementID: "doc3", expectedError: "Replacement document doc3 is not owned by bob"},
+		{name: "unknown replacement", setup: func(l *testLedger) {}, invoker: "bob", replacementID: "doc9", expectedError: "Document with id doc9 doesn't exist"},
+		{
+			name: "revoked replacement",
+			setup: func(l *testLedger) {
+				expectResponse(l.t, new(DocContract).RevokeDocument(l.as("Org1MSP", "bob", nil), "doc2", "lost"), "")
+			},
+			invoker:       "bob",
+			replacementID: "doc2",
+			expectedError: "Document doc2 is revoked",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := newLifecycleLedger(t)
+			tt.setup(l)
+			expectResponse(t, new(DocContract).SupersedeDocument(l.as("Org1MSP", tt.invoker, nil), "doc1", tt.replacementID, "renewed"), tt.expectedError)
+			if tt.expectedError != "" {
+				return
+			}
+			doc, _ := getDocument(l.as("Org1MSP", "bob", nil), "doc1")
+			if doc.Status != DocumentSuperseded || doc.ReplacedBy != tt.replacementID || doc.StatusChanged.By != tt.invoker {
+				t.Fatalf("expected document superseded by %s, got %+v", tt.replacementID, doc)
+			}
+			expectErr(t, checkDocumentActive(doc, l.now()), "Document doc1 is superseded by doc2")
+		})
+	}
+}
+
+func TestSetDocumentExpiry(t *testing.T) {
+	tests := []struct {
+		name          string
+		invoker       string
+		expiresIn     time.Duration
+		expectedError string
+	}{
+		{name: "owner sets expiry", invoker: "bob", expiresIn: time.Hour},
+		{name: "owner removes expiry", invoker: "bob"},
+		{name: "wrong owner", invoker: "mallory", expiresIn: time.Hour, expectedError: "Identity mallory is neither owner of document doc1 nor an admin"},
+		{name: "expiry in the past", invoker: "bob", expiresIn: -time.Hour, expectedError: "is not in the future"},
+		{name: "expiry beyond horizon", invoker: "bob", expiresIn: (DefaultExpiryHorizonDays + 1) * 24 * time.Hour, expectedError: "days in the future"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := newLifecycleLedger(t)
+			expiryDate := ""
+			if tt.expiresIn != 0 {
+				expiryDate = l.now().Add(tt.expiresIn).Format(time.RFC3339)
+			}
+			expectResponse(t, new(DocContract).SetDocumentExpiry(l.as("Org1MSP", tt.invoker, nil), "doc1", expiryDate), tt.expectedError)
+			if tt.expectedError != "" || expiryDate == "" {
+				return
+			}
+			doc, _ := getDocument(l.as("Org1MSP", "bob", nil), "doc1")
+			if checkDocumentActive(doc, l.now()) != nil {
+				t.Fatalf("expected document active until expiry, got %+v", doc)
+			}
+			expectErr(t, checkDocumentActive(doc, l.now().Add(tt.expiresIn)), "Document doc1 is expired")
+		})
+	}
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"otp"
+)
+
+// issueOTP issues OTP challenge to bob for doc1 and returns its ID
+func issueOTP(l *testLedger, mspID string, attrs map[string]string, otpCode string, expiresIn int) (string, Response) {
+	l.t.Helper()
+	ctx := l.as(mspID, "issuer", attrs)
+	err := l.stub.SetTransient(map[string][]byte{"otp": []byte(otpCode), "salt": []byte("salt-" + otpCode)})
+	if err != nil {
+		l.t.Fatalf("setting transient data: %s", err.Error())
+	}
+	response := new(DocContract).IssueOTPChallenge(ctx, "doc1", "bob", expiresIn)
+	l.stub.TransientMap = nil
+	return response.TxID, response
+}
+
+func TestIssueOTPChallenge(t *testing.T) {
+	tests := []struct {
+		name          string
+		mspID         string
+		attrs         map[string]string
+		expiresIn     int
+		expectedError string
+	}{
+		{name: "member of OTP issuer MSP", mspID: "OTPMSP", expiresIn: 60},
+		{name: "identity with otpIssuer attribute", mspID: "Org1MSP", attrs: map[string]string{"otpIssuer": "true"}, expiresIn: 60},
+		{name: "identity which is not OTP issuer", mspID: "Org1MSP", expiresIn: 60, expectedError: "only OTP issuer can issue OTP challenges"},
+		{name: "lifetime must be positive", mspID: "OTPMSP", expiresIn: 0, expectedError: "OTP challenge must expire within"},
+		{name: "lifetime is limited", mspID: "OTPMSP", expiresIn: int(otp.MaxLifetime.Seconds()) + 1, expectedError: "OTP challenge must expire within"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := newOTPLedger(t)
+			_, response := issueOTP(l, tt.mspID, tt.attrs, "123456", tt.expiresIn)
+			expectResponse(t, response, tt.expectedError)
+			if tt.expectedError != "" {
+				return
+			}
+			issued := response.Data.(otp.Challenge)
+			if issued.OTPHash != "" || issued.Salt != "" {
+				t.Fatalf("expected OTP hash and salt not to be returned, got %+v", issued)
+			}
+		})
+	}
+}
+
+func TestConsumeOTPChallenge(t *testing.T) {
+	l := newOTPLedger(t)
+	activeID, response := issueOTP(l, "OTPMSP", nil, "123456", 60)
+	expectResponse(t, response, "")
+	expiringID, response := issueOTP(l, "OTPMSP", nil, "654321", 1)
+	expectResponse(t, response, "")
+	issuedAt := l.now()
+
+	// cases run in order against the same challenges, so consumed challenge stays consumed
+	tests := []struct {
+		name          string
+		signer        string
+		otpCode       string
+		now           time.Time
+		expectedID    string
+		expectedError string
+	}{
+		{name: "wrong OTP", signer: "bob", otpCode: "000000", now: issuedAt, expectedError: "OTP doesn't match any challenge issued to bob for document doc1"},
+		{name: "OTP of other signer", signer: "alice", otpCode: "123456", now: issuedAt, expectedError: "OTP doesn't match any challenge issued to alice"},
+		{name: "expired OTP", signer: "bob", otpCode: "654321", now: issuedAt.Add(time.Second), expectedError: "OTP of challenge " + expiringID + " expired at"},
+		{name: "valid OTP", signer: "bob", otpCode: "123456", now: issuedAt.Add(time.Second), expectedID: activeID},
+		{name: "consumed OTP", signer: "bob", otpCode: "123456", now: issuedAt.Add(2 * time.Second), expectedError: "OTP of challenge " + activeID + " is already used"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := l.as("Org1MSP", tt.signer, nil)
+			challenge, err := otp.Consume(ctx, DefaultOTPCollection, "document", "doc1", tt.signer, tt.otpCode, tt.now)
+			expectErr(t, err, tt.expectedError)
+			if tt.expectedError != "" {
+				return
+			}
+			if challenge.ChallengeID != tt.expectedID || challenge.ConsumedAt == nil || challenge.ConsumedBy != ctx.GetStub().GetTxID() {
+				t.Fatalf("expected challenge %s consumed by %s, got %+v", tt.expectedID, ctx.GetStub().GetTxID(), challenge)
+			}
+		})
+	}
+}
+
+func TestOTPChallengeFailedAttempts(t *testing.T) {
+	l, keys := newSigningLedger(t)
+	challengeID, response := issueOTP(l, "OTPMSP", nil, "123456", 60)
+	expectResponse(t, response, "")
+	failedAttempts := func() int {
+		key := l.compositeKey(otp.ObjectType, "bob", "doc1", challengeID)
+		var challenge otp.Challenge
+		json.Unmarshal(l.stub.PvtState[DefaultOTPCollection][key], &challenge)
+		return challenge.FailedAttempts
+	}
+
+	// signing fails on wrong OTP but failed attempt is still written, so it is counted once transaction commits
+	ctx := l.as("Org1MSP", "bob", nil)
+	l.stub.SetTransient(map[string][]byte{"otp": []byte("000000")})
+	signature := signECDSA(t, keys["bob"], saltedHash("", "content"))
+	response = new(DocContract).SignDoc(ctx, "doc1", signature, l.now().Format(time.RFC3339))
+	l.stub.TransientMap = nil
+	expectResponse(t, response, "OTP doesn't match any challenge issued to bob for document doc1")
+	if failedAttempts() != 1 {
+		t.Fatalf("expected failed attempt recorded by SignDoc, got %d", failedAttempts())
+	}
+
+	for attempt := failedAttempts(); attempt < otp.MaxFailedAttempts; attempt++ {
+		_, err := otp.Consume(l.as("Org1MSP", "bob", nil), DefaultOTPCollection, "document", "doc1", "bob", "000000", l.now())
+		expectErr(t, err, "OTP doesn't match any challenge issued to bob for document doc1")
+	}
+	_, err := otp.Consume(l.as("Org1MSP", "bob", nil), DefaultOTPCollection, "document", "doc1", "bob", "123456", l.now())
+	expectErr(t, err, "OTP challenge "+challengeID+" is locked after 5 failed attempts")
+	if failedAttempts() != otp.MaxFailedAttempts {
+		t.Fatalf("expected locked challenge not to count more attempts, got %d", failedAttempts())
+	}
+}
+
+func TestLegacyOTPChallenge(t *testing.T) {
+	legacy := `{"docType":"otpchallenge","challengeId":"tx1","documentID":"doc1","signer":"bob"}`
+	var challenge otp.Challenge
+	if err := json.Unmarshal([]byte(legacy), &challenge); err != nil {
+		t.Fatalf("unmarshalling legacy challenge: %s", err.Error())
+	}
+	if challenge.SubjectID != "doc1" || challenge.ChallengeID != "tx1" {
+		t.Fatalf("expected legacy challenge of doc1, got %+v", challenge)
+	}
+}
+
+// newOTPLedger ledger with user bob owning document doc1
+func newOTPLedger(t *testing.T) *testLedger {
+	l := newTestLedger(t)
+	l.initLedger()
+	expectResponse(t, new(UserContract).CreateUser(l.as("Org1MSP", "bob", nil)), "")
+	l.put(l.compositeKey(DocumentObject, "doc1"), Document{ObjectType: "document", DocumentID: "doc1", AkcessID: "bob"})
+	return l
+}
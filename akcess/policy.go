@@ -0,0 +1,315 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// SetGrade adds new grade to verifier grade taxonomy or updates name and level of existing one
+func (u *UserContract) SetGrade(ctx contractapi.TransactionContextInterface, code string, name string, level int) Response {
+	response := Response{
+		TxID:    ctx.GetStub().GetTxID(),
+		Success: false,
+		Message: "",
+		Data:    nil,
+	}
+
+	invoker := invokerName(ctx)
+	if !IsAdmin(ctx) {
+		response.Message = fmt.Sprintf("Identity %s is not an admin, only admin can change grade taxonomy", invoker)
+		logger.Info(response.Message)
+		return response
+	}
+	if code == "" {
+		response.Message = fmt.Sprint("Grade code can't be empty")
+		logger.Info(response.Message)
+		return response
+	}
+
+	taxonomy, err := getGradeTaxonomy(ctx)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while fetching grade taxonomy: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+
+	txTime, err := getTxTimestamp(ctx)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while getting tx timestamp: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+
+	grade := Grade{
+		Code:  code,
+		Name:  name,
+		Level: level,
+	}
+	updated := false
+	for i, g := range taxonomy.Grades {
+		if g.Code == code {
+			taxonomy.Grades[i] = grade
+			updated = true
+			break
+		}
+	}
+	if !updated {
+		taxonomy.Grades = append(taxonomy.Grades, grade)
+	}
+	taxonomy.UpdatedBy = invoker
+	taxonomy.UpdatedAt = txTime
+
+	key, _ := objectKey(ctx, ConfigObject, "gradetaxonomy")
+	taxonomyAsBytes, _ := json.Marshal(taxonomy)
+	err = ctx.GetStub().PutState(key, taxonomyAsBytes)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while saving grade taxonomy: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+
+	response.Success = true
+	response.Message = fmt.Sprintf("Grade %s set with level %d", code, level)
+	logger.Info(response.Message)
+	response.Data = taxonomy
+	return response
+}
+
+// GetGradeTaxonomy returns all grades verifiers can hold
+func (u *UserContract) GetGradeTaxonomy(ctx contractapi.TransactionContextInterface) Response {
+	response := Response{
+		TxID:    ctx.GetStub().GetTxID(),
+		Success: false,
+		Message: "",
+		Data:    nil,
+	}
+
+	taxonomy, err := getGradeTaxonomy(ctx)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while fetching grade taxonomy: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+
+	response.Success = true
+	response.Message = fmt.Sprint("Successfully fetched grade taxonomy")
+	logger.Info(response.Message)
+	response.Data = taxonomy
+	return response
+}
+
+// SetVerificationPolicy sets minimum grade needed to verify profile field, document type or asset type.
+// Passing empty minGrade removes the policy
+func (u *UserContract) SetVerificationPolicy(ctx contractapi.TransactionContextInterface, scope string, target string, minGrade string) Response {
+	response := Response{
+		TxID:    ctx.GetStub().GetTxID(),
+		Success: false,
+		Message: "",
+		Data:    nil,
+	}
+
+	invoker := invokerName(ctx)
+	if !IsAdmin(ctx) {
+		response.Message = fmt.Sprintf("Identity %s is not an admin, only admin can set verification policy", invoker)
+		logger.Info(response.Message)
+		return response
+	}
+	if _, found := Find([]string{PolicyScopeProfile, PolicyScopeDocument, PolicyScopeAsset}, scope); !found {
+		response.Message = fmt.Sprintf("Invalid policy scope %s, use profile, document or asset", scope)
+		logger.Info(response.Message)
+		return response
+	}
+
+	key, _ := ctx.GetStub().CreateCompositeKey(PolicyObject, []string{scope, target})
+	if minGrade == "" {
+		err := ctx.GetStub().DelState(key)
+		if err != nil {
+			response.Message = fmt.Sprintf("Error while removing verification policy: %s", err.Error())
+			logger.Error(response.Message)
+			return response
+		}
+		response.Success = true
+		response.Message = fmt.Sprintf("Verification policy for %s %s removed", scope, target)
+		logger.Info(response.Message)
+		return response
+	}
+
+	taxonomy, err := getGradeTaxonomy(ctx)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while fetching grade taxonomy: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+	if _, found := taxonomy.Level(minGrade); !found {
+		response.Message = fmt.Sprintf("Grade %s is not part of grade taxonomy", minGrade)
+		logger.Info(response.Message)
+		return response
+	}
+
+	txTime, err := getTxTimestamp(ctx)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while getting tx timestamp: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+
+	policy := VerificationPolicy{
+		ObjectType: "verificationpolicy",
+		Scope:      scope,
+		Target:     target,
+		MinGrade:   minGrade,
+		UpdatedBy:  invoker,
+		UpdatedAt:  txTime,
+	}
+	policyAsBytes, _ := json.Marshal(policy)
+	err = ctx.GetStub().PutState(key, policyAsBytes)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while saving verification policy: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+
+	response.Success = true
+	response.Message = fmt.Sprintf("Verification of %s %s now requires grade %s", scope, target, minGrade)
+	logger.Info(response.Message)
+	response.Data = policy
+	return response
+}
+
+// GetVerificationPolicies returns all verification policies of given scope
+func (u *UserContract) GetVerificationPolicies(ctx contractapi.TransactionContextInterface, scope string) Response {
+	response := Response{
+		TxID:    ctx.GetStub().GetTxID(),
+		Success: false,
+		Message: "",
+		Data:    nil,
+	}
+
+	resultIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(PolicyObject, []string{scope})
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while fetching verification policies: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+	defer resultIterator.Close()
+
+	result := []VerificationPolicy{}
+	for resultIterator.HasNext() {
+		queryResponse, _ := resultIterator.Next()
+
+		policy := new(VerificationPolicy)
+		_ = json.Unmarshal(queryResponse.Value, policy)
+		result = append(result, *policy)
+	}
+
+	response.Success = true
+	response.Message = fmt.Sprintf("Successfully fetched %s verification policies", scope)
+	logger.Info(response.Message)
+	response.Data = result
+	return response
+}
+
+// Level returns level of grade with given code
+func (t GradeTaxonomy) Level(code string) (int, bool) {
+	for _, g := range t.Grades {
+		if g.Code == code {
+			return g.Level, true
+		}
+	}
+	return 0, false
+}
+
+// Meets checks if grade is at least as strong as required grade
+func (t GradeTaxonomy) Meets(grade string, required string) bool {
+	requiredLevel, _ := t.Level(required)
+	level, found := t.Level(grade)
+	return found && level >= requiredLevel
+}
+
+// getGradeTaxonomy returns grade taxonomy stored in ledger, empty taxonomy if admin didn't set any grade yet
+func getGradeTaxonomy(ctx contractapi.TransactionContextInterface) (*GradeTaxonomy, error) {
+	key, _ := objectKey(ctx, ConfigObject, "gradetaxonomy")
+	taxonomyAsBytes, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return nil, err
+	}
+
+	taxonomy := GradeTaxonomy{
+		ObjectType: "gradetaxonomy",
+		Grades:     []Grade{},
+	}
+	if taxonomyAsBytes != nil {
+		err = json.Unmarshal(taxonomyAsBytes, &taxonomy)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &taxonomy, nil
+}
+
+// getVerificationPolicy returns policy for given scope and target, nil if there is no policy
+func getVerificationPolicy(ctx contractapi.TransactionContextInterface, scope string, target string) (*VerificationPolicy, error) {
+	key, _ := ctx.GetStub().CreateCompositeKey(PolicyObject, []string{scope, target})
+	policyAsBytes, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return nil, err
+	}
+	if policyAsBytes == nil {
+		return nil, nil
+	}
+
+	var policy VerificationPolicy
+	err = json.Unmarshal(policyAsBytes, &policy)
+	if err != nil {
+		return nil, err
+	}
+	return &policy, nil
+}
+
+// checkGradePolicy returns error if grade is below minimum grade required for scope and target
+func checkGradePolicy(ctx contractapi.TransactionContextInterface, scope string, target string, grade string) error {
+	policy, err := getVerificationPolicy(ctx, scope, target)
+	if err != nil {
+		return err
+	}
+	if policy == nil {
+		return nil
+	}
+
+	taxonomy, err := getGradeTaxonomy(ctx)
+	if err != nil {
+		return err
+	}
+	if !taxonomy.Meets(grade, policy.MinGrade) {
+		return fmt.Errorf("grade %s is below grade %s required to verify %s %s", grade, policy.MinGrade, scope, target)
+	}
+	return nil
+}
+
+// policyCompliance checks if at least one of given verifier grades meets verification policy
+func policyCompliance(ctx contractapi.TransactionContextInterface, scope string, target string, grades []string) (PolicyCompliance, error) {
+	compliance := PolicyCompliance{}
+	policy, err := getVerificationPolicy(ctx, scope, target)
+	if err != nil {
+		return compliance, err
+	}
+	if policy == nil {
+		compliance.MeetsPolicy = len(grades) > 0
+		return compliance, nil
+	}
+	compliance.RequiredGrade = policy.MinGrade
+
+	taxonomy, err := getGradeTaxonomy(ctx)
+	if err != nil {
+		return compliance, err
+	}
+	for _, grade := range grades {
+		if taxonomy.Meets(grade, policy.MinGrade) {
+			compliance.MeetsPolicy = true
+			break
+		}
+	}
+	return compliance, nil
+}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// Default names of private data collections, see collections_config.json. Collections are stored on AKcessMSP peers only,
+// clients of every organization with bound identities read and write them through those peers, so collections are not
+// member only and chaincode checks who may access each entry
+const (
+	DefaultOTPCollection     = "akcessOTPCollection"
+	DefaultProfileCollection = "akcessProfileCollection"
+	DefaultShareCollection   = "akcessShareCollection"
+)
+
+// PrivateDataConfig names of private data collections sensitive fields are stored in
+type PrivateDataConfig struct {
+	ObjectType        string `json:"docType"`
+	OTPCollection     string `json:"otpCollection"`
+	ProfileCollection string `json:"profileCollection"`
+	ShareCollection   string `json:"shareCollection"`
+	UpdatedBy         string `json:"updatedBy"`
+}
+
+// ProfileFieldValue value of user profile field, only its commitment is stored in public state
+type ProfileFieldValue struct {
+	ObjectType   string `json:"docType"`
+	AkcessID     string `json:"akcessId"`
+	ProfileField string `json:"profileField"`
+	Value        string `json:"value"`
+	Salt         string `json:"salt"`
+}
+
+// ShareReceivers receivers of document share, only their salted hashes are stored in public state
+type ShareReceivers struct {
+	ObjectType string   `json:"docType"`
+	SharingID  string   `json:"sharingid"`
+	Receivers  []string `json:"receivers"`
+	Salt       string   `json:"salt"`
+}
+
+// SetPrivateDataCollections admin sets names of private data collections used for OTPs, profile values and share receivers
+func (u *UserContract) SetPrivateDataCollections(ctx contractapi.TransactionContextInterface, otpCollection string, profileCollection string, shareCollection string) Response {
+	response := Response{
+		TxID:    ctx.GetStub().GetTxID(),
+		Success: false,
+		Message: "",
+		Data:    nil,
+	}
+
+	invoker := invokerName(ctx)
+	if !IsAdmin(ctx) {
+		response.Message = fmt.Sprintf("Identity %s is not an admin, only admin can configure private data collections", invoker)
+		logger.Info(response.Message)
+		return response
+	}
+	if otpCollection == "" || profileCollection == "" || shareCollection == "" {
+		response.Message = fmt.Sprint("Collection names can't be empty")
+		logger.Info(response.Message)
+		return response
+	}
+
+	config := PrivateDataConfig{
+		ObjectType:        "privatedataconfig",
+		OTPCollection:     otpCollection,
+		ProfileCollection: profileCollection,
+		ShareCollection:   shareCollection,
+		UpdatedBy:         invoker,
+	}
+	configAsBytes, _ := json.Marshal(config)
+	key, _ := objectKey(ctx, ConfigObject, "privatedata")
+	err := ctx.GetStub().PutState(key, configAsBytes)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while saving private data config: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+
+	response.Success = true
+	response.Message = fmt.Sprint("Private data collections updated")
+	logger.Info(response.Message)
+	response.Data = config
+	return response
+}
+
+// GetPrivateDataCollections returns names of private data collections in use
+func (u *UserContract) GetPrivateDataCollections(ctx contractapi.TransactionContextInterface) Response {
+	response := Response{
+		TxID:    ctx.GetStub().GetTxID(),
+		Success: false,
+		Message: "",
+		Data:    nil,
+	}
+
+	config, err := getPrivateDataConfig(ctx)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while fetching private data config: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+
+	response.Success = true
+	response.Message = fmt.Sprint("Successfully fetched private data collections")
+	logger.Info(response.Message)
+	response.Data = config
+	return response
+}
+
+// getPrivateDataConfig returns private data collections set by admin, default collections if admin didn't set any
+func getPrivateDataConfig(ctx contractapi.TransactionContextInterface) (*PrivateDataConfig, error) {
+	key, _ := objectKey(ctx, ConfigObject, "privatedata")
+	configAsBytes, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return nil, err
+	}
+
+	config := PrivateDataConfig{
+		ObjectType:        "privatedataconfig",
+		OTPCollection:     DefaultOTPCollection,
+		ProfileCollection: DefaultProfileCollection,
+		ShareCollection:   DefaultShareCollection,
+	}
+	if configAsBytes != nil {
+		err = json.Unmarshal(configAsBytes, &config)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &config, nil
+}
+
+// getTransientValue returns value passed in transient data of the proposal under given key
+func getTransientValue(ctx contractapi.TransactionContextInterface, key string) (string, error) {
+	transient, err := ctx.GetStub().GetTransient()
+	if err != nil {
+		return "", err
+	}
+	value, found := transient[key]
+	if !found || len(value) == 0 {
+		return "", fmt.Errorf("%s must be passed in transient data", key)
+	}
+	return string(value), nil
+}
+
+// putPrivateObject writes object to private data collection under composite key of given type and attributes
+func putPrivateObject(ctx contractapi.TransactionContextInterface, collection string, objectType string, attributes []string, object interface{}) error {
+	key, err := ctx.GetStub().CreateCompositeKey(objectType, attributes)
+	if err != nil {
+		return err
+	}
+	objectAsBytes, err := json.Marshal(object)
+	if err != nil {
+		return err
+	}
+	return ctx.GetStub().PutPrivateData(collection, key, objectAsBytes)
+}
+
+// getShareReceivers reads receivers of document share from private data collection, nil if they don't exist
+func getShareReceivers(ctx contractapi.TransactionContextInterface, collection string, sharingid string) (*ShareReceivers, error) {
+	key, err := ctx.GetStub().CreateCompositeKey("sharereceivers", []string{sharingid})
+	if err != nil {
+		return nil, err
+	}
+	receiversAsBytes, err := ctx.GetStub().GetPrivateData(collection, key)
+	if err != nil || receiversAsBytes == nil {
+		return nil, err
+	}
+	var receivers ShareReceivers
+	err = json.Unmarshal(receiversAsBytes, &receivers)
+	if err != nil {
+		return nil, err
+	}
+	return &receivers, nil
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// LinkedDocRecord document linked to asset and transaction which linked it
+type LinkedDocRecord struct {
+	DocumentID string    `json:"documentId"`
+	TxID       string    `json:"txId"`
+	Timestamp  time.Time `json:"timestamp"`
+}
+
+// OwnershipRecord one period during which asset was owned by the same owners with the same shares
+type OwnershipRecord struct {
+	Owner         string            `json:"owner"`
+	Owners        []AssetOwner      `json:"owners"`
+	TxID          string            `json:"txId"` // tx which registered or transferred asset to owner
+	Timestamp     time.Time         `json:"timestamp"`
+	OfferID       string            `json:"offerId,omitempty"` // accepted transfer offer, empty for registration and direct transfers
+	Verifications []Verification    `json:"verifications"`     // verifications present when owner acquired asset
+	LinkedDocs    []LinkedDocRecord `json:"linkedDocs"`        // documents linked while asset was owned by owner
+	EndTxID       string            `json:"endTxId,omitempty"` // tx which ended ownership, empty for current owner
+	EndTimestamp  *time.Time        `json:"endTimestamp,omitempty"`
+}
+
+// AssetProvenance chain of owners of digital asset, oldest first, every change of co-owners or their shares starts new record
+type AssetProvenance struct {
+	AssetID      string            `json:"assetId"`
+	AssetType    string            `json:"assetType"`
+	CurrentOwner string            `json:"currentOwner"` // empty when asset was deleted
+	Owners       []OwnershipRecord `json:"owners"`
+}
+
+// GetAssetProvenance returns every owner or group of co-owners of asset with when and by which transaction asset changed hands,
+// verifications present at that time and documents each owner linked, built from history of asset key
+func (da *DigitalAssetContract) GetAssetProvenance(ctx contractapi.TransactionContextInterface, assetID string) Response {
+	response := Response{
+		TxID:    ctx.GetStub().GetTxID(),
+		Success: false,
+		Message: "",
+		Data:    nil,
+	}
+
+	states, err := getObjectHistory(ctx, AssetObject, assetID)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while fetching history of asset: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+	if len(states) == 0 {
+		response.Message = fmt.Sprintf("Digital asset with id %s has no history", assetID)
+		logger.Info(response.Message)
+		return response
+	}
+
+	provenance := AssetProvenance{
+		AssetID: assetID,
+		Owners:  []OwnershipRecord{},
+	}
+	var previous *DigitalAsset
+	for _, state := range states {
+		var current *DigitalAsset
+		if !state.IsDelete {
+			current = new(DigitalAsset)
+			err = json.Unmarshal(state.Value, current)
+			if err != nil {
+				response.Message = fmt.Sprintf("Error while reading state of asset written by tx %s: %s", state.TxID, err.Error())
+				logger.Error(response.Message)
+				return response
+			}
+		}
+		provenance.Owners = applyAssetState(provenance.Owners, previous, current, state)
+		if current != nil {
+			provenance.AssetType = current.AssetType
+		}
+		previous = current
+	}
+	if previous != nil {
+		provenance.CurrentOwner = previous.Owner
+	}
+
+	response.Success = true
+	response.Message = fmt.Sprintf("Successfully fetched %d owners of asset %s", len(provenance.Owners), assetID)
+	logger.Info(response.Message)
+	response.Data = provenance
+	return response
+}
+
+// applyAssetState updates ownership records with change from previous to current state of asset,
+// nil state means asset doesn't exist
+func applyAssetState(owners []OwnershipRecord, previous *DigitalAsset, current *DigitalAsset, state objectState) []OwnershipRecord {
+	end := func() {
+		if len(owners) > 0 && owners[len(owners)-1].EndTxID == "" {
+			owners[len(owners)-1].EndTxID = state.TxID
+			owners[len(owners)-1].EndTimestamp = &state.Timestamp
+		}
+	}
+	if current == nil {
+		end()
+		return owners
+	}
+
+	if previous == nil || !reflect.DeepEqual(previous.OwnerShares(), current.OwnerShares()) {
+		end()
+		record := OwnershipRecord{
+			Owner:         current.Owner,
+			Owners:        current.OwnerShares(),
+			TxID:          state.TxID,
+			Timestamp:     state.Timestamp,
+			Verifications: current.Verifications,
+			LinkedDocs:    []LinkedDocRecord{},
+		}
+		if record.Verifications == nil {
+			record.Verifications = []Verification{}
+		}
+		if previous != nil {
+			record.OfferID = previous.PendingTransfer
+		}
+		owners = append(owners, record)
+	}
+
+	// documents carried over from previous owner stay with the owner who linked them
+	var previousDocs []string
+	if previous != nil {
+		previousDocs = previous.LinkedDocs
+	}
+	for _, documentID := range current.LinkedDocs {
+		if _, found := Find(previousDocs, documentID); !found {
+			owners[len(owners)-1].LinkedDocs = append(owners[len(owners)-1].LinkedDocs, LinkedDocRecord{
+				DocumentID: documentID,
+				TxID:       state.TxID,
+				Timestamp:  state.Timestamp,
+			})
+		}
+	}
+	return owners
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+)
+
+// ownershipSummary describes ownership record by owners, transactions which started and ended it, offer and linked documents
+func ownershipSummary(record OwnershipRecord) string {
+	owners := ""
+	for _, owner := range record.Owners {
+		owners += fmt.Sprintf("%s:%d ", owner.AkcessID, owner.Share)
+	}
+	docs := []string{}
+	for _, doc := range record.LinkedDocs {
+		docs = append(docs, doc.DocumentID+"@"+doc.TxID)
+	}
+	return fmt.Sprintf("%sfrom %s offer %q until %q docs %v", owners, record.TxID, record.OfferID, record.EndTxID, docs)
+}
+
+func TestGetAssetProvenance(t *testing.T) {
+	l := newTestLedger(t)
+	l.initLedger()
+	for _, akcessID := range []string{"alice", "bank", "mallory"} {
+		expectResponse(t, new(UserContract).CreateUser(l.as("Org1MSP", akcessID, nil)), "")
+	}
+	expectResponse(t, new(DigitalAssetContract).RegisterAssetType(l.as("AdminMSP", "admin", nil), "vehicle", "", map[string]MetadataField{}, true), "")
+	registered := new(DigitalAssetContract).RegisterAsset(l.as("Org1MSP", "alice", nil), "vehicle", map[string]string{}, "car", "")
+	expectResponse(t, registered, "")
+	assetID := registered.TxID
+	expectResponse(t, new(DocContract).CreateDocWithHash(l.as("Org1MSP", "alice", nil), "doc1", "invoice", saltedHash("", "invoice"), "sha256"), "")
+	linked := new(DigitalAssetContract).LinkDocument(l.as("Org1MSP", "alice", nil), assetID, "doc1")
+	expectResponse(t, linked, "")
+
+	transferOffer := new(DigitalAssetContract).OfferAssetTransfer(l.as("Org1MSP", "alice", nil), assetID, "bank", "")
+	expectResponse(t, transferOffer, "")
+	transferred := new(DigitalAssetContract).AcceptAssetTransfer(l.as("Org1MSP", "bank", nil), transferOffer.TxID)
+	expectResponse(t, transferred, "")
+	shareOffer := new(DigitalAssetContract).OfferAssetShare(l.as("Org1MSP", "bank", nil), assetID, "mallory", FullShare/4, "")
+	expectResponse(t, shareOffer, "")
+	shared := new(DigitalAssetContract).AcceptAssetTransfer(l.as("Org1MSP", "mallory", nil), shareOffer.TxID)
+	expectResponse(t, shared, "")
+
+	alice := fmt.Sprintf("alice:%d from %s offer \"\" until %q docs [doc1@%s]", FullShare, assetID, transferred.TxID, linked.TxID)
+	bank := fmt.Sprintf("bank:%d from %s offer %q until %q docs []", FullShare, transferred.TxID, transferOffer.TxID, shared.TxID)
+	coOwners := fmt.Sprintf("bank:%d mallory:%d from %s offer %q until %%q docs []", FullShare*3/4, FullShare/4, shared.TxID, shareOffer.TxID)
+
+	tests := []struct {
+		name            string
+		setup           func() string // returns ID of tx which ended current ownership, if any
+		assetID         string
+		expectedOwner   string
+		expectedRecords func(endTxID string) []string
+		expectedError   string
+	}{
+		{
+			name:          "every owner with linked documents",
+			setup:         func() string { return "" },
+			assetID:       assetID,
+			expectedOwner: "bank",
+			expectedRecords: func(endTxID string) []string {
+				return []string{alice, bank, fmt.Sprintf(coOwners, endTxID)}
+			},
+		},
+		{
+			name:          "unknown asset",
+			setup:         func() string { return "" },
+			assetID:       "asset0",
+			expectedError: "Digital asset with id asset0 has no history",
+		},
+		{
+			name: "removed asset",
+			setup: func() string {
+				expectResponse(t, new(DigitalAssetContract).ApproveAssetAction(l.as("Org1MSP", "mallory", nil), assetID, AssetActionRemove, assetID), "")
+				removed := new(DigitalAssetContract).RemoveAsset(l.as("Org1MSP", "bank", nil), assetID)
+				expectResponse(t, removed, "")
+				return removed.TxID
+			},
+			assetID:       assetID,
+			expectedOwner: "",
+			expectedRecords: func(endTxID string) []string {
+				return []string{alice, bank, fmt.Sprintf(coOwners, endTxID)}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			endTxID := tt.setup()
+			response := new(DigitalAssetContract).GetAssetProvenance(l.as("Org1MSP", "reader", nil), tt.assetID)
+			expectResponse(t, response, tt.expectedError)
+			if tt.expectedError != "" {
+				return
+			}
+			provenance := response.Data.(AssetProvenance)
+			records := []string{}
+			for _, record := range provenance.Owners {
+				records = append(records, ownershipSummary(record))
+			}
+			if expected := tt.expectedRecords(endTxID); !equalStrings(records, expected) {
+				t.Fatalf("expected ownership records\n%v\ngot\n%v", expected, records)
+			}
+			if provenance.CurrentOwner != tt.expectedOwner || provenance.AssetType != "vehicle" {
+				t.Fatalf("expected vehicle currently owned by %q, got %+v", tt.expectedOwner, provenance)
+			}
+		})
+	}
+}
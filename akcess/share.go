@@ -0,0 +1,712 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// RevokeShare sender of share or owner of shared document revokes share, reshares made from it
+// lose access together with it
+func (d *DocContract) RevokeShare(ctx contractapi.TransactionContextInterface, sharingid string) Response {
+	response := Response{
+		TxID:    ctx.GetStub().GetTxID(),
+		Success: false,
+		Message: "",
+		Data:    nil,
+	}
+
+	invoker, err := resolveAkcessID(ctx)
+	if err != nil {
+		response.Message = err.Error()
+		logger.Info(response.Message)
+		return response
+	}
+	share, err := getShare(ctx, sharingid)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while fetching share from world state: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+	if share == nil {
+		response.Message = fmt.Sprintf("Share with id %s doesn't exist", sharingid)
+		logger.Info(response.Message)
+		return response
+	}
+	if share.Sender != invoker {
+		doc, err := getDocument(ctx, share.DocumentID)
+		if err != nil {
+			response.Message = fmt.Sprintf("Error while fetching doc from world state: %s", err.Error())
+			logger.Error(response.Message)
+			return response
+		}
+		if doc == nil || doc.AkcessID != invoker {
+			response.Message = fmt.Sprintf("Identity %s is neither sender of share %s nor owner of document %s", invoker, sharingid, share.DocumentID)
+			logger.Info(response.Message)
+			return response
+		}
+	}
+	if share.Status == ShareRevoked {
+		response.Message = fmt.Sprintf("Share %s is already revoked", sharingid)
+		logger.Info(response.Message)
+		return response
+	}
+
+	txTime, err := getTxTimestamp(ctx)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while getting tx timestamp: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+	share.Status = ShareRevoked
+	share.RevokedAt = &txTime
+	shareAsBytes, _ := json.Marshal(share)
+	err = putObject(ctx, DocShareObject, sharingid, shareAsBytes)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while revoking share: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+
+	response.Success = true
+	response.Message = fmt.Sprintf("Share %s revoked by %s", sharingid, invoker)
+	logger.Info(response.Message)
+	response.Data = share
+	return response
+}
+
+// AcknowledgeShare receiver acknowledges it received document share
+func (d *DocContract) AcknowledgeShare(ctx contractapi.TransactionContextInterface, sharingid string) Response {
+	return respondToShare(ctx, sharingid, ShareAcknowledged)
+}
+
+// DeclineShare receiver declines document share, it loses access to the document through the share
+// and can't acknowledge it afterwards
+func (d *DocContract) DeclineShare(ctx contractapi.TransactionContextInterface, sharingid string) Response {
+	return respondToShare(ctx, sharingid, ShareDeclined)
+}
+
+// CheckShareAccess checks whether receiver can access document through share at tx time,
+// called by off-chain document storage before it serves the file. Only owner of document, receiver
+// asked about or an admin can check access, so receivers of shares can't be probed by others
+func (d *DocContract) CheckShareAccess(ctx contractapi.TransactionContextInterface, sharingid string, receiver string) Response {
+	response := Response{
+		TxID:    ctx.GetStub().GetTxID(),
+		Success: false,
+		Message: "",
+		Data:    nil,
+	}
+
+	share, err := getShare(ctx, sharingid)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while fetching share from world state: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+	if share == nil {
+		response.Message = fmt.Sprintf("Share with id %s doesn't exist", sharingid)
+		logger.Info(response.Message)
+		return response
+	}
+	if !IsAdmin(ctx) {
+		invoker, err := resolveAkcessID(ctx)
+		if err != nil {
+			response.Message = err.Error()
+			logger.Info(response.Message)
+			return response
+		}
+		doc, err := getDocument(ctx, share.DocumentID)
+		if err != nil {
+			response.Message = fmt.Sprintf("Error while fetching doc from world state: %s", err.Error())
+			logger.Error(response.Message)
+			return response
+		}
+		if invoker != receiver && (doc == nil || doc.AkcessID != invoker) {
+			response.Message = fmt.Sprintf("Identity %s can't check access of %s, only owner of document, the receiver or an admin can", invoker, receiver)
+			logger.Info(response.Message)
+			return response
+		}
+	}
+	collections, err := getPrivateDataConfig(ctx)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while fetching private data config: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+	txTime, err := getTxTimestamp(ctx)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while getting tx timestamp: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+
+	access, err := shareAccess(ctx, collections.ShareCollection, share, receiver, txTime)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while checking share access: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+
+	response.Success = true
+	if access.Allowed {
+		response.Message = fmt.Sprintf("Receiver %s can access document %s through share %s", receiver, share.DocumentID, sharingid)
+	} else {
+		response.Message = fmt.Sprintf("Receiver %s can't access document %s through share %s: %s", receiver, share.DocumentID, sharingid, access.Reason)
+	}
+	logger.Info(response.Message)
+	response.Data = access
+	return response
+}
+
+// ReshareDoc receiver of share with reshare permission shares the document further. New share can't grant
+// permissions or validity the parent share doesn't have. Receivers and salt are passed in transient data as in ShareDoc
+func (d *DocContract) ReshareDoc(ctx contractapi.TransactionContextInterface, parentSharingID string, sharingid string, permissions []string, validFrom string, validUntil string) Response {
+	response := Response{
+		TxID:    ctx.GetStub().GetTxID(),
+		Success: false,
+		Message: "",
+		Data:    nil,
+	}
+
+	invoker, err := resolveAkcessID(ctx)
+	if err != nil {
+		response.Message = err.Error()
+		logger.Info(response.Message)
+		return response
+	}
+	parent, err := getShare(ctx, parentSharingID)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while fetching share from world state: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+	if parent == nil {
+		response.Message = fmt.Sprintf("Share with id %s doesn't exist", parentSharingID)
+		logger.Info(response.Message)
+		return response
+	}
+	collections, err := getPrivateDataConfig(ctx)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while fetching private data config: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+	txTime, err := getTxTimestamp(ctx)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while getting tx timestamp: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+
+	access, err := shareAccess(ctx, collections.ShareCollection, parent, invoker, txTime)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while checking share access: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+	if !access.Allowed {
+		response.Message = fmt.Sprintf("Identity %s can't access share %s: %s", invoker, parentSharingID, access.Reason)
+		logger.Info(response.Message)
+		return response
+	}
+	if !parent.HasPermission(SharePermissionReshare) {
+		response.Message = fmt.Sprintf("Share %s doesn't permit resharing", parentSharingID)
+		logger.Info(response.Message)
+		return response
+	}
+
+	share, err := newShare(ctx, sharingid, invoker, parent.DocumentID, permissions, validFrom, validUntil, txTime)
+	if err != nil {
+		response.Message = err.Error()
+		logger.Info(response.Message)
+		return response
+	}
+	for _, permission := range share.Permissions {
+		if !parent.HasPermission(permission) {
+			response.Message = fmt.Sprintf("Share %s doesn't grant %s permission, it can't be reshared with it", parentSharingID, permission)
+			logger.Info(response.Message)
+			return response
+		}
+	}
+	if share.ValidFrom.Before(parent.ValidFrom) {
+		share.ValidFrom = parent.ValidFrom
+	}
+	if !parent.ValidUntil.IsZero() && (share.ValidUntil.IsZero() || share.ValidUntil.After(parent.ValidUntil)) {
+		response.Message = fmt.Sprintf("Share %s is valid until %s, reshare can't be valid longer", parentSharingID, parent.ValidUntil.Format(time.RFC3339))
+		logger.Info(response.Message)
+		return response
+	}
+	share.ParentSharingID = parentSharingID
+
+	receivers, salt, err := getShareReceiversInput(ctx)
+	if err != nil {
+		response.Message = err.Error()
+		logger.Info(response.Message)
+		return response
+	}
+	err = saveShare(ctx, collections.ShareCollection, share, receivers, salt)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while resharing doc: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+
+	response.Success = true
+	response.Message = fmt.Sprintf("Document %s reshared from %s to %d receivers", share.DocumentID, invoker, len(receivers))
+	logger.Info(response.Message)
+	response.Data = share
+	return response
+}
+
+// GetLegacyShares returns IDs of shares made before receivers were moved to private data collection,
+// their receivers are still in public state until they are migrated with MigrateShareReceivers
+func (d *DocContract) GetLegacyShares(ctx contractapi.TransactionContextInterface) Response {
+	response := Response{
+		TxID:    ctx.GetStub().GetTxID(),
+		Success: false,
+		Message: "",
+		Data:    nil,
+	}
+
+	resultIterator, err := ctx.GetStub().GetQueryResult(`{"selector": {"docType": "docshare", "receivers": {"$exists": true}}}`)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while fetching query result: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+	defer resultIterator.Close()
+
+	result := []string{}
+	for resultIterator.HasNext() {
+		queryResponse, err := resultIterator.Next()
+		if err != nil {
+			response.Message = fmt.Sprintf("Error while iterating shares: %s", err.Error())
+			logger.Error(response.Message)
+			return response
+		}
+		var share DocumentShare
+		json.Unmarshal(queryResponse.Value, &share)
+		result = append(result, share.SharingID)
+	}
+
+	response.Success = true
+	response.Message = fmt.Sprintf("Found %d legacy shares", len(result))
+	logger.Info(response.Message)
+	response.Data = result
+	return response
+}
+
+// MigrateShareReceivers admin moves plaintext receivers of legacy shares to private data collection and removes them
+// from public state. Salt is passed in transient data, each share is salted with hash of it and its sharing ID.
+// Shares which have no plaintext receivers are skipped
+func (d *DocContract) MigrateShareReceivers(ctx contractapi.TransactionContextInterface, sharingIDs []string) Response {
+	response := Response{
+		TxID:    ctx.GetStub().GetTxID(),
+		Success: false,
+		Message: "",
+		Data:    nil,
+	}
+
+	invoker := invokerName(ctx)
+	if !IsAdmin(ctx) {
+		response.Message = fmt.Sprintf("Identity %s is not an admin, only admin can migrate share receivers", invoker)
+		logger.Info(response.Message)
+		return response
+	}
+	salt, err := getTransientValue(ctx, "salt")
+	if err != nil {
+		response.Message = err.Error()
+		logger.Info(response.Message)
+		return response
+	}
+	collections, err := getPrivateDataConfig(ctx)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while fetching private data config: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+
+	result := MigrationResult{
+		Migrated: []string{},
+		Skipped:  []string{},
+	}
+	for _, sharingid := range sharingIDs {
+		shareAsBytes, err := getObject(ctx, DocShareObject, sharingid)
+		if err != nil {
+			response.Message = fmt.Sprintf("Error while fetching share %s: %s", sharingid, err.Error())
+			logger.Error(response.Message)
+			return response
+		}
+		var legacy struct {
+			Receivers []string `json:"receivers"`
+		}
+		if shareAsBytes != nil {
+			json.Unmarshal(shareAsBytes, &legacy)
+		}
+		if len(legacy.Receivers) == 0 {
+			result.Skipped = append(result.Skipped, sharingid)
+			continue
+		}
+
+		var share DocumentShare
+		json.Unmarshal(shareAsBytes, &share)
+		share.ReceiverHashes = []string{}
+		err = saveShare(ctx, collections.ShareCollection, &share, legacy.Receivers, saltedHash(salt, sharingid))
+		if err != nil {
+			response.Message = fmt.Sprintf("Error while migrating receivers of share %s: %s", sharingid, err.Error())
+			logger.Error(response.Message)
+			return response
+		}
+		result.Migrated = append(result.Migrated, sharingid)
+	}
+
+	response.Success = true
+	response.Message = fmt.Sprintf("Migrated receivers of %d legacy shares", len(result.Migrated))
+	logger.Info(response.Message)
+	response.Data = result
+	return response
+}
+
+// EffectiveStatus status of share at given time, shares made before statuses were added are active
+func (s DocumentShare) EffectiveStatus(now time.Time) string {
+	if s.Status == ShareRevoked {
+		return ShareRevoked
+	}
+	if now.Before(s.ValidFrom) {
+		return ShareScheduled
+	}
+	if !s.ValidUntil.IsZero() && !now.Before(s.ValidUntil) {
+		return ShareExpired
+	}
+	return ShareActive
+}
+
+// EffectivePermissions permissions share grants, shares made before permissions were added grant view only
+func (s DocumentShare) EffectivePermissions() []string {
+	if len(s.Permissions) == 0 {
+		return []string{SharePermissionView}
+	}
+	return s.Permissions
+}
+
+// HasPermission checks share grants permission
+func (s DocumentShare) HasPermission(permission string) bool {
+	_, found := Find(s.EffectivePermissions(), permission)
+	return found
+}
+
+// respondToShare records acknowledgement of invoker as receiver of share
+func respondToShare(ctx contractapi.TransactionContextInterface, sharingid string, status string) Response {
+	response := Response{
+		TxID:    ctx.GetStub().GetTxID(),
+		Success: false,
+		Message: "",
+		Data:    nil,
+	}
+
+	invoker, err := resolveAkcessID(ctx)
+	if err != nil {
+		response.Message = err.Error()
+		logger.Info(response.Message)
+		return response
+	}
+	share, err := getShare(ctx, sharingid)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while fetching share from world state: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+	if share == nil {
+		response.Message = fmt.Sprintf("Share with id %s doesn't exist", sharingid)
+		logger.Info(response.Message)
+		return response
+	}
+	collections, err := getPrivateDataConfig(ctx)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while fetching private data config: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+	receivers, err := getShareReceivers(ctx, collections.ShareCollection, sharingid)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while fetching receivers from private data collection: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+	if receivers == nil {
+		response.Message = fmt.Sprintf("Receivers of share %s are not available on this peer", sharingid)
+		logger.Info(response.Message)
+		return response
+	}
+	receiverHash := saltedHash(receivers.Salt, invoker)
+	if _, found := Find(share.ReceiverHashes, receiverHash); !found {
+		response.Message = fmt.Sprintf("Identity %s is not receiver of share %s", invoker, sharingid)
+		logger.Info(response.Message)
+		return response
+	}
+	if previous, found := share.Responses[receiverHash]; found && previous.Status == ShareDeclined {
+		response.Message = fmt.Sprintf("Share %s is already declined by %s", sharingid, invoker)
+		logger.Info(response.Message)
+		return response
+	}
+
+	txTime, err := getTxTimestamp(ctx)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while getting tx timestamp: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+	if current := share.EffectiveStatus(txTime); current == ShareRevoked || current == ShareExpired {
+		response.Message = fmt.Sprintf("Share %s is %s", sharingid, current)
+		logger.Info(response.Message)
+		return response
+	}
+
+	if share.Responses == nil {
+		share.Responses = map[string]ShareResponse{}
+	}
+	share.Responses[receiverHash] = ShareResponse{
+		Status:      status,
+		RespondedAt: txTime,
+	}
+	shareAsBytes, _ := json.Marshal(share)
+	err = putObject(ctx, DocShareObject, sharingid, shareAsBytes)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while saving share response: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+
+	response.Success = true
+	response.Message = fmt.Sprintf("Share %s %s by %s", sharingid, status, invoker)
+	logger.Info(response.Message)
+	response.Data = share.Responses[receiverHash]
+	return response
+}
+
+// shareAccess checks receiver can access document through share at given time,
+// every share it was reshared from has to be in effect as well
+func shareAccess(ctx contractapi.TransactionContextInterface, collection string, share *DocumentShare, receiver string, now time.Time) (ShareAccess, error) {
+	access := ShareAccess{
+		SharingID:   share.SharingID,
+		DocumentID:  share.DocumentID,
+		Receiver:    receiver,
+		Allowed:     false,
+		Permissions: share.EffectivePermissions(),
+		ValidUntil:  share.ValidUntil,
+	}
+
+	if status := share.EffectiveStatus(now); status != ShareActive {
+		access.Reason = fmt.Sprintf("share is %s", status)
+		return access, nil
+	}
+	doc, err := getDocument(ctx, share.DocumentID)
+	if err != nil {
+		return access, err
+	}
+	if doc == nil {
+		access.Reason = "shared document doesn't exist"
+		return access, nil
+	}
+	if status := doc.EffectiveStatus(now); status != DocumentActive {
+		access.Reason = fmt.Sprintf("document is %s", status)
+		return access, nil
+	}
+	receivers, err := getShareReceivers(ctx, collection, share.SharingID)
+	if err != nil {
+		return access, err
+	}
+	if receivers == nil {
+		access.Reason = "receivers of share are not available on this peer"
+		return access, nil
+	}
+	receiverHash := saltedHash(receivers.Salt, receiver)
+	if _, found := Find(share.ReceiverHashes, receiverHash); !found {
+		access.Reason = "not a receiver of share"
+		return access, nil
+	}
+	if previous, found := share.Responses[receiverHash]; found && previous.Status == ShareDeclined {
+		access.Reason = "receiver declined share"
+		return access, nil
+	}
+
+	parentID := share.ParentSharingID
+	for parentID != "" {
+		parent, err := getShare(ctx, parentID)
+		if err != nil {
+			return access, err
+		}
+		if parent == nil {
+			access.Reason = fmt.Sprintf("parent share %s doesn't exist", parentID)
+			return access, nil
+		}
+		if status := parent.EffectiveStatus(now); status != ShareActive {
+			access.Reason = fmt.Sprintf("parent share %s is %s", parentID, status)
+			return access, nil
+		}
+		parentID = parent.ParentSharingID
+	}
+
+	access.Allowed = true
+	return access, nil
+}
+
+// checkSharePermission refuses receiver which holds no share of document granting permission and giving access at given time
+func checkSharePermission(ctx contractapi.TransactionContextInterface, documentid string, receiver string, permission string, now time.Time) error {
+	collections, err := getPrivateDataConfig(ctx)
+	if err != nil {
+		return fmt.Errorf("Error while fetching private data config: %s", err.Error())
+	}
+	// document ID is client supplied, marshalling escapes it so it can't change selector
+	queryAsBytes, _ := json.Marshal(map[string]interface{}{
+		"selector": map[string]string{"docType": DocShareObject, "documentID": documentid},
+	})
+	resultIterator, err := ctx.GetStub().GetQueryResult(string(queryAsBytes))
+	if err != nil {
+		return fmt.Errorf("Error while fetching shares of document: %s", err.Error())
+	}
+	defer resultIterator.Close()
+
+	for resultIterator.HasNext() {
+		queryResponse, err := resultIterator.Next()
+		if err != nil {
+			return fmt.Errorf("Error while iterating shares of document: %s", err.Error())
+		}
+		var share DocumentShare
+		json.Unmarshal(queryResponse.Value, &share)
+		if share.DocumentID != documentid || !share.HasPermission(permission) {
+			continue
+		}
+		access, err := shareAccess(ctx, collections.ShareCollection, &share, receiver, now)
+		if err != nil {
+			return fmt.Errorf("Error while checking share access: %s", err.Error())
+		}
+		if access.Allowed {
+			return nil
+		}
+	}
+	return fmt.Errorf("Identity %s holds no share of document %s with %s permission", receiver, documentid, permission)
+}
+
+// newShare builds share of document after checking share ID is free and permissions and validity window are valid.
+// Empty validFrom starts share at tx time, empty validUntil makes share valid until revoked
+func newShare(ctx contractapi.TransactionContextInterface, sharingid string, sender string, documentid string, permissions []string, validFrom string, validUntil string, txTime time.Time) (*DocumentShare, error) {
+	if sharingid == "" {
+		return nil, fmt.Errorf("Sharing ID can't be empty")
+	}
+	existing, err := getObject(ctx, DocShareObject, sharingid)
+	if err != nil {
+		return nil, fmt.Errorf("Error while fetching share from world state: %s", err.Error())
+	}
+	if existing != nil {
+		return nil, fmt.Errorf("Share with id %s already exist", sharingid)
+	}
+
+	granted := []string{}
+	for _, permission := range permissions {
+		if _, found := Find(sharePermissions, permission); !found {
+			return nil, fmt.Errorf("Permission %s is not supported, use %s, %s or %s", permission, SharePermissionView, SharePermissionVerify, SharePermissionReshare)
+		}
+		if _, found := Find(granted, permission); !found {
+			granted = append(granted, permission)
+		}
+	}
+	if len(granted) == 0 {
+		granted = append(granted, SharePermissionView)
+	}
+
+	share := DocumentShare{
+		ObjectType:     DocShareObject,
+		SharingID:      sharingid,
+		Sender:         sender,
+		ReceiverHashes: []string{},
+		DocumentID:     documentid,
+		Permissions:    granted,
+		ValidFrom:      txTime,
+		Status:         ShareActive,
+		Responses:      map[string]ShareResponse{},
+		CreatedAt:      txTime,
+	}
+	if validFrom != "" {
+		share.ValidFrom, err = time.Parse(time.RFC3339, validFrom)
+		if err != nil {
+			return nil, fmt.Errorf("Error while parsing date pass date in ISO format: %s", err.Error())
+		}
+	}
+	if validUntil != "" {
+		share.ValidUntil, err = time.Parse(time.RFC3339, validUntil)
+		if err != nil {
+			return nil, fmt.Errorf("Error while parsing date pass date in ISO format: %s", err.Error())
+		}
+		if !share.ValidUntil.After(share.ValidFrom) || !share.ValidUntil.After(txTime) {
+			return nil, fmt.Errorf("Share must be valid until a time after it starts and after current time")
+		}
+	}
+	return &share, nil
+}
+
+// getShareReceiversInput reads receivers and salt of share from transient data and checks receivers exist
+func getShareReceiversInput(ctx contractapi.TransactionContextInterface) ([]string, string, error) {
+	receiversAsJSON, err := getTransientValue(ctx, "receivers")
+	if err != nil {
+		return nil, "", err
+	}
+	var receivers []string
+	err = json.Unmarshal([]byte(receiversAsJSON), &receivers)
+	if err != nil {
+		return nil, "", fmt.Errorf("Receivers must be JSON array of AKcessIDs: %s", err.Error())
+	}
+	if len(receivers) == 0 {
+		return nil, "", fmt.Errorf("Share must have at least one receiver")
+	}
+	salt, err := getTransientValue(ctx, "salt")
+	if err != nil {
+		return nil, "", err
+	}
+	for _, receiver := range receivers {
+		user, err := getUser(ctx, receiver)
+		if err != nil {
+			return nil, "", fmt.Errorf("Error while fetching user from world state: %s", err.Error())
+		}
+		if user == nil {
+			return nil, "", fmt.Errorf("Receiver %s doesn't exist", receiver)
+		}
+	}
+	return receivers, salt, nil
+}
+
+// saveShare stores receivers of share in private data collection and share with their hashes in world state
+func saveShare(ctx contractapi.TransactionContextInterface, collection string, share *DocumentShare, receivers []string, salt string) error {
+	shareReceivers := ShareReceivers{
+		ObjectType: "sharereceivers",
+		SharingID:  share.SharingID,
+		Receivers:  receivers,
+		Salt:       salt,
+	}
+	err := putPrivateObject(ctx, collection, "sharereceivers", []string{share.SharingID}, shareReceivers)
+	if err != nil {
+		return err
+	}
+
+	for _, receiver := range receivers {
+		share.ReceiverHashes = append(share.ReceiverHashes, saltedHash(salt, receiver))
+	}
+	shareAsBytes, _ := json.Marshal(share)
+	return putObject(ctx, DocShareObject, share.SharingID, shareAsBytes)
+}
+
+// getShare reads document share, nil if it doesn't exist
+func getShare(ctx contractapi.TransactionContextInterface, sharingid string) (*DocumentShare, error) {
+	shareAsBytes, err := getObject(ctx, DocShareObject, sharingid)
+	if err != nil || shareAsBytes == nil {
+		return nil, err
+	}
+	var share DocumentShare
+	err = json.Unmarshal(shareAsBytes, &share)
+	if err != nil {
+		return nil, err
+	}
+	return &share, nil
+}
@@ -0,0 +1,272 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+func TestShareDoc(t *testing.T) {
+	tests := []struct {
+		name          string
+		sender        string
+		receivers     []string
+		permissions   []string
+		expectedError string
+	}{
+		{name: "owner shares document", sender: "bob", receivers: []string{"vera"}, permissions: []string{SharePermissionView}},
+		{name: "wrong owner", sender: "mallory", receivers: []string{"mallory"}, permissions: []string{SharePermissionView}, expectedError: "Identity mallory is not owner of document doc1"},
+		{name: "unknown receiver", sender: "bob", receivers: []string{"ghost"}, permissions: []string{SharePermissionView}, expectedError: "Receiver ghost doesn't exist"},
+		{name: "no receiver", sender: "bob", receivers: []string{}, permissions: []string{SharePermissionView}, expectedError: "Share must have at least one receiver"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := newDocLedger(t)
+			response := shareDoc(l, tt.sender, "share1", tt.receivers, tt.permissions)
+			expectResponse(t, response, tt.expectedError)
+			if tt.expectedError != "" {
+				return
+			}
+			shareAsBytes := l.stub.State[l.compositeKey(DocShareObject, "share1")]
+			var legacy struct {
+				Receivers []string `json:"receivers"`
+			}
+			json.Unmarshal(shareAsBytes, &legacy)
+			if len(legacy.Receivers) != 0 {
+				t.Fatalf("expected receivers not to be stored in public state, got %s", shareAsBytes)
+			}
+		})
+	}
+}
+
+func TestVerifyDocSharePermission(t *testing.T) {
+	expiry := testTime.AddDate(1, 0, 0).Format(time.RFC3339)
+	tests := []struct {
+		name          string
+		setup         func(l *testLedger)
+		documentid    string
+		verifier      string
+		expectedError string
+	}{
+		{
+			name:          "verifier without share",
+			setup:         func(l *testLedger) {},
+			verifier:      "vera",
+			expectedError: "Identity vera holds no share of document doc1 with verify permission",
+		},
+		{
+			name: "verifier with view only share",
+			setup: func(l *testLedger) {
+				expectResponse(l.t, shareDoc(l, "bob", "share1", []string{"vera"}, []string{SharePermissionView}), "")
+			},
+			verifier:      "vera",
+			expectedError: "Identity vera holds no share of document doc1 with verify permission",
+		},
+		{
+			name: "wrong verifier",
+			setup: func(l *testLedger) {
+				expectResponse(l.t, shareDoc(l, "bob", "share1", []string{"vera"}, []string{SharePermissionVerify}), "")
+			},
+			verifier:      "vic",
+			expectedError: "Identity vic holds no share of document doc1 with verify permission",
+		},
+		{
+			name: "verifier with revoked share",
+			setup: func(l *testLedger) {
+				expectResponse(l.t, shareDoc(l, "bob", "share1", []string{"vera"}, []string{SharePermissionVerify}), "")
+				expectResponse(l.t, new(DocContract).RevokeShare(l.as("Org1MSP", "bob", nil), "share1"), "")
+			},
+			verifier:      "vera",
+			expectedError: "Identity vera holds no share of document doc1 with verify permission",
+		},
+		{
+			name: "verifier with verify share",
+			setup: func(l *testLedger) {
+				expectResponse(l.t, shareDoc(l, "bob", "share1", []string{"vera"}, []string{SharePermissionVerify}), "")
+			},
+			verifier: "vera",
+		},
+		{
+			name: "document ID injecting query selector",
+			setup: func(l *testLedger) {
+				expectResponse(l.t, shareDoc(l, "bob", "share1", []string{"vera"}, []string{SharePermissionVerify}), "")
+				ctx := l.as("Org1MSP", "mallory", nil)
+				expectResponse(l.t, new(DocContract).CreateDocWithHash(ctx, `doc2", "documentID": "doc1`, "passport", saltedHash("", "forged"), "sha256"), "")
+			},
+			documentid:    `doc2", "documentID": "doc1`,
+			verifier:      "vera",
+			expectedError: `Identity vera holds no share of document doc2", "documentID": "doc1 with verify permission`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := newDocLedger(t)
+			tt.setup(l)
+			documentid := tt.documentid
+			if documentid == "" {
+				documentid = "doc1"
+			}
+			response := new(DocContract).VerifyDoc(l.as("Org1MSP", tt.verifier, verifierAttrs), documentid, expiry)
+			expectResponse(t, response, tt.expectedError)
+		})
+	}
+}
+
+func TestMigrateShareReceivers(t *testing.T) {
+	l := newDocLedger(t)
+	legacyShare := map[string]interface{}{
+		"docType":    "docshare",
+		"sharingid":  "share1",
+		"sender":     "bob",
+		"documentID": "doc1",
+		"receivers":  []string{"vera"},
+	}
+	l.put("share1", legacyShare)
+	expectResponse(t, shareDoc(l, "bob", "share2", []string{"vic"}, []string{SharePermissionView}), "")
+
+	legacy := new(DocContract).GetLegacyShares(l.as("Org1MSP", "bob", nil))
+	expectResponse(t, legacy, "")
+	if shares := legacy.Data.([]string); !equalStrings(shares, []string{"share1"}) {
+		t.Fatalf("expected legacy share share1, got %v", shares)
+	}
+
+	tests := []struct {
+		name             string
+		mspID            string
+		salt             string
+		expectedMigrated []string
+		expectedSkipped  []string
+		expectedError    string
+	}{
+		{name: "non admin can't migrate", mspID: "Org1MSP", salt: "salt", expectedError: "only admin can migrate share receivers"},
+		{name: "salt is required", mspID: "AdminMSP", expectedError: "salt"},
+		{name: "admin migrates legacy shares", mspID: "AdminMSP", salt: "salt", expectedMigrated: []string{"share1"}, expectedSkipped: []string{"share2", "share3"}},
+		{name: "migrated share is skipped", mspID: "AdminMSP", salt: "salt", expectedMigrated: []string{}, expectedSkipped: []string{"share1", "share2", "share3"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := l.as(tt.mspID, "admin", nil)
+			if tt.salt != "" {
+				l.stub.SetTransient(map[string][]byte{"salt": []byte(tt.salt)})
+			}
+			response := new(DocContract).MigrateShareReceivers(ctx, []string{"share1", "share2", "share3"})
+			l.stub.TransientMap = nil
+			expectResponse(t, response, tt.expectedError)
+			if tt.expectedError != "" {
+				return
+			}
+			result := response.Data.(MigrationResult)
+			if !equalStrings(result.Migrated, tt.expectedMigrated) || !equalStrings(result.Skipped, tt.expectedSkipped) {
+				t.Fatalf("expected migrated %v and skipped %v, got %+v", tt.expectedMigrated, tt.expectedSkipped, result)
+			}
+		})
+	}
+
+	if l.stub.State["share1"] != nil {
+		t.Fatalf("expected legacy key of share1 to be deleted")
+	}
+	legacy = new(DocContract).GetLegacyShares(l.as("Org1MSP", "bob", nil))
+	if shares := legacy.Data.([]string); len(shares) != 0 {
+		t.Fatalf("expected no legacy shares left, got %v", shares)
+	}
+	ctx := l.as("Org1MSP", "vera", nil)
+	expectErr(t, checkSharePermission(ctx, "doc1", "vera", SharePermissionView, l.now()), "")
+	expectErr(t, checkSharePermission(ctx, "doc1", "vera", SharePermissionVerify, l.now()), "holds no share of document doc1 with verify permission")
+	expectErr(t, checkSharePermission(ctx, "doc1", "mallory", SharePermissionView, l.now()), "holds no share of document doc1 with view permission")
+}
+
+func TestSendDoc(t *testing.T) {
+	expiry := testTime.AddDate(1, 0, 0).Format(time.RFC3339)
+	tests := []struct {
+		name            string
+		setup           func(l *testLedger)
+		sender          string
+		publicReceivers []string
+		expectedError   string
+	}{
+		{name: "receivers passed in transient data", setup: func(l *testLedger) {}, sender: "bob"},
+		{name: "receivers passed as arguments", setup: func(l *testLedger) {}, sender: "bob", publicReceivers: []string{"vera"}, expectedError: "leave receivers empty and pass them in transient data"},
+		{name: "wrong owner", setup: func(l *testLedger) {}, sender: "mallory", expectedError: "Identity mallory is not owner of document doc1"},
+		{
+			name: "revoked document",
+			setup: func(l *testLedger) {
+				expectResponse(l.t, new(DocContract).RevokeDocument(l.as("Org1MSP", "bob", nil), "doc1", "lost"), "")
+			},
+			sender:        "bob",
+			expectedError: "Document doc1 is revoked",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := newDocLedger(t)
+			tt.setup(l)
+			ctx := l.as("Org1MSP", tt.sender, nil)
+			l.stub.SetTransient(map[string][]byte{"receivers": []byte(`["vera"]`), "salt": []byte("salt")})
+			response := new(DocContract).SendDoc(ctx, "share1", tt.publicReceivers, "doc1")
+			l.stub.TransientMap = nil
+			expectResponse(t, response, tt.expectedError)
+			if tt.expectedError != "" {
+				return
+			}
+			expectResponse(t, new(DocContract).VerifyDoc(l.as("Org1MSP", "vera", verifierAttrs), "doc1", expiry), "")
+		})
+	}
+}
+
+func TestCheckShareAccess(t *testing.T) {
+	tests := []struct {
+		name            string
+		invoker         func(l *testLedger) contractapi.TransactionContextInterface
+		receiver        string
+		expectedAllowed bool
+		expectedError   string
+	}{
+		{
+			name:            "owner checks receiver",
+			invoker:         func(l *testLedger) contractapi.TransactionContextInterface { return l.as("Org1MSP", "bob", nil) },
+			receiver:        "vera",
+			expectedAllowed: true,
+		},
+		{
+			name:            "receiver checks itself",
+			invoker:         func(l *testLedger) contractapi.TransactionContextInterface { return l.as("Org1MSP", "vera", nil) },
+			receiver:        "vera",
+			expectedAllowed: true,
+		},
+		{
+			name:     "admin checks other identity",
+			invoker:  func(l *testLedger) contractapi.TransactionContextInterface { return l.as("AdminMSP", "admin", nil) },
+			receiver: "mallory",
+		},
+		{
+			name:          "other receiver probes receiver",
+			invoker:       func(l *testLedger) contractapi.TransactionContextInterface { return l.as("Org1MSP", "mallory", nil) },
+			receiver:      "vera",
+			expectedError: "Identity mallory can't check access of vera, only owner of document, the receiver or an admin can",
+		},
+		{
+			name:          "unbound identity",
+			invoker:       func(l *testLedger) contractapi.TransactionContextInterface { return l.as("Org2MSP", "vera", nil) },
+			receiver:      "vera",
+			expectedError: "Identity Org2MSP::vera is not bound to any AKcessID",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := newDocLedger(t)
+			expectResponse(t, shareDoc(l, "bob", "share1", []string{"vera"}, []string{SharePermissionView}), "")
+			response := new(DocContract).CheckShareAccess(tt.invoker(l), "share1", tt.receiver)
+			expectResponse(t, response, tt.expectedError)
+			if tt.expectedError == "" && response.Data.(ShareAccess).Allowed != tt.expectedAllowed {
+				t.Fatalf("expected access allowed %t, got %+v", tt.expectedAllowed, response.Data)
+			}
+		})
+	}
+}
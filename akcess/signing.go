@@ -0,0 +1,415 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// DefineSigningWorkflow owner defines who has to sign document, in which order, until when and how many
+// signatures complete it. Pass quorum 0 to require all signers. Workflow can be defined while document
+// is a draft or after previous workflow expired
+func (d *DocContract) DefineSigningWorkflow(ctx contractapi.TransactionContextInterface, documentid string, signers []string, order string, deadline string, quorum int) Response {
+	response := Response{
+		TxID:    ctx.GetStub().GetTxID(),
+		Success: false,
+		Message: "",
+		Data:    nil,
+	}
+
+	invoker, err := resolveAkcessID(ctx)
+	if err != nil {
+		response.Message = err.Error()
+		logger.Info(response.Message)
+		return response
+	}
+	doc, err := getDocument(ctx, documentid)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while fetching doc from world state: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+	if doc == nil {
+		response.Message = fmt.Sprintf("Document with id %s doesn't exist", documentid)
+		logger.Info(response.Message)
+		return response
+	}
+	if doc.AkcessID != invoker {
+		response.Message = fmt.Sprintf("Identity %s is not owner of document %s", invoker, documentid)
+		logger.Info(response.Message)
+		return response
+	}
+
+	txTime, err := getTxTimestamp(ctx)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while getting tx timestamp: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+	if status := doc.EffectiveSigningStatus(txTime); status != SigningDraft && status != SigningExpired {
+		response.Message = fmt.Sprintf("Document %s is %s, signing workflow can't be changed", documentid, status)
+		logger.Info(response.Message)
+		return response
+	}
+
+	if len(signers) == 0 {
+		response.Message = fmt.Sprint("Signing workflow needs at least one signer")
+		logger.Info(response.Message)
+		return response
+	}
+	for i, signer := range signers {
+		if _, duplicate := Find(signers[:i], signer); duplicate {
+			response.Message = fmt.Sprintf("Signer %s is listed more than once", signer)
+			logger.Info(response.Message)
+			return response
+		}
+		userAsBytes, err := getObject(ctx, UserObject, signer)
+		if err != nil {
+			response.Message = fmt.Sprintf("Error while fetching user from world state: %s", err.Error())
+			logger.Error(response.Message)
+			return response
+		}
+		if userAsBytes == nil {
+			response.Message = fmt.Sprintf("Signer %s is not a registered user", signer)
+			logger.Info(response.Message)
+			return response
+		}
+	}
+	if order != SigningSequential && order != SigningParallel {
+		response.Message = fmt.Sprintf("Invalid signing order %s, use sequential or parallel", order)
+		logger.Info(response.Message)
+		return response
+	}
+	if quorum < 0 || quorum > len(signers) {
+		response.Message = fmt.Sprintf("Quorum must be between 0 and number of signers %d", len(signers))
+		logger.Info(response.Message)
+		return response
+	}
+	deadlineTime, err := time.Parse(time.RFC3339, deadline)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while parsing deadline pass date in ISO format: %s", err.Error())
+		logger.Info(response.Message)
+		return response
+	}
+	if !deadlineTime.After(txTime) {
+		response.Message = fmt.Sprint("Deadline must be in the future")
+		logger.Info(response.Message)
+		return response
+	}
+
+	doc.Workflow = &SigningWorkflow{
+		Signers:   signers,
+		Order:     order,
+		Deadline:  deadlineTime,
+		Quorum:    quorum,
+		DefinedAt: txTime,
+	}
+	doc.SigningStatus = SigningDraft
+	docAsBytes, _ := json.Marshal(doc)
+	err = putObject(ctx, DocumentObject, documentid, docAsBytes)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while saving signing workflow: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+
+	response.Success = true
+	response.Message = fmt.Sprintf("Signing workflow of document %s defined with %d signers", documentid, len(signers))
+	logger.Info(response.Message)
+	response.Data = doc.Workflow
+	return response
+}
+
+// StartSigning owner starts signing workflow of document, signers sign its current version
+func (d *DocContract) StartSigning(ctx contractapi.TransactionContextInterface, documentid string) Response {
+	response := Response{
+		TxID:    ctx.GetStub().GetTxID(),
+		Success: false,
+		Message: "",
+		Data:    nil,
+	}
+
+	invoker, err := resolveAkcessID(ctx)
+	if err != nil {
+		response.Message = err.Error()
+		logger.Info(response.Message)
+		return response
+	}
+	doc, err := getDocument(ctx, documentid)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while fetching doc from world state: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+	if doc == nil {
+		response.Message = fmt.Sprintf("Document with id %s doesn't exist", documentid)
+		logger.Info(response.Message)
+		return response
+	}
+	if doc.AkcessID != invoker {
+		response.Message = fmt.Sprintf("Identity %s is not owner of document %s", invoker, documentid)
+		logger.Info(response.Message)
+		return response
+	}
+
+	txTime, err := getTxTimestamp(ctx)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while getting tx timestamp: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+	if status := doc.EffectiveSigningStatus(txTime); status != SigningDraft {
+		response.Message = fmt.Sprintf("Document %s is %s, only draft can be sent for signing", documentid, status)
+		logger.Info(response.Message)
+		return response
+	}
+	if doc.Workflow == nil {
+		response.Message = fmt.Sprintf("Document %s has no signing workflow, define it first", documentid)
+		logger.Info(response.Message)
+		return response
+	}
+	if !doc.Workflow.Deadline.After(txTime) {
+		response.Message = fmt.Sprintf("Deadline of signing workflow of document %s has passed, define it again", documentid)
+		logger.Info(response.Message)
+		return response
+	}
+	latest, found := doc.LatestVersion()
+	if !found {
+		response.Message = fmt.Sprintf("Document %s has no version to sign", documentid)
+		logger.Info(response.Message)
+		return response
+	}
+
+	doc.Workflow.DocumentVersion = latest.Version
+	doc.Workflow.StartedAt = &txTime
+	doc.SigningStatus = SigningAwaitingSignatures
+	docAsBytes, _ := json.Marshal(doc)
+	err = putObject(ctx, DocumentObject, documentid, docAsBytes)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while starting signing workflow: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+
+	response.Success = true
+	response.Message = fmt.Sprintf("Version %d of document %s is awaiting signatures", latest.Version, documentid)
+	logger.Info(response.Message)
+	response.Data = signingState(*doc, txTime)
+	return response
+}
+
+// GetSigningStatus returns signing status of document with signers who signed and who can sign now
+func (d *DocContract) GetSigningStatus(ctx contractapi.TransactionContextInterface, documentid string) Response {
+	response := Response{
+		TxID:    ctx.GetStub().GetTxID(),
+		Success: false,
+		Message: "",
+		Data:    nil,
+	}
+
+	doc, err := getDocument(ctx, documentid)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while fetching doc from world state: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+	if doc == nil {
+		response.Message = fmt.Sprintf("Document with id %s doesn't exist", documentid)
+		logger.Info(response.Message)
+		return response
+	}
+
+	txTime, err := getTxTimestamp(ctx)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while getting tx timestamp: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+
+	response.Success = true
+	response.Message = fmt.Sprintf("Successfully fetched signing status of document %s", documentid)
+	logger.Info(response.Message)
+	response.Data = signingState(*doc, txTime)
+	return response
+}
+
+// GetPendingSignatures returns documents waiting for signature of invoker. In sequential
+// workflows document is returned only when it is invoker's turn to sign
+func (d *DocContract) GetPendingSignatures(ctx contractapi.TransactionContextInterface) Response {
+	response := Response{
+		TxID:    ctx.GetStub().GetTxID(),
+		Success: false,
+		Message: "",
+		Data:    nil,
+	}
+
+	invoker, err := resolveAkcessID(ctx)
+	if err != nil {
+		response.Message = err.Error()
+		logger.Info(response.Message)
+		return response
+	}
+	txTime, err := getTxTimestamp(ctx)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while getting tx timestamp: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+
+	docs, err := queryDocuments(ctx, fmt.Sprintf(`{
+		"selector": {
+		   "docType": "document",
+		   "signingStatus": "%s",
+		   "workflow.signers": {
+			  "$elemMatch": {
+				 "$eq": "%s"
+			  }
+		   }
+		}
+	 }`, SigningAwaitingSignatures, invoker))
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while fetching documents: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+
+	result := []PendingSignature{}
+	for _, doc := range docs {
+		if doc.EffectiveSigningStatus(txTime) != SigningAwaitingSignatures || doc.EffectiveStatus(txTime) != DocumentActive {
+			continue
+		}
+		_, pending := doc.WorkflowSigners()
+		if _, found := Find(pending, invoker); !found {
+			continue
+		}
+		pendingSignature := PendingSignature{
+			DocumentID:      doc.DocumentID,
+			Owner:           doc.AkcessID,
+			DocumentVersion: doc.Workflow.DocumentVersion,
+			Deadline:        doc.Workflow.Deadline,
+		}
+		if doc.Workflow.DocumentVersion >= 1 && doc.Workflow.DocumentVersion <= len(doc.Versions) {
+			pendingSignature.Hash = doc.Versions[doc.Workflow.DocumentVersion-1].Hash
+		}
+		result = append(result, pendingSignature)
+	}
+
+	response.Success = true
+	response.Message = fmt.Sprintf("Successfully fetched %d documents waiting for signature of %s", len(result), invoker)
+	logger.Info(response.Message)
+	response.Data = result
+	return response
+}
+
+// Required returns number of signatures which complete workflow
+func (w SigningWorkflow) Required() int {
+	if w.Quorum > 0 {
+		return w.Quorum
+	}
+	return len(w.Signers)
+}
+
+// EffectiveSigningStatus returns signing status of document at given time. Documents stored before
+// signing workflows were introduced are drafts and workflows past their deadline are expired
+func (d Document) EffectiveSigningStatus(now time.Time) string {
+	if d.SigningStatus == "" {
+		return SigningDraft
+	}
+	if d.SigningStatus == SigningAwaitingSignatures && d.Workflow != nil && now.After(d.Workflow.Deadline) {
+		return SigningExpired
+	}
+	return d.SigningStatus
+}
+
+// WorkflowSigners returns workflow signers who signed the version being signed and signers who can sign next
+func (d Document) WorkflowSigners() ([]string, []string) {
+	signed := []string{}
+	pending := []string{}
+	if d.Workflow == nil {
+		return signed, pending
+	}
+	for _, signer := range d.Workflow.Signers {
+		if d.HasSigned(signer, d.Workflow.DocumentVersion) {
+			signed = append(signed, signer)
+			continue
+		}
+		if d.Workflow.Order == SigningSequential && len(pending) > 0 {
+			continue
+		}
+		pending = append(pending, signer)
+	}
+	return signed, pending
+}
+
+// HasSigned checks if signer signed given version of document
+func (d Document) HasSigned(signer string, version int) bool {
+	for _, signature := range d.Signature {
+		if signature.AkcessID == signer && signature.DocumentVersion == version {
+			return true
+		}
+	}
+	return false
+}
+
+// checkCanSign returns error if signer can't sign document at given time under its signing workflow
+func checkCanSign(doc Document, signer string, now time.Time) error {
+	if err := checkDocumentActive(&doc, now); err != nil {
+		return err
+	}
+	status := doc.EffectiveSigningStatus(now)
+	if status == SigningDraft {
+		return fmt.Errorf("Document %s is a draft, its owner has to start signing workflow first", doc.DocumentID)
+	}
+	if status != SigningAwaitingSignatures {
+		return fmt.Errorf("Document %s is %s, it can't be signed anymore", doc.DocumentID, status)
+	}
+	if _, found := Find(doc.Workflow.Signers, signer); !found {
+		return fmt.Errorf("%s is not a signer of document %s", signer, doc.DocumentID)
+	}
+	signed, pending := doc.WorkflowSigners()
+	if _, found := Find(signed, signer); found {
+		return fmt.Errorf("%s already signed version %d of document %s", signer, doc.Workflow.DocumentVersion, doc.DocumentID)
+	}
+	if _, found := Find(pending, signer); !found {
+		return fmt.Errorf("It is not %s's turn to sign document %s, waiting for %v", signer, doc.DocumentID, pending)
+	}
+	return nil
+}
+
+// checkCanSignWithoutWorkflow returns error if signer can't sign current version of document which has no signing workflow.
+// Such document can be signed by its owner and by receivers of its shares, each of them once per version
+func checkCanSignWithoutWorkflow(ctx contractapi.TransactionContextInterface, doc Document, signer string, now time.Time) error {
+	if err := checkDocumentActive(&doc, now); err != nil {
+		return err
+	}
+	latest, found := doc.LatestVersion()
+	if !found {
+		return fmt.Errorf("Document %s has no version to sign", doc.DocumentID)
+	}
+	if signer != doc.AkcessID {
+		if err := checkSharePermission(ctx, doc.DocumentID, signer, SharePermissionView, now); err != nil {
+			return err
+		}
+	}
+	if doc.HasSigned(signer, latest.Version) {
+		return fmt.Errorf("%s already signed version %d of document %s", signer, latest.Version, doc.DocumentID)
+	}
+	return nil
+}
+
+// signingState returns signing status of document at given time
+func signingState(doc Document, now time.Time) SigningState {
+	state := SigningState{
+		DocumentID:     doc.DocumentID,
+		DocumentStatus: doc.EffectiveStatus(now),
+		Status:         doc.EffectiveSigningStatus(now),
+		Workflow:       doc.Workflow,
+	}
+	state.Signed, state.Pending = doc.WorkflowSigners()
+	if state.Status != SigningAwaitingSignatures {
+		state.Pending = []string{}
+	}
+	return state
+}
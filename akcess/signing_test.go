@@ -0,0 +1,242 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"testing"
+	"time"
+)
+
+// newSigningLedger ledger of newDocLedger where bob, vera and vic registered signing keys
+func newSigningLedger(t *testing.T) (*testLedger, map[string]*ecdsa.PrivateKey) {
+	l := newDocLedger(t)
+	keys := map[string]*ecdsa.PrivateKey{}
+	for _, akcessID := range []string{"bob", "vera", "vic"} {
+		keys[akcessID], _ = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		response := new(UserContract).RegisterSigningKey(l.as("Org1MSP", akcessID, nil), "key1", KeyAlgorithmECDSAP256, publicKeyPEM(t, &keys[akcessID].PublicKey))
+		expectResponse(t, response, "")
+	}
+	return l, keys
+}
+
+// defineWorkflow owner bob defines signing workflow of doc1 which has to be signed within an hour
+func defineWorkflow(l *testLedger, signers []string, order string, quorum int) Response {
+	l.t.Helper()
+	ctx := l.as("Org1MSP", "bob", nil)
+	return new(DocContract).DefineSigningWorkflow(ctx, "doc1", signers, order, l.now().Add(time.Hour).Format(time.RFC3339), quorum)
+}
+
+// signDoc signer signs hash of first version of doc1 with OTP issued to them just before
+func signDoc(l *testLedger, keys map[string]*ecdsa.PrivateKey, signer string) Response {
+	l.t.Helper()
+	ctx := l.as("OTPMSP", "issuer", nil)
+	l.stub.SetTransient(map[string][]byte{"otp": []byte("123456"), "salt": []byte("salt")})
+	expectResponse(l.t, new(DocContract).IssueOTPChallenge(ctx, "doc1", signer, 60), "")
+
+	ctx = l.as("Org1MSP", signer, nil)
+	l.stub.SetTransient(map[string][]byte{"otp": []byte("123456")})
+	key, found := keys[signer]
+	if !found {
+		key = keys["bob"]
+	}
+	signature := signECDSA(l.t, key, saltedHash("", "content"))
+	response := new(DocContract).SignDoc(ctx, "doc1", signature, l.now().Format(time.RFC3339))
+	l.stub.TransientMap = nil
+	return response
+}
+
+func TestDefineSigningWorkflow(t *testing.T) {
+	tests := []struct {
+		name          string
+		owner         string
+		signers       []string
+		order         string
+		quorum        int
+		deadline      time.Duration
+		expectedError string
+	}{
+		{name: "owner defines workflow", owner: "bob", signers: []string{"vera", "vic"}, order: SigningSequential, deadline: time.Hour},
+		{name: "wrong owner", owner: "mallory", signers: []string{"vera"}, order: SigningSequential, deadline: time.Hour, expectedError: "Identity mallory is not owner of document doc1"},
+		{name: "no signer", owner: "bob", signers: []string{}, order: SigningSequential, deadline: time.Hour, expectedError: "Signing workflow needs at least one signer"},
+		{name: "duplicate signer", owner: "bob", signers: []string{"vera", "vera"}, order: SigningSequential, deadline: time.Hour, expectedError: "Signer vera is listed more than once"},
+		{name: "unregistered signer", owner: "bob", signers: []string{"ghost"}, order: SigningSequential, deadline: time.Hour, expectedError: "Signer ghost is not a registered user"},
+		{name: "unknown order", owner: "bob", signers: []string{"vera"}, order: "random", deadline: time.Hour, expectedError: "Invalid signing order random"},
+		{name: "quorum over number of signers", owner: "bob", signers: []string{"vera"}, order: SigningParallel, quorum: 2, deadline: time.Hour, expectedError: "Quorum must be between 0 and number of signers 1"},
+		{name: "deadline in the past", owner: "bob", signers: []string{"vera"}, order: SigningParallel, deadline: -time.Hour, expectedError: "Deadline must be in the future"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := newDocLedger(t)
+			ctx := l.as("Org1MSP", tt.owner, nil)
+			deadline := l.now().Add(tt.deadline).Format(time.RFC3339)
+			response := new(DocContract).DefineSigningWorkflow(ctx, "doc1", tt.signers, tt.order, deadline, tt.quorum)
+			expectResponse(t, response, tt.expectedError)
+		})
+	}
+}
+
+func TestStartSigning(t *testing.T) {
+	tests := []struct {
+		name          string
+		setup         func(l *testLedger)
+		owner         string
+		expectedError string
+	}{
+		{
+			name:          "document without workflow",
+			setup:         func(l *testLedger) {},
+			owner:         "bob",
+			expectedError: "Document doc1 has no signing workflow, define it first",
+		},
+		{
+			name:          "wrong owner",
+			setup:         func(l *testLedger) { expectResponse(l.t, defineWorkflow(l, []string{"vera"}, SigningParallel, 0), "") },
+			owner:         "mallory",
+			expectedError: "Identity mallory is not owner of document doc1",
+		},
+		{
+			name: "workflow already started",
+			setup: func(l *testLedger) {
+				expectResponse(l.t, defineWorkflow(l, []string{"vera"}, SigningParallel, 0), "")
+				expectResponse(l.t, new(DocContract).StartSigning(l.as("Org1MSP", "bob", nil), "doc1"), "")
+			},
+			owner:         "bob",
+			expectedError: "Document doc1 is awaitingsignatures, only draft can be sent for signing",
+		},
+		{
+			name:  "owner starts workflow",
+			setup: func(l *testLedger) { expectResponse(l.t, defineWorkflow(l, []string{"vera"}, SigningParallel, 0), "") },
+			owner: "bob",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := newDocLedger(t)
+			tt.setup(l)
+			response := new(DocContract).StartSigning(l.as("Org1MSP", tt.owner, nil), "doc1")
+			expectResponse(t, response, tt.expectedError)
+			if tt.expectedError != "" {
+				return
+			}
+			state := response.Data.(SigningState)
+			if state.Status != SigningAwaitingSignatures || state.Workflow.DocumentVersion != 1 {
+				t.Fatalf("expected version 1 awaiting signatures, got %+v", state)
+			}
+			expectResponse(t, defineWorkflow(l, []string{"vic"}, SigningParallel, 0), "signing workflow can't be changed")
+		})
+	}
+}
+
+func TestSignDocWorkflow(t *testing.T) {
+	start := func(l *testLedger) {
+		expectResponse(l.t, new(DocContract).StartSigning(l.as("Org1MSP", "bob", nil), "doc1"), "")
+	}
+	tests := []struct {
+		name           string
+		setup          func(l *testLedger, keys map[string]*ecdsa.PrivateKey)
+		signer         string
+		expectedStatus string
+		expectedError  string
+	}{
+		{
+			name: "listed signer before signing started",
+			setup: func(l *testLedger, keys map[string]*ecdsa.PrivateKey) {
+				expectResponse(l.t, defineWorkflow(l, []string{"vera"}, SigningSequential, 0), "")
+			},
+			signer:        "vera",
+			expectedError: "Document doc1 is a draft, its owner has to start signing workflow first",
+		},
+		{
+			name: "signer not listed in workflow",
+			setup: func(l *testLedger, keys map[string]*ecdsa.PrivateKey) {
+				expectResponse(l.t, defineWorkflow(l, []string{"vera"}, SigningSequential, 0), "")
+				start(l)
+			},
+			signer:        "mallory",
+			expectedError: "mallory is not a signer of document doc1",
+		},
+		{
+			name: "signer out of sequential order",
+			setup: func(l *testLedger, keys map[string]*ecdsa.PrivateKey) {
+				expectResponse(l.t, defineWorkflow(l, []string{"vera", "vic"}, SigningSequential, 0), "")
+				start(l)
+			},
+			signer:        "vic",
+			expectedError: "It is not vic's turn to sign document doc1, waiting for [vera]",
+		},
+		{
+			name: "first sequential signer",
+			setup: func(l *testLedger, keys map[string]*ecdsa.PrivateKey) {
+				expectResponse(l.t, defineWorkflow(l, []string{"vera", "vic"}, SigningSequential, 0), "")
+				start(l)
+			},
+			signer:         "vera",
+			expectedStatus: SigningAwaitingSignatures,
+		},
+		{
+			name: "next sequential signer completes workflow",
+			setup: func(l *testLedger, keys map[string]*ecdsa.PrivateKey) {
+				expectResponse(l.t, defineWorkflow(l, []string{"vera", "vic"}, SigningSequential, 0), "")
+				start(l)
+				expectResponse(l.t, signDoc(l, keys, "vera"), "")
+			},
+			signer:         "vic",
+			expectedStatus: SigningCompleted,
+		},
+		{
+			name: "signer signs twice",
+			setup: func(l *testLedger, keys map[string]*ecdsa.PrivateKey) {
+				expectResponse(l.t, defineWorkflow(l, []string{"vera", "vic"}, SigningParallel, 0), "")
+				start(l)
+				expectResponse(l.t, signDoc(l, keys, "vera"), "")
+			},
+			signer:        "vera",
+			expectedError: "vera already signed version 1 of document doc1",
+		},
+		{
+			name: "quorum reached",
+			setup: func(l *testLedger, keys map[string]*ecdsa.PrivateKey) {
+				expectResponse(l.t, defineWorkflow(l, []string{"vera", "vic"}, SigningParallel, 1), "")
+				start(l)
+				expectResponse(l.t, signDoc(l, keys, "vic"), "")
+			},
+			signer:        "vera",
+			expectedError: "Document doc1 is completed, it can't be signed anymore",
+		},
+		{
+			name: "deadline passed",
+			setup: func(l *testLedger, keys map[string]*ecdsa.PrivateKey) {
+				expectResponse(l.t, defineWorkflow(l, []string{"vera"}, SigningParallel, 0), "")
+				start(l)
+				l.txs += int(time.Hour.Seconds())
+			},
+			signer:        "vera",
+			expectedError: "Document doc1 is expired, it can't be signed anymore",
+		},
+		{
+			name: "signature made with key of other user",
+			setup: func(l *testLedger, keys map[string]*ecdsa.PrivateKey) {
+				expectResponse(l.t, defineWorkflow(l, []string{"vera"}, SigningParallel, 0), "")
+				start(l)
+				keys["vera"] = keys["vic"]
+			},
+			signer:        "vera",
+			expectedError: "Signature doesn't verify with any active signing key of user vera",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l, keys := newSigningLedger(t)
+			tt.setup(l, keys)
+			response := signDoc(l, keys, tt.signer)
+			expectResponse(t, response, tt.expectedError)
+			if tt.expectedError == "" && response.Data.(SigningState).Status != tt.expectedStatus {
+				t.Fatalf("expected signing status %s, got %+v", tt.expectedStatus, response.Data)
+			}
+		})
+	}
+}
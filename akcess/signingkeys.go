@@ -0,0 +1,295 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// RegisterSigningKey user registers public key its document signatures will be verified with.
+// Public key is PEM encoded PKIX key of ecdsa-p256 or ed25519 algorithm
+func (u *UserContract) RegisterSigningKey(ctx contractapi.TransactionContextInterface, keyID string, algorithm string, publicKey string) Response {
+	response := Response{
+		TxID:    ctx.GetStub().GetTxID(),
+		Success: false,
+		Message: "",
+		Data:    nil,
+	}
+
+	invoker, err := resolveAkcessID(ctx)
+	if err != nil {
+		response.Message = err.Error()
+		logger.Info(response.Message)
+		return response
+	}
+	user, err := getUser(ctx, invoker)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while fetching user from world state: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+	if user == nil {
+		response.Message = fmt.Sprintf("AKcessID %s doesn't exist", invoker)
+		logger.Info(response.Message)
+		return response
+	}
+
+	if keyID == "" {
+		response.Message = fmt.Sprint("Key ID can't be empty")
+		logger.Info(response.Message)
+		return response
+	}
+	for _, key := range user.SigningKeys {
+		if key.KeyID == keyID {
+			response.Message = fmt.Sprintf("Signing key %s already exist", keyID)
+			logger.Info(response.Message)
+			return response
+		}
+	}
+	_, err = parseSigningKey(algorithm, publicKey)
+	if err != nil {
+		response.Message = fmt.Sprintf("Invalid public key: %s", err.Error())
+		logger.Info(response.Message)
+		return response
+	}
+
+	txTime, err := getTxTimestamp(ctx)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while getting tx timestamp: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+
+	key := SigningKey{
+		KeyID:     keyID,
+		Algorithm: algorithm,
+		PublicKey: publicKey,
+		Status:    KeyActive,
+		AddedAt:   txTime,
+	}
+	user.SigningKeys = append(user.SigningKeys, key)
+	userAsBytes, _ := json.Marshal(user)
+	err = putObject(ctx, UserObject, invoker, userAsBytes)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while saving signing key: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+
+	response.Success = true
+	response.Message = fmt.Sprintf("Signing key %s of user %s registered", keyID, invoker)
+	logger.Info(response.Message)
+	response.Data = key
+	return response
+}
+
+// RevokeSigningKey user revokes its signing key, signatures made with it stay on documents
+// but no new signature is accepted with it
+func (u *UserContract) RevokeSigningKey(ctx contractapi.TransactionContextInterface, keyID string) Response {
+	response := Response{
+		TxID:    ctx.GetStub().GetTxID(),
+		Success: false,
+		Message: "",
+		Data:    nil,
+	}
+
+	invoker, err := resolveAkcessID(ctx)
+	if err != nil {
+		response.Message = err.Error()
+		logger.Info(response.Message)
+		return response
+	}
+	user, err := getUser(ctx, invoker)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while fetching user from world state: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+	if user == nil {
+		response.Message = fmt.Sprintf("AKcessID %s doesn't exist", invoker)
+		logger.Info(response.Message)
+		return response
+	}
+
+	txTime, err := getTxTimestamp(ctx)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while getting tx timestamp: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+
+	found := false
+	for i, key := range user.SigningKeys {
+		if key.KeyID != keyID {
+			continue
+		}
+		if key.Status == KeyRevoked {
+			response.Message = fmt.Sprintf("Signing key %s is already revoked", keyID)
+			logger.Info(response.Message)
+			return response
+		}
+		user.SigningKeys[i].Status = KeyRevoked
+		user.SigningKeys[i].RevokedAt = &txTime
+		found = true
+		break
+	}
+	if !found {
+		response.Message = fmt.Sprintf("Signing key %s doesn't exist", keyID)
+		logger.Info(response.Message)
+		return response
+	}
+
+	userAsBytes, _ := json.Marshal(user)
+	err = putObject(ctx, UserObject, invoker, userAsBytes)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while revoking signing key: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+
+	response.Success = true
+	response.Message = fmt.Sprintf("Signing key %s of user %s revoked", keyID, invoker)
+	logger.Info(response.Message)
+	return response
+}
+
+// GetSigningKeys returns all signing keys of user
+func (u *UserContract) GetSigningKeys(ctx contractapi.TransactionContextInterface, akcessid string) Response {
+	response := Response{
+		TxID:    ctx.GetStub().GetTxID(),
+		Success: false,
+		Message: "",
+		Data:    nil,
+	}
+
+	user, err := getUser(ctx, akcessid)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while fetching user from world state: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+	if user == nil {
+		response.Message = fmt.Sprintf("AKcessID %s doesn't exist", akcessid)
+		logger.Info(response.Message)
+		return response
+	}
+
+	response.Success = true
+	response.Message = fmt.Sprintf("Successfully fetched signing keys of user %s", akcessid)
+	logger.Info(response.Message)
+	response.Data = user.SigningKeys
+	return response
+}
+
+// VerifyUserSignature verifies base64 encoded signature over message with active signing keys of user
+// and returns ID of key it verifies with, used by other chaincodes to verify signatures
+func (u *UserContract) VerifyUserSignature(ctx contractapi.TransactionContextInterface, akcessid string, message string, signature string) (string, error) {
+	user, err := getUser(ctx, akcessid)
+	if err != nil {
+		return "", err
+	}
+	if user == nil {
+		return "", fmt.Errorf("AKcessID %s doesn't exist", akcessid)
+	}
+	return verifyUserSignature(*user, message, signature)
+}
+
+// getUser reads user, nil if it doesn't exist
+func getUser(ctx contractapi.TransactionContextInterface, akcessid string) (*User, error) {
+	userAsBytes, err := getObject(ctx, UserObject, akcessid)
+	if err != nil || userAsBytes == nil {
+		return nil, err
+	}
+	var user User
+	err = json.Unmarshal(userAsBytes, &user)
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// parseSigningKey parses PEM encoded PKIX public key and checks it is key of given algorithm
+func parseSigningKey(algorithm string, publicKey string) (interface{}, error) {
+	block, _ := pem.Decode([]byte(publicKey))
+	if block == nil {
+		return nil, fmt.Errorf("public key must be PEM encoded")
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	switch algorithm {
+	case KeyAlgorithmECDSAP256:
+		ecdsaKey, ok := key.(*ecdsa.PublicKey)
+		if !ok || ecdsaKey.Curve != elliptic.P256() {
+			return nil, fmt.Errorf("public key is not ECDSA P-256 key")
+		}
+		return ecdsaKey, nil
+	case KeyAlgorithmEd25519:
+		ed25519Key, ok := key.(ed25519.PublicKey)
+		if !ok {
+			return nil, fmt.Errorf("public key is not Ed25519 key")
+		}
+		return ed25519Key, nil
+	}
+	return nil, fmt.Errorf("algorithm %s is not supported, use %s or %s", algorithm, KeyAlgorithmECDSAP256, KeyAlgorithmEd25519)
+}
+
+// verifySignature verifies signature over message with signing key
+func verifySignature(key SigningKey, message []byte, signature []byte) bool {
+	publicKey, err := parseSigningKey(key.Algorithm, key.PublicKey)
+	if err != nil {
+		return false
+	}
+
+	switch publicKey := publicKey.(type) {
+	case *ecdsa.PublicKey:
+		var ecdsaSignature struct {
+			R, S *big.Int
+		}
+		rest, err := asn1.Unmarshal(signature, &ecdsaSignature)
+		if err != nil || len(rest) != 0 || ecdsaSignature.R == nil || ecdsaSignature.S == nil {
+			return false
+		}
+		digest := sha256.Sum256(message)
+		return ecdsa.Verify(publicKey, digest[:], ecdsaSignature.R, ecdsaSignature.S)
+	case ed25519.PublicKey:
+		return ed25519.Verify(publicKey, message, signature)
+	}
+	return false
+}
+
+// verifyUserSignature verifies base64 encoded signature over message with active signing keys of user
+// and returns ID of key it verifies with
+func verifyUserSignature(user User, message string, signature string) (string, error) {
+	signatureBytes, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil {
+		return "", fmt.Errorf("Signature must be base64 encoded")
+	}
+
+	active := 0
+	for _, key := range user.SigningKeys {
+		if key.Status != KeyActive {
+			continue
+		}
+		active++
+		if verifySignature(key, []byte(message), signatureBytes) {
+			return key.KeyID, nil
+		}
+	}
+	if active == 0 {
+		return "", fmt.Errorf("User %s has no active signing key", user.AkcessID)
+	}
+	return "", fmt.Errorf("Signature doesn't verify with any active signing key of user %s", user.AkcessID)
+}
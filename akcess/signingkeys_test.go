@@ -0,0 +1,111 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/pem"
+	"math/big"
+	"testing"
+)
+
+// publicKeyPEM PEM encoded PKIX public key
+func publicKeyPEM(t *testing.T, key interface{}) string {
+	t.Helper()
+	keyAsBytes, err := x509.MarshalPKIXPublicKey(key)
+	if err != nil {
+		t.Fatalf("marshalling public key: %s", err.Error())
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: keyAsBytes}))
+}
+
+// signECDSA base64 encoded ASN.1 DER signature over SHA-256 of message
+func signECDSA(t *testing.T, key *ecdsa.PrivateKey, message string) string {
+	t.Helper()
+	digest := sha256.Sum256([]byte(message))
+	r, s, err := ecdsa.Sign(rand.Reader, key, digest[:])
+	if err != nil {
+		t.Fatalf("signing message: %s", err.Error())
+	}
+	signature, _ := asn1.Marshal(struct{ R, S *big.Int }{r, s})
+	return base64.StdEncoding.EncodeToString(signature)
+}
+
+func TestParseSigningKey(t *testing.T) {
+	p256Key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	p384Key, _ := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	ed25519Key, _, _ := ed25519.GenerateKey(rand.Reader)
+
+	tests := []struct {
+		name          string
+		algorithm     string
+		publicKey     string
+		expectedError string
+	}{
+		{name: "ECDSA P-256 key", algorithm: KeyAlgorithmECDSAP256, publicKey: publicKeyPEM(t, &p256Key.PublicKey)},
+		{name: "Ed25519 key", algorithm: KeyAlgorithmEd25519, publicKey: publicKeyPEM(t, ed25519Key)},
+		{name: "ECDSA key of other curve", algorithm: KeyAlgorithmECDSAP256, publicKey: publicKeyPEM(t, &p384Key.PublicKey), expectedError: "public key is not ECDSA P-256 key"},
+		{name: "key of other algorithm", algorithm: KeyAlgorithmEd25519, publicKey: publicKeyPEM(t, &p256Key.PublicKey), expectedError: "public key is not Ed25519 key"},
+		{name: "unsupported algorithm", algorithm: "rsa", publicKey: publicKeyPEM(t, &p256Key.PublicKey), expectedError: "algorithm rsa is not supported"},
+		{name: "key not PEM encoded", algorithm: KeyAlgorithmEd25519, publicKey: "key", expectedError: "public key must be PEM encoded"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseSigningKey(tt.algorithm, tt.publicKey)
+			expectErr(t, err, tt.expectedError)
+		})
+	}
+}
+
+func TestVerifyUserSignature(t *testing.T) {
+	l := newTestLedger(t)
+	p256Key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	ed25519Public, ed25519Key, _ := ed25519.GenerateKey(rand.Reader)
+	revokedKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	otherKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+	expectResponse(t, new(UserContract).CreateUser(l.as("Org1MSP", "alice", nil)), "")
+	expectResponse(t, new(UserContract).CreateUser(l.as("Org1MSP", "bob", nil)), "")
+	expectResponse(t, new(UserContract).RegisterSigningKey(l.as("Org1MSP", "alice", nil), "p256", KeyAlgorithmECDSAP256, publicKeyPEM(t, &p256Key.PublicKey)), "")
+	expectResponse(t, new(UserContract).RegisterSigningKey(l.as("Org1MSP", "alice", nil), "ed25519", KeyAlgorithmEd25519, publicKeyPEM(t, ed25519Public)), "")
+	expectResponse(t, new(UserContract).RegisterSigningKey(l.as("Org1MSP", "alice", nil), "revoked", KeyAlgorithmECDSAP256, publicKeyPEM(t, &revokedKey.PublicKey)), "")
+	expectResponse(t, new(UserContract).RegisterSigningKey(l.as("Org1MSP", "alice", nil), "p256", KeyAlgorithmECDSAP256, publicKeyPEM(t, &otherKey.PublicKey)), "Signing key p256 already exist")
+	expectResponse(t, new(UserContract).RevokeSigningKey(l.as("Org1MSP", "bob", nil), "revoked"), "Signing key revoked doesn't exist")
+	expectResponse(t, new(UserContract).RevokeSigningKey(l.as("Org1MSP", "alice", nil), "revoked"), "")
+	expectResponse(t, new(UserContract).RevokeSigningKey(l.as("Org1MSP", "alice", nil), "revoked"), "Signing key revoked is already revoked")
+
+	message := "sha256:doc1:1"
+	tests := []struct {
+		name          string
+		akcessID      string
+		message       string
+		signature     string
+		expectedKeyID string
+		expectedError string
+	}{
+		{name: "ECDSA signature", akcessID: "alice", message: message, signature: signECDSA(t, p256Key, message), expectedKeyID: "p256"},
+		{name: "Ed25519 signature", akcessID: "alice", message: message, signature: base64.StdEncoding.EncodeToString(ed25519.Sign(ed25519Key, []byte(message))), expectedKeyID: "ed25519"},
+		{name: "signature over other message", akcessID: "alice", message: "sha256:doc1:2", signature: signECDSA(t, p256Key, message), expectedError: "Signature doesn't verify with any active signing key of user alice"},
+		{name: "signature with revoked key", akcessID: "alice", message: message, signature: signECDSA(t, revokedKey, message), expectedError: "Signature doesn't verify with any active signing key"},
+		{name: "signature with unregistered key", akcessID: "alice", message: message, signature: signECDSA(t, otherKey, message), expectedError: "Signature doesn't verify with any active signing key"},
+		{name: "signature not base64 encoded", akcessID: "alice", message: message, signature: "not base64!", expectedError: "Signature must be base64 encoded"},
+		{name: "user without signing keys", akcessID: "bob", message: message, signature: signECDSA(t, p256Key, message), expectedError: "User bob has no active signing key"},
+		{name: "unknown user", akcessID: "carol", message: message, signature: signECDSA(t, p256Key, message), expectedError: "AKcessID carol doesn't exist"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keyID, err := new(UserContract).VerifyUserSignature(l.as("Org2MSP", "reader", nil), tt.akcessID, tt.message, tt.signature)
+			expectErr(t, err, tt.expectedError)
+			if keyID != tt.expectedKeyID {
+				t.Fatalf("expected key %q, got %q", tt.expectedKeyID, keyID)
+			}
+		})
+	}
+}
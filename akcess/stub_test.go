@@ -0,0 +1,475 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/hyperledger/fabric-chaincode-go/pkg/cid"
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-chaincode-go/shimtest"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/hyperledger/fabric-protos-go/ledger/queryresult"
+	"github.com/hyperledger/fabric-protos-go/msp"
+)
+
+// testTime tx timestamp of first transaction in tests, every next transaction is one second later
+var testTime = time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// attributeOID OID of certificate extension Fabric CA stores attributes in
+var attributeOID = asn1.ObjectIdentifier{1, 2, 3, 4, 5, 6, 7, 8, 1}
+
+// testStub mock stub with queries chaincode uses which shimtest doesn't implement
+type testStub struct {
+	*shimtest.MockStub
+	history map[string][]*queryresult.KeyModification
+}
+
+// PutState writes state and records it in history of key
+func (s *testStub) PutState(key string, value []byte) error {
+	err := s.MockStub.PutState(key, value)
+	if err != nil {
+		return err
+	}
+	s.recordHistory(key, value, false)
+	return nil
+}
+
+// DelState deletes state and records delete in history of key
+func (s *testStub) DelState(key string) error {
+	err := s.MockStub.DelState(key)
+	if err != nil {
+		return err
+	}
+	s.recordHistory(key, nil, true)
+	return nil
+}
+
+func (s *testStub) recordHistory(key string, value []byte, isDelete bool) {
+	if s.history == nil {
+		s.history = map[string][]*queryresult.KeyModification{}
+	}
+	s.history[key] = append(s.history[key], &queryresult.KeyModification{
+		TxId:      s.TxID,
+		Value:     value,
+		Timestamp: s.TxTimestamp,
+		IsDelete:  isDelete,
+	})
+}
+
+// GetHistoryForKey returns recorded modifications of key, newest first as peers return them
+func (s *testStub) GetHistoryForKey(key string) (shim.HistoryQueryIteratorInterface, error) {
+	modifications := []*queryresult.KeyModification{}
+	for i := len(s.history[key]) - 1; i >= 0; i-- {
+		modifications = append(modifications, s.history[key][i])
+	}
+	return &testHistoryIterator{modifications: modifications}, nil
+}
+
+// GetStateByRange range query over simple keys, composite keys are skipped as they are on peer
+func (s *testStub) GetStateByRange(startKey string, endKey string) (shim.StateQueryIteratorInterface, error) {
+	results := []*queryresult.KV{}
+	for elem := s.Keys.Front(); elem != nil; elem = elem.Next() {
+		key := elem.Value.(string)
+		if strings.HasPrefix(key, "\x00") || key < startKey || (endKey != "" && key >= endKey) {
+			continue
+		}
+		results = append(results, &queryresult.KV{Key: key, Value: s.State[key]})
+	}
+	return &testIterator{results: results}, nil
+}
+
+// GetPrivateDataByPartialCompositeKey query over composite keys of private data collection
+func (s *testStub) GetPrivateDataByPartialCompositeKey(collection string, objectType string, attributes []string) (shim.StateQueryIteratorInterface, error) {
+	prefix, err := s.CreateCompositeKey(objectType, attributes)
+	if err != nil {
+		return nil, err
+	}
+	keys := []string{}
+	for key := range s.PvtState[collection] {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	results := []*queryresult.KV{}
+	for _, key := range keys {
+		results = append(results, &queryresult.KV{Key: key, Value: s.PvtState[collection][key]})
+	}
+	return &testIterator{results: results}, nil
+}
+
+// DelPrivateData deletes key from private data collection
+func (s *testStub) DelPrivateData(collection string, key string) error {
+	delete(s.PvtState[collection], key)
+	return nil
+}
+
+// GetQueryResult rich query matching selector fields by equality, by $or of selectors or by operators matchesCondition supports
+func (s *testStub) GetQueryResult(query string) (shim.StateQueryIteratorInterface, error) {
+	keys := []string{}
+	for elem := s.Keys.Front(); elem != nil; elem = elem.Next() {
+		keys = append(keys, elem.Value.(string))
+	}
+	return queryState(s.State, keys, query)
+}
+
+// GetPrivateDataQueryResult rich query over private data collection
+func (s *testStub) GetPrivateDataQueryResult(collection string, query string) (shim.StateQueryIteratorInterface, error) {
+	keys := []string{}
+	for key := range s.PvtState[collection] {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return queryState(s.PvtState[collection], keys, query)
+}
+
+// queryState returns values of keys matching selector of rich query
+func queryState(state map[string][]byte, keys []string, query string) (shim.StateQueryIteratorInterface, error) {
+	var parsed struct {
+		Selector map[string]interface{} `json:"selector"`
+	}
+	err := json.Unmarshal([]byte(query), &parsed)
+	if err != nil {
+		return nil, err
+	}
+	results := []*queryresult.KV{}
+	for _, key := range keys {
+		var object map[string]interface{}
+		if json.Unmarshal(state[key], &object) != nil {
+			continue
+		}
+		matches, err := matchesSelector(object, parsed.Selector)
+		if err != nil {
+			return nil, err
+		}
+		if matches {
+			results = append(results, &queryresult.KV{Key: key, Value: state[key]})
+		}
+	}
+	return &testIterator{results: results}, nil
+}
+
+func matchesSelector(object map[string]interface{}, selector map[string]interface{}) (bool, error) {
+	for field, condition := range selector {
+		if field == "$or" {
+			matches := false
+			for _, alternative := range condition.([]interface{}) {
+				var err error
+				matches, err = matchesSelector(object, alternative.(map[string]interface{}))
+				if err != nil {
+					return false, err
+				}
+				if matches {
+					break
+				}
+			}
+			if !matches {
+				return false, nil
+			}
+			continue
+		}
+		value, found := object[field]
+		matches, err := matchesCondition(value, found, condition)
+		if err != nil || !matches {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// matchesCondition matches value by equality or by $exists, $eq and $elemMatch operators
+func matchesCondition(value interface{}, found bool, condition interface{}) (bool, error) {
+	operators, isOperator := condition.(map[string]interface{})
+	if !isOperator {
+		return found && reflect.DeepEqual(value, condition), nil
+	}
+	for operator, operand := range operators {
+		matches := false
+		switch operator {
+		case "$exists":
+			matches = found == operand.(bool)
+		case "$eq":
+			matches = found && reflect.DeepEqual(value, operand)
+		case "$elemMatch":
+			elements, _ := value.([]interface{})
+			for _, element := range elements {
+				var err error
+				if object, isObject := element.(map[string]interface{}); isObject {
+					matches, err = matchesSelector(object, operand.(map[string]interface{}))
+				} else {
+					matches, err = matchesCondition(element, true, operand)
+				}
+				if err != nil {
+					return false, err
+				}
+				if matches {
+					break
+				}
+			}
+		default:
+			return false, fmt.Errorf("operator %s is not supported by test stub", operator)
+		}
+		if !matches {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// testIterator iterator over fixed query results
+type testIterator struct {
+	results []*queryresult.KV
+}
+
+func (it *testIterator) HasNext() bool {
+	return len(it.results) > 0
+}
+
+func (it *testIterator) Next() (*queryresult.KV, error) {
+	if len(it.results) == 0 {
+		return nil, fmt.Errorf("iterator has no more results")
+	}
+	result := it.results[0]
+	it.results = it.results[1:]
+	return result, nil
+}
+
+func (it *testIterator) Close() error {
+	return nil
+}
+
+// testHistoryIterator iterator over recorded modifications of a key
+type testHistoryIterator struct {
+	modifications []*queryresult.KeyModification
+}
+
+func (it *testHistoryIterator) HasNext() bool {
+	return len(it.modifications) > 0
+}
+
+func (it *testHistoryIterator) Next() (*queryresult.KeyModification, error) {
+	if len(it.modifications) == 0 {
+		return nil, fmt.Errorf("iterator has no more results")
+	}
+	modification := it.modifications[0]
+	it.modifications = it.modifications[1:]
+	return modification, nil
+}
+
+func (it *testHistoryIterator) Close() error {
+	return nil
+}
+
+// testLedger world state shared by transactions of one test
+type testLedger struct {
+	t    *testing.T
+	stub *testStub
+	txs  int
+}
+
+func newTestLedger(t *testing.T) *testLedger {
+	return &testLedger{
+		t:    t,
+		stub: &testStub{MockStub: shimtest.NewMockStub("akcess", nil)},
+	}
+}
+
+// as starts new transaction invoked by client identity with given MSP, enrollment ID and certificate attributes
+func (l *testLedger) as(mspID string, enrollmentID string, attrs map[string]string) contractapi.TransactionContextInterface {
+	l.t.Helper()
+	return l.asRole("client", mspID, enrollmentID, attrs)
+}
+
+// asRole starts new transaction invoked by identity with given NodeOU role, MSP, enrollment ID and certificate attributes
+func (l *testLedger) asRole(role string, mspID string, enrollmentID string, attrs map[string]string) contractapi.TransactionContextInterface {
+	l.t.Helper()
+	l.txs++
+	l.stub.MockTransactionStart(fmt.Sprintf("tx%d", l.txs))
+	l.stub.TxTimestamp, _ = ptypes.TimestampProto(l.now())
+	l.stub.Creator = serializedIdentity(l.t, role, mspID, enrollmentID, attrs)
+
+	identity, err := cid.New(l.stub)
+	if err != nil {
+		l.t.Fatalf("creating client identity: %s", err.Error())
+	}
+	ctx := new(contractapi.TransactionContext)
+	ctx.SetStub(l.stub)
+	ctx.SetClientIdentity(identity)
+	return ctx
+}
+
+// now tx timestamp of current transaction
+func (l *testLedger) now() time.Time {
+	return testTime.Add(time.Duration(l.txs) * time.Second)
+}
+
+// put writes object to world state in its own transaction, used to set up state tests start from
+func (l *testLedger) put(key string, object interface{}) {
+	l.t.Helper()
+	objectAsBytes, err := json.Marshal(object)
+	if err != nil {
+		l.t.Fatalf("marshalling %s: %s", key, err.Error())
+	}
+	l.stub.MockTransactionStart("setup")
+	err = l.stub.PutState(key, objectAsBytes)
+	l.stub.MockTransactionEnd("setup")
+	if err != nil {
+		l.t.Fatalf("writing %s: %s", key, err.Error())
+	}
+}
+
+// compositeKey composite key of given type and attributes
+func (l *testLedger) compositeKey(objectType string, attributes ...string) string {
+	l.t.Helper()
+	key, err := l.stub.CreateCompositeKey(objectType, attributes)
+	if err != nil {
+		l.t.Fatalf("creating composite key: %s", err.Error())
+	}
+	return key
+}
+
+// initLedger initializes access config with AdminMSP as admin MSP and OTPMSP as OTP issuer MSP
+func (l *testLedger) initLedger() {
+	l.t.Helper()
+	response := new(UserContract).InitLedger(l.asRole("admin", "AdminMSP", "admin", nil), []string{"AdminMSP"}, []string{"OTPMSP"})
+	if !response.Success {
+		l.t.Fatalf("InitLedger failed: %s", response.Message)
+	}
+}
+
+// serializedIdentity serialized identity with self signed certificate, common name is set to enrollment ID,
+// organizational unit to NodeOU role and attributes are stored in certificate extension as Fabric CA stores them
+func serializedIdentity(t *testing.T, role string, mspID string, enrollmentID string, attrs map[string]string) []byte {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generating key: %s", err.Error())
+	}
+	template := x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: enrollmentID, Organization: []string{mspID}, OrganizationalUnit: []string{role}},
+		NotBefore:    testTime.Add(-time.Hour),
+		NotAfter:     testTime.Add(24 * time.Hour),
+	}
+	if attrs != nil {
+		attrsAsBytes, _ := json.Marshal(map[string]map[string]string{"attrs": attrs})
+		template.ExtraExtensions = []pkix.Extension{{Id: attributeOID, Value: attrsAsBytes}}
+	}
+	certificate, err := x509.CreateCertificate(rand.Reader, &template, &template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("creating certificate: %s", err.Error())
+	}
+
+	identity, err := proto.Marshal(&msp.SerializedIdentity{
+		Mspid:   mspID,
+		IdBytes: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certificate}),
+	})
+	if err != nil {
+		t.Fatalf("serializing identity: %s", err.Error())
+	}
+	return identity
+}
+
+// expectResponse checks response succeeded, or failed with message containing expected error
+func expectResponse(t *testing.T, response Response, expectedError string) {
+	t.Helper()
+	expectError(t, response.Success, response.Message, expectedError)
+}
+
+// expectErr checks err is nil, or contains expected error
+func expectErr(t *testing.T, err error, expectedError string) {
+	t.Helper()
+	message := ""
+	if err != nil {
+		message = err.Error()
+	}
+	expectError(t, err == nil, message, expectedError)
+}
+
+func expectError(t *testing.T, success bool, message string, expectedError string) {
+	t.Helper()
+	if expectedError == "" {
+		if !success {
+			t.Fatalf("expected success, got error: %s", message)
+		}
+		return
+	}
+	if success {
+		t.Fatalf("expected error containing %q, got success: %s", expectedError, message)
+	}
+	if !strings.Contains(message, expectedError) {
+		t.Fatalf("expected error containing %q, got: %s", expectedError, message)
+	}
+}
+
+var verifierAttrs = map[string]string{"isVerifier": "true"}
+
+func equalStrings(a []string, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// newDocLedger ledger with document doc1 owned by bob, users vera and vic who are approved verifiers and user mallory
+func newDocLedger(t *testing.T) *testLedger {
+	l := newTestLedger(t)
+	l.initLedger()
+	for _, akcessID := range []string{"bob", "vera", "vic", "mallory"} {
+		expectResponse(t, new(UserContract).CreateUser(l.as("Org1MSP", akcessID, nil)), "")
+	}
+	for _, akcessID := range []string{"vera", "vic"} {
+		expectResponse(t, new(UserContract).CreateVerifier(l.as("Org1MSP", akcessID, verifierAttrs), akcessID, "gold"), "")
+		expectResponse(t, new(UserContract).ApproveVerifier(l.as("AdminMSP", "admin", nil), akcessID), "")
+	}
+	expectResponse(t, new(DocContract).CreateDocWithHash(l.as("Org1MSP", "bob", nil), "doc1", "passport", saltedHash("", "content"), "sha256"), "")
+	return l
+}
+
+// shareDoc shares doc1 with receivers passed in transient data
+func shareDoc(l *testLedger, sender string, sharingid string, receivers []string, permissions []string) Response {
+	l.t.Helper()
+	ctx := l.as("Org1MSP", sender, nil)
+	receiversAsJSON, _ := json.Marshal(receivers)
+	err := l.stub.SetTransient(map[string][]byte{"receivers": receiversAsJSON, "salt": []byte("salt-" + sharingid)})
+	if err != nil {
+		l.t.Fatalf("setting transient data: %s", err.Error())
+	}
+	response := new(DocContract).ShareDoc(ctx, sharingid, "doc1", permissions, "", "")
+	l.stub.TransientMap = nil
+	return response
+}
+
+// newAssetLedger ledger with users alice, bank and mallory, and asset1 solely owned by alice
+func newAssetLedger(t *testing.T) *testLedger {
+	l := newTestLedger(t)
+	for _, akcessID := range []string{"alice", "bank", "mallory"} {
+		expectResponse(t, new(UserContract).CreateUser(l.as("Org1MSP", akcessID, nil)), "")
+	}
+	l.put(l.compositeKey(AssetObject, "asset1"), DigitalAsset{ObjectType: "asset", UniqueAssetID: "asset1", Owner: "alice"})
+	return l
+}
+
+func offerAsset1ToMallory(ctx contractapi.TransactionContextInterface) Response {
+	return new(DigitalAssetContract).OfferAssetTransfer(ctx, "asset1", "mallory", "")
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSetTimePolicy(t *testing.T) {
+	tests := []struct {
+		name          string
+		mspID         string
+		skew          int
+		horizon       int
+		expectedError string
+	}{
+		{name: "admin sets policy", mspID: "AdminMSP", skew: 60, horizon: 30},
+		{name: "admin allows no skew", mspID: "AdminMSP", skew: 0, horizon: 30},
+		{name: "non admin", mspID: "Org1MSP", skew: 60, horizon: 30, expectedError: "only admin can set time policy"},
+		{name: "negative skew", mspID: "AdminMSP", skew: -1, horizon: 30, expectedError: "Sign date skew can't be negative"},
+		{name: "no expiry horizon", mspID: "AdminMSP", skew: 60, horizon: 0, expectedError: "expiry horizon must be greater than zero"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := newTestLedger(t)
+			l.initLedger()
+			expectResponse(t, new(UserContract).SetTimePolicy(l.as(tt.mspID, "admin", nil), tt.skew, tt.horizon), tt.expectedError)
+
+			expectedSkew, expectedHorizon := tt.skew, tt.horizon
+			if tt.expectedError != "" {
+				expectedSkew, expectedHorizon = DefaultSignDateSkewSeconds, DefaultExpiryHorizonDays
+			}
+			response := new(UserContract).GetTimePolicy(l.as("Org1MSP", "reader", nil))
+			expectResponse(t, response, "")
+			if policy := response.Data.(*TimePolicy); policy.SignDateSkewSeconds != expectedSkew || policy.ExpiryHorizonDays != expectedHorizon {
+				t.Fatalf("expected skew %d and horizon %d, got %+v", expectedSkew, expectedHorizon, policy)
+			}
+		})
+	}
+}
+
+func TestCheckSignDate(t *testing.T) {
+	policy := TimePolicy{SignDateSkewSeconds: 300}
+	tests := []struct {
+		name          string
+		signDate      time.Time
+		expectedError string
+	}{
+		{name: "tx timestamp", signDate: testTime},
+		{name: "earliest allowed", signDate: testTime.Add(-300 * time.Second)},
+		{name: "latest allowed", signDate: testTime.Add(300 * time.Second)},
+		{name: "too early", signDate: testTime.Add(-301 * time.Second), expectedError: "is more than 300 seconds away from tx timestamp"},
+		{name: "too late", signDate: testTime.Add(301 * time.Second), expectedError: "is more than 300 seconds away from tx timestamp"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expectErr(t, policy.CheckSignDate(tt.signDate, testTime), tt.expectedError)
+		})
+	}
+}
+
+func TestCheckExpiryDate(t *testing.T) {
+	policy := TimePolicy{ExpiryHorizonDays: 30}
+	tests := []struct {
+		name          string
+		expiryDate    time.Time
+		expectedError string
+	}{
+		{name: "within horizon", expiryDate: testTime.AddDate(0, 0, 1)},
+		{name: "at horizon", expiryDate: testTime.AddDate(0, 0, 30)},
+		{name: "tx timestamp", expiryDate: testTime, expectedError: "is not in the future"},
+		{name: "past", expiryDate: testTime.Add(-time.Second), expectedError: "is not in the future"},
+		{name: "beyond horizon", expiryDate: testTime.AddDate(0, 0, 30).Add(time.Second), expectedError: "is more than 30 days in the future"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expectErr(t, policy.CheckExpiryDate(tt.expiryDate, testTime), tt.expectedError)
+		})
+	}
+}
+
+func TestApproveDocVerificationTimePolicy(t *testing.T) {
+	tests := []struct {
+		name          string
+		horizon       int
+		expiresIn     int
+		expectedError string
+	}{
+		{name: "expiry within default horizon", expiresIn: 365},
+		{name: "expiry beyond default horizon", expiresIn: DefaultExpiryHorizonDays + 1, expectedError: "days in the future"},
+		{name: "expiry within policy horizon", horizon: 30, expiresIn: 30},
+		{name: "expiry beyond policy horizon", horizon: 30, expiresIn: 31, expectedError: "is more than 30 days in the future"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := newDocLedger(t)
+			if tt.horizon != 0 {
+				expectResponse(t, new(UserContract).SetTimePolicy(l.as("AdminMSP", "admin", nil), DefaultSignDateSkewSeconds, tt.horizon), "")
+			}
+			requestID := requestVerification(l)
+			ctx := l.as("Org1MSP", "vera", verifierAttrs)
+			expiryDate := l.now().AddDate(0, 0, tt.expiresIn).Format(time.RFC3339)
+			expectResponse(t, new(DocContract).ApproveDocVerification(ctx, requestID, expiryDate), tt.expectedError)
+		})
+	}
+}
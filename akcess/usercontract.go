@@ -22,8 +22,13 @@ func (u *UserContract) CreateUser(ctx contractapi.TransactionContextInterface) R
 		Data:    nil,
 	}
 
-	invoker, _ := getCommonName(ctx)
-	userAsBytes, err := ctx.GetStub().GetState(invoker)
+	invoker, bound, err := registrationAkcessID(ctx)
+	if err != nil {
+		response.Message = err.Error()
+		logger.Info(response.Message)
+		return response
+	}
+	userAsBytes, err := getObject(ctx, UserObject, invoker)
 	if err != nil {
 		response.Message = fmt.Sprintf("Error while fetching user from world state: %s" + err.Error())
 		logger.Error(response.Message)
@@ -35,18 +40,59 @@ func (u *UserContract) CreateUser(ctx contractapi.TransactionContextInterface) R
 		return response
 	}
 
+	identityHash, err := getIdentityHash(ctx)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while reading invoker identity: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+	// erased AKcessID can only be registered again by identity which registered it before
+	tombstoneAsBytes, err := getObject(ctx, TombstoneObject, invoker)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while fetching tombstone from world state: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+	if tombstoneAsBytes != nil {
+		var tombstone UserTombstone
+		json.Unmarshal(tombstoneAsBytes, &tombstone)
+		if tombstone.IdentityHash == "" || tombstone.IdentityHash != identityHash {
+			response.Message = fmt.Sprintf("AKcessID %s was erased and can't be registered by another identity", invoker)
+			logger.Info(response.Message)
+			return response
+		}
+		err = deleteObject(ctx, TombstoneObject, invoker)
+		if err != nil {
+			response.Message = fmt.Sprintf("Error while removing tombstone: %s", err.Error())
+			logger.Error(response.Message)
+			return response
+		}
+	}
+
 	user := User{
-		ObjectType:    "user",
-		AkcessID:      invoker,
-		Verifications: map[string][]Verification{},
+		ObjectType:           "user",
+		AkcessID:             invoker,
+		Verifications:        map[string][]Verification{},
+		RevokedVerifications: map[string][]RevokedVerification{},
+		Commitments:          map[string]ProfileCommitment{},
+		IdentityHash:         identityHash,
+		SigningKeys:          []SigningKey{},
 	}
 	newUserAsBytes, _ := json.Marshal(user)
-	err = ctx.GetStub().PutState(invoker, newUserAsBytes)
+	err = putObject(ctx, UserObject, invoker, newUserAsBytes)
 	if err != nil {
 		response.Message = fmt.Sprintf("Error while registering user: %s" + err.Error())
 		logger.Error(response.Message)
 		return response
 	}
+	if !bound {
+		err = bindInvoker(ctx, invoker)
+		if err != nil {
+			response.Message = fmt.Sprintf("Error while binding identity to AKcessID %s: %s", invoker, err.Error())
+			logger.Error(response.Message)
+			return response
+		}
+	}
 
 	response.Success = true
 	response.Message = fmt.Sprintf("User with AKcessID %s added\n", invoker)
@@ -63,8 +109,19 @@ func (u *UserContract) CreateVerifier(ctx contractapi.TransactionContextInterfac
 		Data:    nil,
 	}
 
-	invoker, _ := getCommonName(ctx)
-	verifierAsBytes, err := ctx.GetStub().GetState(invoker)
+	invoker, bound, err := registrationAkcessID(ctx)
+	if err != nil {
+		response.Message = err.Error()
+		logger.Info(response.Message)
+		return response
+	}
+	if !IsVerifier(ctx) {
+		response.Message = fmt.Sprintf("Identity %s is not allowed to apply as verifier, isVerifier attribute is not set", invoker)
+		logger.Info(response.Message)
+		return response
+	}
+
+	verifierAsBytes, err := getObject(ctx, VerifierObject, invoker)
 	if err != nil {
 		response.Message = fmt.Sprintf("Error while fetching verifier from world state: %s" + err.Error())
 		logger.Error(response.Message)
@@ -76,28 +133,100 @@ func (u *UserContract) CreateVerifier(ctx contractapi.TransactionContextInterfac
 		return response
 	}
 
+	taxonomy, err := getGradeTaxonomy(ctx)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while fetching grade taxonomy: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+	// grades can't be checked until admin defines taxonomy
+	if _, found := taxonomy.Level(VerifierGrade); !found && len(taxonomy.Grades) > 0 {
+		response.Message = fmt.Sprintf("Grade %s is not part of grade taxonomy", VerifierGrade)
+		logger.Info(response.Message)
+		return response
+	}
+
+	txTime, err := getTxTimestamp(ctx)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while getting tx timestamp: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+
 	verifier := Verifier{
 		ObjectType:    "verifier",
 		AkcessID:      invoker,
 		VerifierName:  verifierName,
 		VerifierGrade: VerifierGrade,
+		Status:        VerifierPending,
+		StatusHistory: []VerifierStatusChange{
+			{
+				Status:    VerifierPending,
+				Reason:    "application submitted",
+				ChangedBy: invoker,
+				TimeStamp: txTime,
+			},
+		},
+		Version: 1,
+		ProfileHistory: []VerifierProfileVersion{
+			{
+				Version:       1,
+				VerifierName:  verifierName,
+				VerifierGrade: VerifierGrade,
+				UpdatedBy:     invoker,
+				UpdatedAt:     txTime,
+			},
+		},
 	}
 	newVerifierAsBytes, _ := json.Marshal(verifier)
-	err = ctx.GetStub().PutState(invoker, newVerifierAsBytes)
+	err = putObject(ctx, VerifierObject, invoker, newVerifierAsBytes)
 	if err != nil {
 		response.Message = fmt.Sprintf("Error while registering verifier: %s" + err.Error())
 		logger.Error(response.Message)
 		return response
 	}
+	if !bound {
+		err = bindInvoker(ctx, invoker)
+		if err != nil {
+			response.Message = fmt.Sprintf("Error while binding identity to AKcessID %s: %s", invoker, err.Error())
+			logger.Error(response.Message)
+			return response
+		}
+	}
 
 	response.Success = true
-	response.Message = fmt.Sprintf("Verifier with AKcessID %s added\n", invoker)
+	response.Message = fmt.Sprintf("Verifier with AKcessID %s added and waiting for approval\n", invoker)
 	logger.Info(response.Message)
 	return response
 }
 
 // AddUserProfileVerification add verifcation transaction and field of users profiles is verfiied
+// Kept for fields user has not stored commitment for, committed fields are verified with AddUserProfileVerificationWithCommitments
 func (u *UserContract) AddUserProfileVerification(ctx contractapi.TransactionContextInterface, verifierAKcessID string, userAKcessID string, profileFields []string, expiryDates []string) Response {
+	return addUserProfileVerification(ctx, verifierAKcessID, userAKcessID, profileFields, expiryDates, nil)
+}
+
+// AddUserProfileVerificationWithCommitments verifier attests to commitment of each field value,
+// so commitments must match user's current commitments
+func (u *UserContract) AddUserProfileVerificationWithCommitments(ctx contractapi.TransactionContextInterface, verifierAKcessID string, userAKcessID string, profileFields []string, expiryDates []string, commitments []string) Response {
+	response := Response{
+		TxID:    ctx.GetStub().GetTxID(),
+		Success: false,
+		Message: "",
+		Data:    nil,
+	}
+
+	if len(commitments) != len(profileFields) {
+		response.Message = fmt.Sprint("Pass one expiry date and one commitment for each profile field")
+		logger.Info(response.Message)
+		return response
+	}
+	return addUserProfileVerification(ctx, verifierAKcessID, userAKcessID, profileFields, expiryDates, commitments)
+}
+
+// addUserProfileVerification records verifications of profile fields, commitments is nil when verifier doesn't
+// attest to any and then only fields without stored commitment can be verified
+func addUserProfileVerification(ctx contractapi.TransactionContextInterface, verifierAKcessID string, userAKcessID string, profileFields []string, expiryDates []string, commitments []string) Response {
 	response := Response{
 		TxID:    ctx.GetStub().GetTxID(),
 		Success: false,
@@ -105,8 +234,24 @@ func (u *UserContract) AddUserProfileVerification(ctx contractapi.TransactionCon
 		Data:    nil,
 	}
 
-	invoker, _ := getCommonName(ctx)
-	verifierAsBytes, err := ctx.GetStub().GetState(invoker)
+	if len(expiryDates) != len(profileFields) {
+		response.Message = fmt.Sprint("Pass one expiry date for each profile field")
+		logger.Info(response.Message)
+		return response
+	}
+
+	invoker, err := resolveAkcessID(ctx)
+	if err != nil {
+		response.Message = err.Error()
+		logger.Info(response.Message)
+		return response
+	}
+	if verifierAKcessID != invoker {
+		response.Message = fmt.Sprintf("Identity %s can't add verification on behalf of verifier %s", invoker, verifierAKcessID)
+		logger.Info(response.Message)
+		return response
+	}
+	verifierAsBytes, err := getObject(ctx, VerifierObject, invoker)
 	if err != nil {
 		response.Message = fmt.Sprintf("Error while fetching verifier from world state: %s" + err.Error())
 		logger.Error(response.Message)
@@ -119,8 +264,13 @@ func (u *UserContract) AddUserProfileVerification(ctx contractapi.TransactionCon
 	}
 	var verifier Verifier
 	json.Unmarshal(verifierAsBytes, &verifier)
+	if !verifier.IsApproved() {
+		response.Message = fmt.Sprintf("Verifier %s is not approved, current status is %s", invoker, verifier.CurrentStatus())
+		logger.Info(response.Message)
+		return response
+	}
 
-	userAsBytes, err := ctx.GetStub().GetState(userAKcessID)
+	userAsBytes, err := getObject(ctx, UserObject, userAKcessID)
 	if err != nil {
 		response.Message = fmt.Sprintf("Error while fetching user from world state: %s" + err.Error())
 		logger.Error(response.Message)
@@ -134,7 +284,55 @@ func (u *UserContract) AddUserProfileVerification(ctx contractapi.TransactionCon
 	var user User
 	json.Unmarshal(userAsBytes, &user)
 
+	txTime, err := getTxTimestamp(ctx)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while getting tx timestamp: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+	policy, err := getTimePolicy(ctx)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while fetching time policy: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+
 	for index, profileField := range profileFields {
+		err = checkGradePolicy(ctx, PolicyScopeProfile, profileField, verifier.VerifierGrade)
+		if err != nil {
+			response.Message = fmt.Sprintf("Verifier %s can't verify profile field %s: %s", invoker, profileField, err.Error())
+			logger.Info(response.Message)
+			return response
+		}
+
+		commitment := ""
+		stored, committed := user.Commitments[profileField]
+		committed = committed && stored.Commitment != ""
+		if commitments == nil {
+			if committed {
+				response.Message = fmt.Sprintf("User %s has commitment for profile field %s, verify it with AddUserProfileVerificationWithCommitments", userAKcessID, profileField)
+				logger.Info(response.Message)
+				return response
+			}
+		} else {
+			commitment = commitments[index]
+			if commitment == "" {
+				response.Message = fmt.Sprintf("Commitment of profile field %s can't be empty", profileField)
+				logger.Info(response.Message)
+				return response
+			}
+			if !committed {
+				response.Message = fmt.Sprintf("User %s has no commitment for profile field %s", userAKcessID, profileField)
+				logger.Info(response.Message)
+				return response
+			}
+			if stored.Commitment != commitment {
+				response.Message = fmt.Sprintf("Commitment %s doesn't match current commitment of profile field %s", commitment, profileField)
+				logger.Info(response.Message)
+				return response
+			}
+		}
+
 		verifierList := VerifiersList(user.Verifications[profileField])
 		expirydate, err := time.Parse(time.RFC3339, expiryDates[index])
 		if err != nil {
@@ -142,26 +340,38 @@ func (u *UserContract) AddUserProfileVerification(ctx contractapi.TransactionCon
 			logger.Info(response.Message)
 			return response
 		}
+		err = policy.CheckExpiryDate(expirydate, txTime)
+		if err != nil {
+			response.Message = err.Error()
+			logger.Info(response.Message)
+			return response
+		}
 
 		_, found := Find(verifierList, verifierAKcessID)
 		if found {
 			for i, v := range user.Verifications[profileField] {
-				if v.VerifierObj.AkcessID == verifierAKcessID {
+				if v.VerifierID == verifierAKcessID {
+					user.Verifications[profileField][i].VerifierVersion = verifier.Version
 					user.Verifications[profileField][i].ExpirtyDate = expirydate
+					user.Verifications[profileField][i].Commitment = commitment
+					user.Verifications[profileField][i].TxTimestamp = txTime
 					break
 				}
 			}
 		} else {
 			verification := Verification{
-				VerifierObj: verifier,
-				ExpirtyDate: expirydate,
+				VerifierID:      verifier.AkcessID,
+				VerifierVersion: verifier.Version,
+				ExpirtyDate:     expirydate,
+				Commitment:      commitment,
+				TxTimestamp:     txTime,
 			}
 			user.Verifications[profileField] = append(user.Verifications[profileField], verification)
 		}
 	}
 
 	userAsBytes, _ = json.Marshal(user)
-	err = ctx.GetStub().PutState(userAKcessID, userAsBytes)
+	err = putObject(ctx, UserObject, userAKcessID, userAsBytes)
 	if err != nil {
 		response.Message = fmt.Sprintf("Error while updating user profile verification: %s" + err.Error())
 		logger.Error(response.Message)
@@ -174,8 +384,178 @@ func (u *UserContract) AddUserProfileVerification(ctx contractapi.TransactionCon
 	return response
 }
 
-// GetVerifiersOfUserProfile get verifiers of perticular user field
-func (u *UserContract) GetVerifiersOfUserProfile(ctx contractapi.TransactionContextInterface, akcessid string, profileField string) Response {
+// SetProfileFieldCommitment user stores salted hash of profile field value, hex encoded SHA-256 of salt followed by value.
+// Verifications made for previous commitment of the field become stale
+func (u *UserContract) SetProfileFieldCommitment(ctx contractapi.TransactionContextInterface, profileField string, commitment string) Response {
+	response := Response{
+		TxID:    ctx.GetStub().GetTxID(),
+		Success: false,
+		Message: "",
+		Data:    nil,
+	}
+
+	invoker, err := resolveAkcessID(ctx)
+	if err != nil {
+		response.Message = err.Error()
+		logger.Info(response.Message)
+		return response
+	}
+	if !isHexHash(commitment) {
+		response.Message = fmt.Sprint("Commitment must be hex encoded SHA-256 hash")
+		logger.Info(response.Message)
+		return response
+	}
+
+	userAsBytes, err := getObject(ctx, UserObject, invoker)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while fetching user from world state: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+	if userAsBytes == nil {
+		response.Message = fmt.Sprintf("AKcessID %s doesn't exist", invoker)
+		logger.Info(response.Message)
+		return response
+	}
+	var user User
+	json.Unmarshal(userAsBytes, &user)
+
+	txTime, err := getTxTimestamp(ctx)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while getting tx timestamp: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+
+	if user.Commitments == nil {
+		user.Commitments = map[string]ProfileCommitment{}
+	}
+	user.Commitments[profileField] = ProfileCommitment{
+		Commitment: commitment,
+		UpdatedAt:  txTime,
+	}
+
+	userAsBytes, _ = json.Marshal(user)
+	err = putObject(ctx, UserObject, invoker, userAsBytes)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while saving profile field commitment: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+
+	response.Success = true
+	response.Message = fmt.Sprintf("Commitment of profile field %s of user %s updated", profileField, invoker)
+	logger.Info(response.Message)
+	return response
+}
+
+// StoreProfileFieldValue user stores profile field value in profile private data collection and its commitment
+// in public state. Value and salt are passed in transient data under "value" and "salt" keys
+func (u *UserContract) StoreProfileFieldValue(ctx contractapi.TransactionContextInterface, profileField string) Response {
+	response := Response{
+		TxID:    ctx.GetStub().GetTxID(),
+		Success: false,
+		Message: "",
+		Data:    nil,
+	}
+
+	invoker, err := resolveAkcessID(ctx)
+	if err != nil {
+		response.Message = err.Error()
+		logger.Info(response.Message)
+		return response
+	}
+	value, err := getTransientValue(ctx, "value")
+	if err != nil {
+		response.Message = err.Error()
+		logger.Info(response.Message)
+		return response
+	}
+	salt, err := getTransientValue(ctx, "salt")
+	if err != nil {
+		response.Message = err.Error()
+		logger.Info(response.Message)
+		return response
+	}
+
+	collections, err := getPrivateDataConfig(ctx)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while fetching private data config: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+	profileValue := ProfileFieldValue{
+		ObjectType:   "profilevalue",
+		AkcessID:     invoker,
+		ProfileField: profileField,
+		Value:        value,
+		Salt:         salt,
+	}
+	err = putPrivateObject(ctx, collections.ProfileCollection, "profilevalue", []string{invoker, profileField}, profileValue)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while saving profile value in private data collection: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+
+	return u.SetProfileFieldCommitment(ctx, profileField, saltedHash(salt, value))
+}
+
+// CheckProfileFieldCommitment checks value and salt disclosed off-chain against commitment of user profile field
+// and returns active verifications attesting that commitment
+func (u *UserContract) CheckProfileFieldCommitment(ctx contractapi.TransactionContextInterface, userAKcessID string, profileField string, value string, salt string) Response {
+	response := Response{
+		TxID:    ctx.GetStub().GetTxID(),
+		Success: false,
+		Message: "",
+		Data:    nil,
+	}
+
+	userAsBytes, err := getObject(ctx, UserObject, userAKcessID)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while fetching user from world state: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+	if userAsBytes == nil {
+		response.Message = fmt.Sprintf("AKcessID %s doesn't exist", userAKcessID)
+		logger.Info(response.Message)
+		return response
+	}
+	var user User
+	json.Unmarshal(userAsBytes, &user)
+
+	current, found := user.Commitments[profileField]
+	if !found {
+		response.Message = fmt.Sprintf("User %s has no commitment for profile field %s", userAKcessID, profileField)
+		logger.Info(response.Message)
+		return response
+	}
+
+	result := CommitmentCheck{
+		ProfileField: profileField,
+		Commitment:   current.Commitment,
+		Matches:      saltedHash(salt, value) == current.Commitment,
+	}
+	if result.Matches {
+		result.Verified, err = buildVerificationsView(ctx, PolicyScopeProfile, profileField, user.Verifications[profileField], nil, current.Commitment, true)
+		if err != nil {
+			response.Message = fmt.Sprintf("Error while evaluating verifications: %s", err.Error())
+			logger.Error(response.Message)
+			return response
+		}
+	}
+
+	response.Data = result
+	response.Success = true
+	response.Message = fmt.Sprintf("Checked value of profile field %s of user %s against its commitment", profileField, userAKcessID)
+	logger.Info(response.Message)
+	return response
+}
+
+// GetVerifiersOfUserProfile get verifiers of perticular user field with status of each verification,
+// pass activeOnly to skip expired and revoked verifications
+func (u *UserContract) GetVerifiersOfUserProfile(ctx contractapi.TransactionContextInterface, akcessid string, profileField string, activeOnly bool) Response {
 	response := Response{
 		TxID:    ctx.GetStub().GetTxID(),
 		Success: false,
@@ -183,7 +563,7 @@ func (u *UserContract) GetVerifiersOfUserProfile(ctx contractapi.TransactionCont
 		Data:    nil,
 	}
 
-	userAsBytes, err := ctx.GetStub().GetState(akcessid)
+	userAsBytes, err := getObject(ctx, UserObject, akcessid)
 	if err != nil {
 		response.Message = fmt.Sprintf("Error while fetching user from world state: %s" + err.Error())
 		logger.Error(response.Message)
@@ -198,7 +578,14 @@ func (u *UserContract) GetVerifiersOfUserProfile(ctx contractapi.TransactionCont
 	var user User
 	json.Unmarshal(userAsBytes, &user)
 
-	response.Data = user.Verifications
+	view, err := buildVerificationsView(ctx, PolicyScopeProfile, profileField, user.Verifications[profileField], user.RevokedVerifications[profileField], user.Commitments[profileField].Commitment, activeOnly)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while evaluating verifications: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+
+	response.Data = view
 	response.Success = true
 	response.Message = fmt.Sprintf("Succesfully fetched verfiers list of user profile field %s", profileField)
 	logger.Info(response.Message)
@@ -207,7 +594,7 @@ func (u *UserContract) GetVerifiersOfUserProfile(ctx contractapi.TransactionCont
 
 // GetVerifier get verifier
 func (u *UserContract) GetVerifier(ctx contractapi.TransactionContextInterface, akcessid string) (*Verifier, error) {
-	verifierAsBytes, err := ctx.GetStub().GetState(akcessid)
+	verifierAsBytes, err := getObject(ctx, VerifierObject, akcessid)
 
 	if err != nil {
 		return nil, fmt.Errorf("Failed to read from world state. %s", err.Error())
@@ -222,6 +609,308 @@ func (u *UserContract) GetVerifier(ctx contractapi.TransactionContextInterface,
 	return &verifier, nil
 }
 
+// UpdateVerifierProfile updates name and grade of verifier and keeps previous versions of its profile.
+// Verifier can change its own name, grade can be changed only by admin
+func (u *UserContract) UpdateVerifierProfile(ctx contractapi.TransactionContextInterface, akcessid string, verifierName string, verifierGrade string) Response {
+	response := Response{
+		TxID:    ctx.GetStub().GetTxID(),
+		Success: false,
+		Message: "",
+		Data:    nil,
+	}
+
+	invoker := invokerName(ctx)
+	isAdmin := IsAdmin(ctx)
+	if !isAdmin {
+		akcessID, err := resolveAkcessID(ctx)
+		if err != nil {
+			response.Message = err.Error()
+			logger.Info(response.Message)
+			return response
+		}
+		if akcessID != akcessid {
+			response.Message = fmt.Sprintf("Identity %s can't update profile of verifier %s", invoker, akcessid)
+			logger.Info(response.Message)
+			return response
+		}
+	}
+
+	verifierAsBytes, err := getObject(ctx, VerifierObject, akcessid)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while fetching verifier from world state: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+	if verifierAsBytes == nil {
+		response.Message = fmt.Sprintf("Verifier with id %s doesn't exist", akcessid)
+		logger.Info(response.Message)
+		return response
+	}
+	var verifier Verifier
+	err = json.Unmarshal(verifierAsBytes, &verifier)
+	if err != nil || verifier.ObjectType != "verifier" {
+		response.Message = fmt.Sprintf("AKcessID %s is not a verifier", akcessid)
+		logger.Info(response.Message)
+		return response
+	}
+
+	if verifierGrade != verifier.VerifierGrade {
+		if !isAdmin {
+			response.Message = fmt.Sprintf("Grade of verifier %s can be changed only by admin", akcessid)
+			logger.Info(response.Message)
+			return response
+		}
+		taxonomy, err := getGradeTaxonomy(ctx)
+		if err != nil {
+			response.Message = fmt.Sprintf("Error while fetching grade taxonomy: %s", err.Error())
+			logger.Error(response.Message)
+			return response
+		}
+		if _, found := taxonomy.Level(verifierGrade); !found && len(taxonomy.Grades) > 0 {
+			response.Message = fmt.Sprintf("Grade %s is not part of grade taxonomy", verifierGrade)
+			logger.Info(response.Message)
+			return response
+		}
+	}
+
+	txTime, err := getTxTimestamp(ctx)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while getting tx timestamp: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+
+	verifier.Version++
+	verifier.VerifierName = verifierName
+	verifier.VerifierGrade = verifierGrade
+	verifier.ProfileHistory = append(verifier.ProfileHistory, VerifierProfileVersion{
+		Version:       verifier.Version,
+		VerifierName:  verifierName,
+		VerifierGrade: verifierGrade,
+		UpdatedBy:     invoker,
+		UpdatedAt:     txTime,
+	})
+
+	verifierAsBytes, _ = json.Marshal(verifier)
+	err = putObject(ctx, VerifierObject, akcessid, verifierAsBytes)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while updating verifier profile: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+
+	response.Success = true
+	response.Message = fmt.Sprintf("Profile of verifier %s updated to version %d", akcessid, verifier.Version)
+	logger.Info(response.Message)
+	response.Data = verifier
+	return response
+}
+
+// ApproveVerifier admin approves verifier application or reinstates suspended verifier
+func (u *UserContract) ApproveVerifier(ctx contractapi.TransactionContextInterface, akcessid string) Response {
+	return u.changeVerifierStatus(ctx, akcessid, VerifierApproved, "approved by admin")
+}
+
+// SuspendVerifier admin temporarily suspends approved verifier
+func (u *UserContract) SuspendVerifier(ctx contractapi.TransactionContextInterface, akcessid string, reason string) Response {
+	return u.changeVerifierStatus(ctx, akcessid, VerifierSuspended, reason)
+}
+
+// RevokeVerifier admin permanently revokes accreditation of verifier or rejects its application
+func (u *UserContract) RevokeVerifier(ctx contractapi.TransactionContextInterface, akcessid string, reason string) Response {
+	return u.changeVerifierStatus(ctx, akcessid, VerifierRevoked, reason)
+}
+
+// changeVerifierStatus moves verifier to given accreditation status and records the change
+func (u *UserContract) changeVerifierStatus(ctx contractapi.TransactionContextInterface, akcessid string, status string, reason string) Response {
+	response := Response{
+		TxID:    ctx.GetStub().GetTxID(),
+		Success: false,
+		Message: "",
+		Data:    nil,
+	}
+
+	invoker := invokerName(ctx)
+	if !IsAdmin(ctx) {
+		response.Message = fmt.Sprintf("Identity %s is not an admin, only admin can change verifier status", invoker)
+		logger.Info(response.Message)
+		return response
+	}
+
+	verifierAsBytes, err := getObject(ctx, VerifierObject, akcessid)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while fetching verifier from world state: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+	if verifierAsBytes == nil {
+		response.Message = fmt.Sprintf("Verifier with id %s doesn't exist", akcessid)
+		logger.Info(response.Message)
+		return response
+	}
+
+	var verifier Verifier
+	err = json.Unmarshal(verifierAsBytes, &verifier)
+	if err != nil || verifier.ObjectType != "verifier" {
+		response.Message = fmt.Sprintf("AKcessID %s is not a verifier", akcessid)
+		logger.Info(response.Message)
+		return response
+	}
+
+	current := verifier.CurrentStatus()
+	if _, allowed := Find(verifierTransitions[current], status); !allowed {
+		response.Message = fmt.Sprintf("Verifier %s can't be moved from %s to %s", akcessid, current, status)
+		logger.Info(response.Message)
+		return response
+	}
+
+	txTime, err := getTxTimestamp(ctx)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while getting tx timestamp: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+
+	verifier.Status = status
+	verifier.StatusHistory = append(verifier.StatusHistory, VerifierStatusChange{
+		Status:    status,
+		Reason:    reason,
+		ChangedBy: invoker,
+		TimeStamp: txTime,
+	})
+
+	verifierAsBytes, _ = json.Marshal(verifier)
+	err = putObject(ctx, VerifierObject, akcessid, verifierAsBytes)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while updating verifier status: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+
+	response.Success = true
+	response.Message = fmt.Sprintf("Verifier %s moved from %s to %s by %s", akcessid, current, status, invoker)
+	logger.Info(response.Message)
+	response.Data = verifier
+	return response
+}
+
+// RevokeProfileVerification verifier withdraws its own verification of user profile field
+func (u *UserContract) RevokeProfileVerification(ctx contractapi.TransactionContextInterface, userAKcessID string, profileField string, reason string) Response {
+	response := Response{
+		TxID:    ctx.GetStub().GetTxID(),
+		Success: false,
+		Message: "",
+		Data:    nil,
+	}
+
+	invoker, err := resolveAkcessID(ctx)
+	if err != nil {
+		response.Message = err.Error()
+		logger.Info(response.Message)
+		return response
+	}
+	if _, found := Find(revocationReasons, reason); !found {
+		response.Message = fmt.Sprintf("Invalid reason code %s, use one of %v", reason, revocationReasons)
+		logger.Info(response.Message)
+		return response
+	}
+
+	userAsBytes, err := getObject(ctx, UserObject, userAKcessID)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while fetching user from world state: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+	if userAsBytes == nil {
+		response.Message = fmt.Sprintf("User with id %s doesn't exist", userAKcessID)
+		logger.Info(response.Message)
+		return response
+	}
+	var user User
+	json.Unmarshal(userAsBytes, &user)
+
+	index, found := Find(VerifiersList(user.Verifications[profileField]), invoker)
+	if !found {
+		response.Message = fmt.Sprintf("Verifier %s didn't verify profile field %s of user %s", invoker, profileField, userAKcessID)
+		logger.Info(response.Message)
+		return response
+	}
+
+	txTime, err := getTxTimestamp(ctx)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while getting tx timestamp: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+
+	revoked := RevokedVerification{
+		Verification: user.Verifications[profileField][index],
+		ReasonCode:   reason,
+		RevokedBy:    invoker,
+		RevokedAt:    txTime,
+		TxID:         response.TxID,
+	}
+	if user.RevokedVerifications == nil {
+		user.RevokedVerifications = map[string][]RevokedVerification{}
+	}
+	user.RevokedVerifications[profileField] = append(user.RevokedVerifications[profileField], revoked)
+	verifications := user.Verifications[profileField]
+	user.Verifications[profileField] = append(verifications[:index:index], verifications[index+1:]...)
+
+	userAsBytes, _ = json.Marshal(user)
+	err = putObject(ctx, UserObject, userAKcessID, userAsBytes)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while revoking profile verification: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+
+	response.Success = true
+	response.Message = fmt.Sprintf("Verification of profile field %s of user %s revoked by %s", profileField, userAKcessID, invoker)
+	logger.Info(response.Message)
+	response.Data = revoked
+	return response
+}
+
+// GetRevokedProfileVerifications get verifications withdrawn by verifiers from user profile,
+// pass empty profileField to get revocations of all fields
+func (u *UserContract) GetRevokedProfileVerifications(ctx contractapi.TransactionContextInterface, akcessid string, profileField string) Response {
+	response := Response{
+		TxID:    ctx.GetStub().GetTxID(),
+		Success: false,
+		Message: "",
+		Data:    nil,
+	}
+
+	userAsBytes, err := getObject(ctx, UserObject, akcessid)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while fetching user from world state: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+	if userAsBytes == nil {
+		response.Message = fmt.Sprintf("AKcessID %s doesn't exist", akcessid)
+		logger.Info(response.Message)
+		return response
+	}
+
+	var user User
+	json.Unmarshal(userAsBytes, &user)
+
+	result := map[string][]RevokedVerification{}
+	for field, revoked := range user.RevokedVerifications {
+		if profileField == "" || profileField == field {
+			result[field] = revoked
+		}
+	}
+
+	response.Data = result
+	response.Success = true
+	response.Message = fmt.Sprintf("Successfully fetched revoked verifications of user %s", akcessid)
+	logger.Info(response.Message)
+	return response
+}
+
 // DeleteVerification deletes the verification from user profile
 func (u *UserContract) DeleteVerification(ctx contractapi.TransactionContextInterface, profileField string) Response {
 	response := Response{
@@ -231,8 +920,13 @@ func (u *UserContract) DeleteVerification(ctx contractapi.TransactionContextInte
 		Data:    nil,
 	}
 
-	invoker, _ := getCommonName(ctx)
-	userAsBytes, err := ctx.GetStub().GetState(invoker)
+	invoker, err := resolveAkcessID(ctx)
+	if err != nil {
+		response.Message = err.Error()
+		logger.Info(response.Message)
+		return response
+	}
+	userAsBytes, err := getObject(ctx, UserObject, invoker)
 	if err != nil {
 		response.Message = fmt.Sprintf("Error while fetching user from world state: %s" + err.Error())
 		logger.Error(response.Message)
@@ -248,7 +942,7 @@ func (u *UserContract) DeleteVerification(ctx contractapi.TransactionContextInte
 	json.Unmarshal(userAsBytes, &user)
 	user.Verifications[profileField] = []Verification{}
 	newUserAsBytes, _ := json.Marshal(user)
-	err = ctx.GetStub().PutState(invoker, newUserAsBytes)
+	err = putObject(ctx, UserObject, invoker, newUserAsBytes)
 	if err != nil {
 		response.Message = fmt.Sprintf("Error while deleting profile field verification: %s" + err.Error())
 		logger.Error(response.Message)
@@ -261,7 +955,10 @@ func (u *UserContract) DeleteVerification(ctx contractapi.TransactionContextInte
 	return response
 }
 
-// DeleteUser deletes the user from Blockchain world state
+// DeleteUser erases the user from Blockchain world state. Only the user or an admin can erase it.
+// Profile values and OTPs are deleted from private data collections, shares sent by the user are deleted,
+// the user is removed from receivers of other shares, its signatures are anonymized and its identities
+// are unbound unless the AKcessID is also a verifier. A tombstone is left so the AKcessID can't be registered again by another identity
 func (u *UserContract) DeleteUser(ctx contractapi.TransactionContextInterface, key string) Response {
 	response := Response{
 		TxID:    ctx.GetStub().GetTxID(),
@@ -270,28 +967,81 @@ func (u *UserContract) DeleteUser(ctx contractapi.TransactionContextInterface, k
 		Data:    nil,
 	}
 
-	userAsBytes, err := ctx.GetStub().GetState(key)
+	// only AKcessID bound to invoker counts, its display name may collide with AKcessID containing "::"
+	invoker := invokerName(ctx)
+	akcessID, err := resolveAkcessID(ctx)
+	if !IsAdmin(ctx) {
+		if err != nil {
+			response.Message = err.Error()
+			logger.Info(response.Message)
+			return response
+		}
+		if akcessID != key {
+			response.Message = fmt.Sprintf("Identity %s can't delete user %s, only the user or an admin can", invoker, key)
+			logger.Info(response.Message)
+			return response
+		}
+	}
+
+	userAsBytes, err := getObject(ctx, UserObject, key)
 	if err != nil {
-		response.Message = fmt.Sprintf("Error while fetching data from world state: %s" + err.Error())
+		response.Message = fmt.Sprintf("Error while fetching data from world state: %s", err.Error())
 		logger.Error(response.Message)
 		return response
 	}
 	if userAsBytes == nil {
-		response.Message = fmt.Sprintf("Key %s doesn't exist", key)
+		response.Message = fmt.Sprintf("User %s doesn't exist", key)
 		logger.Info(response.Message)
 		return response
 	}
+	var user User
+	json.Unmarshal(userAsBytes, &user)
 
-	err = ctx.GetStub().DelState(key)
+	txTime, err := getTxTimestamp(ctx)
 	if err != nil {
-		response.Message = fmt.Sprintf("Error while deleting data: %s" + err.Error())
+		response.Message = fmt.Sprintf("Error while getting tx timestamp: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+
+	result, err := eraseUserData(ctx, key)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while erasing data of user %s: %s", key, err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+
+	err = deleteObject(ctx, UserObject, key)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while deleting data: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+
+	// users registered before identity hashes were recorded can only be claimed back by themselves
+	identityHash := user.IdentityHash
+	if identityHash == "" && akcessID == key {
+		identityHash, _ = getIdentityHash(ctx)
+	}
+	result.Tombstone = UserTombstone{
+		ObjectType:   "tombstone",
+		AkcessID:     key,
+		IdentityHash: identityHash,
+		ErasedBy:     invoker,
+		ErasedAt:     txTime,
+	}
+	tombstoneAsBytes, _ := json.Marshal(result.Tombstone)
+	err = putObject(ctx, TombstoneObject, key, tombstoneAsBytes)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while saving tombstone: %s", err.Error())
 		logger.Error(response.Message)
 		return response
 	}
 
 	response.Success = true
-	response.Message = fmt.Sprintf("Key %s deleted", key)
+	response.Message = fmt.Sprintf("User %s erased", key)
 	logger.Info(response.Message)
+	response.Data = result
 	return response
 }
 
@@ -332,3 +1082,42 @@ func (u *UserContract) GetAllVerifiers(ctx contractapi.TransactionContextInterfa
 	response.Data = result
 	return response
 }
+
+// GetVerifiersByStatus returns all verifiers with given accreditation status
+func (u *UserContract) GetVerifiersByStatus(ctx contractapi.TransactionContextInterface, status string) Response {
+	response := Response{
+		TxID:    ctx.GetStub().GetTxID(),
+		Success: false,
+		Message: "",
+		Data:    nil,
+	}
+
+	var richQuery string = fmt.Sprintf(`{
+		"selector": {
+		   "docType": "verifier",
+		   "status": "%s"
+		}
+	}`, status)
+	resultIterator, err := ctx.GetStub().GetQueryResult(richQuery)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while fetching query result: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+	defer resultIterator.Close()
+
+	var result []Verifier
+	for resultIterator.HasNext() {
+		queryResponse, _ := resultIterator.Next()
+
+		v := new(Verifier)
+		_ = json.Unmarshal(queryResponse.Value, v)
+		result = append(result, *v)
+	}
+
+	response.Success = true
+	response.Message = fmt.Sprintf("Successfully fetched %s verifiers", status)
+	logger.Info(response.Message)
+	response.Data = result
+	return response
+}
@@ -0,0 +1,305 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+func TestAddUserProfileVerificationWithCommitments(t *testing.T) {
+	l := newTestLedger(t)
+	l.initLedger()
+	commitment := saltedHash("salt", "bob@example.com")
+	expectResponse(t, new(UserContract).CreateUser(l.as("Org1MSP", "bob", nil)), "")
+	expectResponse(t, new(UserContract).SetProfileFieldCommitment(l.as("Org1MSP", "bob", nil), "email", commitment), "")
+	expectResponse(t, new(UserContract).CreateVerifier(l.as("Org2MSP", "vera", verifierAttrs), "Vera", "gold"), "")
+	expectResponse(t, new(UserContract).ApproveVerifier(l.as("AdminMSP", "admin", nil), "vera"), "")
+	expectResponse(t, new(UserContract).CreateVerifier(l.as("Org2MSP", "vic", verifierAttrs), "Vic", "gold"), "")
+	expiry := testTime.AddDate(1, 0, 0).Format(time.RFC3339)
+
+	tests := []struct {
+		name          string
+		invoker       string
+		verifier      string
+		user          string
+		field         string
+		expiry        string
+		commitment    string
+		expectedError string
+	}{
+		{
+			name:          "verifier can't verify on behalf of other verifier",
+			invoker:       "vera",
+			verifier:      "vic",
+			user:          "bob",
+			field:         "email",
+			expiry:        expiry,
+			commitment:    commitment,
+			expectedError: "Identity vera can't add verification on behalf of verifier vic",
+		},
+		{
+			name:          "verifier must be approved",
+			invoker:       "vic",
+			verifier:      "vic",
+			user:          "bob",
+			field:         "email",
+			expiry:        expiry,
+			commitment:    commitment,
+			expectedError: "Verifier vic is not approved",
+		},
+		{
+			name:          "user must exist",
+			invoker:       "vera",
+			verifier:      "vera",
+			user:          "carol",
+			field:         "email",
+			expiry:        expiry,
+			commitment:    commitment,
+			expectedError: "doesn't exist",
+		},
+		{
+			name:          "commitment can't be empty",
+			invoker:       "vera",
+			verifier:      "vera",
+			user:          "bob",
+			field:         "email",
+			expiry:        expiry,
+			expectedError: "Commitment of profile field email can't be empty",
+		},
+		{
+			name:          "user must have committed to field",
+			invoker:       "vera",
+			verifier:      "vera",
+			user:          "bob",
+			field:         "phone",
+			expiry:        expiry,
+			commitment:    commitment,
+			expectedError: "User bob has no commitment for profile field phone",
+		},
+		{
+			name:          "commitment must match user's commitment",
+			invoker:       "vera",
+			verifier:      "vera",
+			user:          "bob",
+			field:         "email",
+			expiry:        expiry,
+			commitment:    saltedHash("salt", "mallory@example.com"),
+			expectedError: "doesn't match current commitment of profile field email",
+		},
+		{
+			name:          "expiry date must be in the future",
+			invoker:       "vera",
+			verifier:      "vera",
+			user:          "bob",
+			field:         "email",
+			expiry:        testTime.AddDate(-1, 0, 0).Format(time.RFC3339),
+			commitment:    commitment,
+			expectedError: "is not in the future",
+		},
+		{
+			name:       "approved verifier verifies committed field",
+			invoker:    "vera",
+			verifier:   "vera",
+			user:       "bob",
+			field:      "email",
+			expiry:     expiry,
+			commitment: commitment,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := l.as("Org2MSP", tt.invoker, verifierAttrs)
+			response := new(UserContract).AddUserProfileVerificationWithCommitments(ctx, tt.verifier, tt.user, []string{tt.field}, []string{tt.expiry}, []string{tt.commitment})
+			expectResponse(t, response, tt.expectedError)
+		})
+	}
+
+	user, err := getUser(l.as("Org1MSP", "bob", nil), "bob")
+	expectErr(t, err, "")
+	verifications := user.Verifications["email"]
+	if len(verifications) != 1 || verifications[0].VerifierID != "vera" || verifications[0].Commitment != commitment {
+		t.Fatalf("expected one verification of email by vera, got %+v", verifications)
+	}
+}
+
+func TestAddUserProfileVerification(t *testing.T) {
+	l := newTestLedger(t)
+	l.initLedger()
+	expectResponse(t, new(UserContract).CreateUser(l.as("Org1MSP", "bob", nil)), "")
+	expectResponse(t, new(UserContract).SetProfileFieldCommitment(l.as("Org1MSP", "bob", nil), "email", saltedHash("salt", "bob@example.com")), "")
+	expectResponse(t, new(UserContract).CreateVerifier(l.as("Org2MSP", "vera", verifierAttrs), "Vera", "gold"), "")
+	expectResponse(t, new(UserContract).ApproveVerifier(l.as("AdminMSP", "admin", nil), "vera"), "")
+	expiry := testTime.AddDate(1, 0, 0).Format(time.RFC3339)
+
+	tests := []struct {
+		name          string
+		fields        []string
+		expiryDates   []string
+		expectedError string
+	}{
+		{name: "expiry date of every field", fields: []string{"phone", "dob"}, expiryDates: []string{expiry}, expectedError: "Pass one expiry date for each profile field"},
+		{name: "committed field needs commitment", fields: []string{"email"}, expiryDates: []string{expiry}, expectedError: "User bob has commitment for profile field email, verify it with AddUserProfileVerificationWithCommitments"},
+		{name: "field without commitment", fields: []string{"phone"}, expiryDates: []string{expiry}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := l.as("Org2MSP", "vera", verifierAttrs)
+			expectResponse(t, new(UserContract).AddUserProfileVerification(ctx, "vera", "bob", tt.fields, tt.expiryDates), tt.expectedError)
+		})
+	}
+
+	user, err := getUser(l.as("Org1MSP", "bob", nil), "bob")
+	expectErr(t, err, "")
+	if verifications := user.Verifications["phone"]; len(verifications) != 1 || verifications[0].VerifierID != "vera" || verifications[0].Commitment != "" {
+		t.Fatalf("expected one verification of phone by vera without commitment, got %+v", verifications)
+	}
+	if verifications := user.Verifications["email"]; len(verifications) != 0 {
+		t.Fatalf("expected committed email not to be verified, got %+v", verifications)
+	}
+}
+
+func TestCheckProfileFieldCommitment(t *testing.T) {
+	l := newTestLedger(t)
+	expectResponse(t, new(UserContract).CreateUser(l.as("Org1MSP", "bob", nil)), "")
+	expectResponse(t, new(UserContract).SetProfileFieldCommitment(l.as("Org1MSP", "bob", nil), "email", "not a hash"), "Commitment must be hex encoded SHA-256 hash")
+	expectResponse(t, new(UserContract).SetProfileFieldCommitment(l.as("Org1MSP", "bob", nil), "email", saltedHash("salt", "bob@example.com")), "")
+
+	tests := []struct {
+		name          string
+		field         string
+		value         string
+		salt          string
+		matches       bool
+		expectedError string
+	}{
+		{name: "value and salt match", field: "email", value: "bob@example.com", salt: "salt", matches: true},
+		{name: "other value", field: "email", value: "mallory@example.com", salt: "salt"},
+		{name: "other salt", field: "email", value: "bob@example.com", salt: "pepper"},
+		{name: "field without commitment", field: "phone", value: "bob@example.com", salt: "salt", expectedError: "User bob has no commitment for profile field phone"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := new(UserContract).CheckProfileFieldCommitment(l.as("Org3MSP", "checker", nil), "bob", tt.field, tt.value, tt.salt)
+			expectResponse(t, response, tt.expectedError)
+			if tt.expectedError != "" {
+				return
+			}
+			if result := response.Data.(CommitmentCheck); result.Matches != tt.matches {
+				t.Fatalf("expected match %v, got %+v", tt.matches, result)
+			}
+		})
+	}
+}
+
+func TestDeleteUser(t *testing.T) {
+	tests := []struct {
+		name          string
+		invoker       func(l *testLedger) contractapi.TransactionContextInterface
+		key           string
+		expectedError string
+	}{
+		{
+			name:    "user deletes itself",
+			invoker: func(l *testLedger) contractapi.TransactionContextInterface { return l.as("Org1MSP", "bob", nil) },
+			key:     "bob",
+		},
+		{
+			name:          "other user",
+			invoker:       func(l *testLedger) contractapi.TransactionContextInterface { return l.as("Org1MSP", "mallory", nil) },
+			key:           "bob",
+			expectedError: "Identity mallory can't delete user bob, only the user or an admin can",
+		},
+		{
+			name:          "unbound identity named as AKcessID",
+			invoker:       func(l *testLedger) contractapi.TransactionContextInterface { return l.as("Org2MSP", "mallory", nil) },
+			key:           "Org2MSP::mallory",
+			expectedError: "Identity Org2MSP::mallory is not bound to any AKcessID",
+		},
+		{
+			name:    "admin",
+			invoker: func(l *testLedger) contractapi.TransactionContextInterface { return l.as("AdminMSP", "admin", nil) },
+			key:     "Org2MSP::mallory",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := newTestLedger(t)
+			l.initLedger()
+			expectResponse(t, new(UserContract).CreateUser(l.as("Org1MSP", "bob", nil)), "")
+			expectResponse(t, new(UserContract).CreateUser(l.as("Org1MSP", "mallory", nil)), "")
+			expectResponse(t, new(UserContract).CreateUser(l.as("Org1MSP", "carol", map[string]string{"akcessId": "Org2MSP::mallory"})), "")
+			expectResponse(t, new(UserContract).DeleteUser(tt.invoker(l), tt.key), tt.expectedError)
+			userAsBytes, _ := getObject(l.as("Org1MSP", "bob", nil), UserObject, tt.key)
+			if exists := userAsBytes != nil; exists != (tt.expectedError != "") {
+				t.Fatalf("expected user %s to exist %t, got %t", tt.key, tt.expectedError != "", exists)
+			}
+		})
+	}
+}
+
+func TestUpdateVerifierProfile(t *testing.T) {
+	tests := []struct {
+		name          string
+		invoker       func(l *testLedger) contractapi.TransactionContextInterface
+		verifierName  string
+		verifierGrade string
+		expectedError string
+	}{
+		{
+			name:          "verifier renames itself",
+			invoker:       func(l *testLedger) contractapi.TransactionContextInterface { return l.as("Org1MSP", "vera", nil) },
+			verifierName:  "Vera Ltd",
+			verifierGrade: "gold",
+		},
+		{
+			name:          "verifier changes its grade",
+			invoker:       func(l *testLedger) contractapi.TransactionContextInterface { return l.as("Org1MSP", "vera", nil) },
+			verifierName:  "Vera",
+			verifierGrade: "platinum",
+			expectedError: "Grade of verifier Org2MSP::vic can be changed only by admin",
+		},
+		{
+			name:          "other user",
+			invoker:       func(l *testLedger) contractapi.TransactionContextInterface { return l.as("Org1MSP", "mallory", nil) },
+			verifierName:  "Mallory",
+			verifierGrade: "gold",
+			expectedError: "Identity mallory can't update profile of verifier Org2MSP::vic",
+		},
+		{
+			name:          "unbound identity named as AKcessID",
+			invoker:       func(l *testLedger) contractapi.TransactionContextInterface { return l.as("Org2MSP", "vic", nil) },
+			verifierName:  "Vic",
+			verifierGrade: "gold",
+			expectedError: "Identity Org2MSP::vic is not bound to any AKcessID",
+		},
+		{
+			name:          "admin changes grade",
+			invoker:       func(l *testLedger) contractapi.TransactionContextInterface { return l.as("AdminMSP", "admin", nil) },
+			verifierName:  "Vera",
+			verifierGrade: "platinum",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := newTestLedger(t)
+			l.initLedger()
+			expectResponse(t, new(UserContract).CreateUser(l.as("Org1MSP", "mallory", nil)), "")
+			vera := l.as("Org1MSP", "vera", map[string]string{"isVerifier": "true", "akcessId": "Org2MSP::vic"})
+			expectResponse(t, new(UserContract).CreateVerifier(vera, "Vera", "gold"), "")
+			expectResponse(t, new(UserContract).UpdateVerifierProfile(tt.invoker(l), "Org2MSP::vic", tt.verifierName, tt.verifierGrade), tt.expectedError)
+			verifier, _ := new(UserContract).GetVerifier(l.as("Org1MSP", "mallory", nil), "Org2MSP::vic")
+			if tt.expectedError == "" && (verifier.VerifierName != tt.verifierName || verifier.VerifierGrade != tt.verifierGrade) {
+				t.Fatalf("expected verifier %s of grade %s, got %+v", tt.verifierName, tt.verifierGrade, verifier)
+			}
+			if tt.expectedError != "" && (verifier.VerifierName != "Vera" || verifier.VerifierGrade != "gold") {
+				t.Fatalf("expected verifier profile to stay unchanged, got %+v", verifier)
+			}
+		})
+	}
+}
@@ -1,7 +1,10 @@
 package main
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"time"
 
 	"github.com/hyperledger/fabric-contract-api-go/contractapi"
 )
@@ -21,10 +24,37 @@ func UnknownTransactionHandler(ctx contractapi.TransactionContextInterface) erro
 	return fmt.Errorf("Invalid function %s passed with args %v", fcn, args)
 }
 
-func getCommonName(ctx contractapi.TransactionContextInterface) (string, error) {
-	x509, err := ctx.GetClientIdentity().GetX509Certificate()
+// getTxTimestamp returns the transaction timestamp set by the client in the proposal.
+// It is the same on every endorsing peer, so it is safe to store in world state
+func getTxTimestamp(ctx contractapi.TransactionContextInterface) (time.Time, error) {
+	ts, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(ts.Seconds, int64(ts.Nanos)).UTC(), nil
+}
+
+// saltedHash returns hex encoded SHA-256 hash of salt followed by value
+func saltedHash(salt string, value string) string {
+	hash := sha256.Sum256([]byte(salt + value))
+	return hex.EncodeToString(hash[:])
+}
+
+// isHexHash checks if value is hex encoded SHA-256 hash
+func isHexHash(value string) bool {
+	decoded, err := hex.DecodeString(value)
+	return err == nil && len(decoded) == sha256.Size
+}
+
+// getIdentityHash returns hash of MSP ID and unique ID of identity invoking transaction
+func getIdentityHash(ctx contractapi.TransactionContextInterface) (string, error) {
+	mspID, err := ctx.GetClientIdentity().GetMSPID()
+	if err != nil {
+		return "", err
+	}
+	id, err := ctx.GetClientIdentity().GetID()
 	if err != nil {
 		return "", err
 	}
-	return x509.Subject.CommonName, nil
+	return saltedHash(mspID, id), nil
 }
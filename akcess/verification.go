@@ -0,0 +1,164 @@
+package main
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// Verification statuses evaluated at transaction timestamp
+const (
+	VerificationActive    = "active"
+	VerificationExpired   = "expired"
+	VerificationSuspended = "suspended" // verifier accreditation is suspended
+	VerificationRevoked   = "revoked"
+	VerificationStale     = "stale" // attested commitment no longer matches profile field value or current document version
+)
+
+// VerifierInfo live details of verifier joined to verification on read
+type VerifierInfo struct {
+	AkcessID      string `json:"akcessId"`
+	VerifierName  string `json:"verifierName"`
+	VerifierGrade string `json:"grade"`
+	Status        string `json:"status"`
+	Version       int    `json:"version"`
+}
+
+// VerificationStatus verification together with live verifier details and its status at transaction timestamp
+type VerificationStatus struct {
+	Verification
+	Verifier      *VerifierInfo `json:"verifier"`      // nil when verifier is no longer registered
+	AttestedGrade string        `json:"attestedGrade"` // grade verifier held when verification was made
+	Status        string        `json:"status"`
+	ReasonCode    string        `json:"reasonCode,omitempty"` // set for revoked verifications
+	RevokedAt     *time.Time    `json:"revokedAt,omitempty"`
+}
+
+// VerificationsView verifications returned by queries with their status, policy compliance
+// and summary of whether subject currently counts as verified
+type VerificationsView struct {
+	Verifications []VerificationStatus `json:"verifications"`
+	ActiveCount   int                  `json:"activeCount"`
+	Verified      bool                 `json:"verified"` // has active verification meeting policy
+	Policy        PolicyCompliance     `json:"policy"`
+	// effective lifecycle status of verified document, verifications of document which is not active don't count
+	DocumentStatus string `json:"documentStatus,omitempty"`
+}
+
+// loadVerifiers reads verifiers referenced by verifications from verifier registry,
+// verifiers which are not registered anymore are nil in returned map
+func loadVerifiers(ctx contractapi.TransactionContextInterface, verifications []Verification) (map[string]*Verifier, error) {
+	verifiers := map[string]*Verifier{}
+	for _, v := range verifications {
+		if _, loaded := verifiers[v.VerifierID]; loaded {
+			continue
+		}
+		verifierAsBytes, err := getObject(ctx, VerifierObject, v.VerifierID)
+		if err != nil {
+			return nil, err
+		}
+		verifier := new(Verifier)
+		if verifierAsBytes != nil {
+			_ = json.Unmarshal(verifierAsBytes, verifier)
+		}
+		if verifier.ObjectType != "verifier" {
+			verifier = nil
+		}
+		verifiers[v.VerifierID] = verifier
+	}
+	return verifiers, nil
+}
+
+// evaluateVerification joins verification with its verifier and evaluates its status at given time
+func evaluateVerification(v Verification, verifier *Verifier, now time.Time) VerificationStatus {
+	result := VerificationStatus{
+		Verification: v,
+		Status:       VerificationActive,
+	}
+	if verifier == nil {
+		result.Status = VerificationRevoked
+		result.ReasonCode = "verifier-not-registered"
+		return result
+	}
+
+	result.Verifier = &VerifierInfo{
+		AkcessID:      verifier.AkcessID,
+		VerifierName:  verifier.VerifierName,
+		VerifierGrade: verifier.VerifierGrade,
+		Status:        verifier.CurrentStatus(),
+		Version:       verifier.Version,
+	}
+	result.AttestedGrade = verifier.VerifierGrade
+	for _, version := range verifier.ProfileHistory {
+		if version.Version == v.VerifierVersion {
+			result.AttestedGrade = version.VerifierGrade
+			break
+		}
+	}
+
+	switch {
+	case verifier.CurrentStatus() == VerifierRevoked:
+		result.Status = VerificationRevoked
+		result.ReasonCode = "verifier-revoked"
+	case verifier.CurrentStatus() == VerifierSuspended:
+		result.Status = VerificationSuspended
+	case !v.ExpirtyDate.After(now):
+		result.Status = VerificationExpired
+	}
+	return result
+}
+
+// buildVerificationsView evaluates verifications and revocations of a profile field, document or asset
+// at tx timestamp and checks active ones against verification policy of scope and target.
+// When commitment or document hash is given, verifications attesting other commitment are stale
+func buildVerificationsView(ctx contractapi.TransactionContextInterface, scope string, target string, verifications []Verification, revoked []RevokedVerification, commitment string, activeOnly bool) (VerificationsView, error) {
+	view := VerificationsView{
+		Verifications: []VerificationStatus{},
+	}
+
+	now, err := getTxTimestamp(ctx)
+	if err != nil {
+		return view, err
+	}
+
+	all := append([]Verification{}, verifications...)
+	for _, r := range revoked {
+		all = append(all, r.Verification)
+	}
+	verifiers, err := loadVerifiers(ctx, all)
+	if err != nil {
+		return view, err
+	}
+
+	activeGrades := []string{}
+	for _, v := range verifications {
+		status := evaluateVerification(v, verifiers[v.VerifierID], now)
+		if status.Status == VerificationActive && commitment != "" && v.Commitment != commitment {
+			status.Status = VerificationStale
+		}
+		if status.Status == VerificationActive {
+			activeGrades = append(activeGrades, status.AttestedGrade)
+		} else if activeOnly {
+			continue
+		}
+		view.Verifications = append(view.Verifications, status)
+	}
+	if !activeOnly {
+		for i := range revoked {
+			status := evaluateVerification(revoked[i].Verification, verifiers[revoked[i].Verification.VerifierID], now)
+			status.Status = VerificationRevoked
+			status.ReasonCode = revoked[i].ReasonCode
+			status.RevokedAt = &revoked[i].RevokedAt
+			view.Verifications = append(view.Verifications, status)
+		}
+	}
+
+	view.ActiveCount = len(activeGrades)
+	view.Policy, err = policyCompliance(ctx, scope, target, activeGrades)
+	if err != nil {
+		return view, err
+	}
+	view.Verified = view.Policy.MeetsPolicy
+	return view, nil
+}
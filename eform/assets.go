@@ -1,6 +1,7 @@
 package main
 
 import (
+	"encoding/json"
 	"fmt"
 	"strconv"
 	"time"
@@ -20,19 +21,22 @@ type Eform struct {
 
 // Signature structure
 type Signature struct {
-	SignatureHash string    `json:"signatureHash"`
-	OTP           string    `json:"otp"`
+	SignatureHash string    `json:"signatureHash"`          // base64 encoded signature over current eform hash
+	KeyID         string    `json:"keyId"`                  // signing key of signer registered in akcess chaincode
+	OTPHash       string    `json:"otpHash"`                // salted hash of OTP, salt is kept in private data collection
+	OTPChallenge  string    `json:"otpChallenge,omitempty"` // ID of OTP challenge signer answered
 	AkcessID      string    `json:"akcessId"`
 	TimeStamp     time.Time `json:"timeStamp"`
+	TxTimestamp   time.Time `json:"txTimestamp"` // tx timestamp of signing transaction
 }
 
 // EformShare eform object for share eform
 type EformShare struct {
-	ObjectType string   `json:"docType"`
-	SharingID  string   `jaon:"sharingid"`
-	Sender     string   `json:"sender"`
-	Receivers  []string `json:"receivers"`
-	EformID    string   `json:"eformId"`
+	ObjectType     string   `json:"docType"`
+	SharingID      string   `jaon:"sharingid"`
+	Sender         string   `json:"sender"`
+	ReceiverHashes []string `json:"receiverHashes"` // salted hashes of receivers, receivers are kept in private data collection
+	EformID        string   `json:"eformId"`
 }
 
 // Verifier schema
@@ -41,12 +45,89 @@ type Verifier struct {
 	AkcessID      string `json:"akcessId"`
 	VerifierName  string `json:"verifierName"`
 	VerifierGrade string `json:"grade"`
+	Status        string `json:"status"`  // accreditation status managed by akcess chaincode
+	Version       int    `json:"version"` // version of verifier profile in akcess chaincode
 }
 
-// Verification schema
+// Verification schema, verifier details are joined from akcess verifier registry on read
 type Verification struct {
-	VerifierObj Verifier  `json:"verifier"`
-	ExpirtyDate time.Time `json:"expiryDate"`
+	VerifierID      string    `json:"verifierId"`
+	VerifierVersion int       `json:"verifierVersion"` // version of verifier profile at attestation time
+	ExpirtyDate     time.Time `json:"expiryDate"`
+	TxTimestamp     time.Time `json:"txTimestamp"` // tx timestamp of verifying transaction
+}
+
+// UnmarshalJSON reads verifications stored before verifier references were introduced,
+// those embed full verifier snapshot instead of verifierId
+func (v *Verification) UnmarshalJSON(data []byte) error {
+	type verification Verification
+	var stored struct {
+		verification
+		LegacyVerifier *Verifier `json:"verifier"`
+	}
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return err
+	}
+	*v = Verification(stored.verification)
+	if v.VerifierID == "" && stored.LegacyVerifier != nil {
+		v.VerifierID = stored.LegacyVerifier.AkcessID
+	}
+	return nil
+}
+
+// Verification statuses evaluated at transaction timestamp
+const (
+	VerificationActive    = "active"
+	VerificationExpired   = "expired"
+	VerificationSuspended = "suspended"
+	VerificationRevoked   = "revoked"
+)
+
+// VerificationStatus verification together with live verifier details and its status at transaction timestamp
+type VerificationStatus struct {
+	Verification
+	Verifier *Verifier `json:"verifier"` // nil when verifier is no longer registered
+	Status   string    `json:"status"`
+}
+
+// VerificationsView verifications returned by queries with their status and summary
+// of whether eform currently counts as verified
+type VerificationsView struct {
+	Verifications []VerificationStatus `json:"verifications"`
+	ActiveCount   int                  `json:"activeCount"`
+	Verified      bool                 `json:"verified"`
+}
+
+// EvaluateVerifications joins verifications with live verifiers and computes status of each one
+// at given time, inactive ones are skipped when activeOnly is set
+func EvaluateVerifications(verifications []Verification, verifiers map[string]*Verifier, now time.Time, activeOnly bool) VerificationsView {
+	view := VerificationsView{
+		Verifications: []VerificationStatus{},
+	}
+	for _, v := range verifications {
+		verifier := verifiers[v.VerifierID]
+		status := VerificationActive
+		switch {
+		case verifier == nil || verifier.Status == "revoked":
+			status = VerificationRevoked
+		case verifier.Status == "suspended":
+			status = VerificationSuspended
+		case !v.ExpirtyDate.After(now):
+			status = VerificationExpired
+		}
+		if status == VerificationActive {
+			view.ActiveCount++
+		} else if activeOnly {
+			continue
+		}
+		view.Verifications = append(view.Verifications, VerificationStatus{
+			Verification: v,
+			Verifier:     verifier,
+			Status:       status,
+		})
+	}
+	view.Verified = view.ActiveCount > 0
+	return view
 }
 
 // Find check if item already exists in slice
@@ -63,11 +144,16 @@ func Find(slice []string, val string) (int, bool) {
 func VerifiersList(v []Verification) []string {
 	var list []string
 	for _, verification := range v {
-		list = append(list, verification.VerifierObj.AkcessID)
+		list = append(list, verification.VerifierID)
 	}
 	return list
 }
 
+// IsApproved checks if verifier is currently allowed to make verifications
+func (v Verifier) IsApproved() bool {
+	return v.Status == "approved"
+}
+
 // IsVerifier checks if user who is invoking transaction is verifier or not
 func IsVerifier(ctx contractapi.TransactionContextInterface) bool {
 	isVerifier, attr, err := ctx.GetClientIdentity().GetAttributeValue("isVerifier")
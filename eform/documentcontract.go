@@ -6,7 +6,8 @@ import (
 	"time"
 
 	"github.com/hyperledger/fabric-contract-api-go/contractapi"
-	"github.com/hyperledger/fabric/common/util"
+
+	"otp"
 )
 
 // EformContract contract for storing user in blockchain
@@ -23,8 +24,13 @@ func (d *EformContract) CreateEform(ctx contractapi.TransactionContextInterface,
 		Data:    nil,
 	}
 
-	invoker, _ := getCommonName(ctx)
-	eformAsBytes, err := ctx.GetStub().GetState(eformid)
+	invoker, err := resolveAkcessID(ctx)
+	if err != nil {
+		response.Message = err.Error()
+		logger.Info(response.Message)
+		return response
+	}
+	eformAsBytes, err := getObject(ctx, EformObject, eformid)
 	if err != nil {
 		response.Message = fmt.Sprintf("Error while fetching doc from world state: %s" + err.Error())
 		logger.Error(response.Message)
@@ -46,12 +52,18 @@ func (d *EformContract) CreateEform(ctx contractapi.TransactionContextInterface,
 	}
 
 	newEformAsBytes, _ := json.Marshal(eform)
-	err = ctx.GetStub().PutState(eformid, newEformAsBytes)
+	err = putObject(ctx, EformObject, eformid, newEformAsBytes)
 	if err != nil {
 		response.Message = fmt.Sprintf("Error while creating eform: %s" + err.Error())
 		logger.Error(response.Message)
 		return response
 	}
+	err = indexEformHashes(ctx, newEformAsBytes)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while indexing eform hash: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
 
 	response.Success = true
 	response.Message = fmt.Sprintf("Eform with id %s created", eformid)
@@ -59,8 +71,11 @@ func (d *EformContract) CreateEform(ctx contractapi.TransactionContextInterface,
 	return response
 }
 
-// SignEform signs the eform
-func (d *EformContract) SignEform(ctx contractapi.TransactionContextInterface, eformid string, signhash string, signDate string, otpCode string) Response {
+// SignEform signs the eform with base64 encoded signature over its current hash, made with one of signer's
+// active signing keys. OTP is passed in transient data under "otp" key and must answer unexpired challenge
+// issued to signer with IssueOTPChallenge. Wrong OTP fails signing but is recorded as failed attempt on signer's
+// open challenges
+func (d *EformContract) SignEform(ctx contractapi.TransactionContextInterface, eformid string, signature string, signDate string) Response {
 	response := Response{
 		TxID:    ctx.GetStub().GetTxID(),
 		Success: false,
@@ -68,8 +83,13 @@ func (d *EformContract) SignEform(ctx contractapi.TransactionContextInterface, e
 		Data:    nil,
 	}
 
-	invoker, _ := getCommonName(ctx)
-	eformAsBytes, err := ctx.GetStub().GetState(eformid)
+	invoker, err := resolveAkcessID(ctx)
+	if err != nil {
+		response.Message = err.Error()
+		logger.Info(response.Message)
+		return response
+	}
+	eformAsBytes, err := getObject(ctx, EformObject, eformid)
 	if err != nil {
 		response.Message = fmt.Sprintf("Error while fetching eform from world state: %s" + err.Error())
 		logger.Error(response.Message)
@@ -87,18 +107,70 @@ func (d *EformContract) SignEform(ctx contractapi.TransactionContextInterface, e
 		logger.Error(response.Message)
 		return response
 	}
+	txTime, err := getTxTimestamp(ctx)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while getting tx timestamp: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+	policy, err := getTimePolicy(ctx)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while fetching time policy: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+	err = policy.CheckSignDate(signdate, txTime)
+	if err != nil {
+		response.Message = err.Error()
+		logger.Info(response.Message)
+		return response
+	}
 
 	var eform Eform
 	json.Unmarshal(eformAsBytes, &eform)
-	signature := Signature{
-		SignatureHash: signhash,
-		OTP:           otpCode,
+	if len(eform.EformHash) == 0 {
+		response.Message = fmt.Sprintf("Eform %s has no hash to sign", eformid)
+		logger.Info(response.Message)
+		return response
+	}
+	keyID, err := verifyUserSignature(ctx, invoker, eform.EformHash[len(eform.EformHash)-1], signature)
+	if err != nil {
+		response.Message = err.Error()
+		logger.Info(response.Message)
+		return response
+	}
+
+	otpCode, err := getTransientValue(ctx, "otp")
+	if err != nil {
+		response.Message = err.Error()
+		logger.Info(response.Message)
+		return response
+	}
+	collections, err := getPrivateDataConfig(ctx)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while fetching private data config: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+	challenge, err := otp.Consume(ctx, collections.OTPCollection, "eform", eformid, invoker, otpCode, txTime)
+	if err != nil {
+		response.Message = err.Error()
+		logger.Info(response.Message)
+		return response
+	}
+
+	eformSignature := Signature{
+		SignatureHash: signature,
+		KeyID:         keyID,
+		OTPHash:       challenge.OTPHash,
+		OTPChallenge:  challenge.ChallengeID,
 		AkcessID:      invoker,
 		TimeStamp:     signdate,
+		TxTimestamp:   txTime,
 	}
-	eform.Signature = append(eform.Signature, signature)
+	eform.Signature = append(eform.Signature, eformSignature)
 	eformAsBytes, _ = json.Marshal(eform)
-	err = ctx.GetStub().PutState(eformid, eformAsBytes)
+	err = putObject(ctx, EformObject, eformid, eformAsBytes)
 	if err != nil {
 		response.Message = fmt.Sprintf("Error while signing eform: %s" + err.Error())
 		logger.Error(response.Message)
@@ -111,8 +183,9 @@ func (d *EformContract) SignEform(ctx contractapi.TransactionContextInterface, e
 	return response
 }
 
-// SendEform shares eform from sender to verifier
-func (d *EformContract) SendEform(ctx contractapi.TransactionContextInterface, sharingid string, receivers []string, eformid string) Response {
+// SendEform shares eform from sender to verifier. Receivers are passed in transient data as JSON array
+// under "receivers" key together with "salt" and stored in share private data collection
+func (d *EformContract) SendEform(ctx contractapi.TransactionContextInterface, sharingid string, eformid string) Response {
 	response := Response{
 		TxID:    ctx.GetStub().GetTxID(),
 		Success: false,
@@ -120,8 +193,13 @@ func (d *EformContract) SendEform(ctx contractapi.TransactionContextInterface, s
 		Data:    nil,
 	}
 
-	sender, _ := getCommonName(ctx)
-	eformAsBytes, err := ctx.GetStub().GetState(eformid)
+	sender, err := resolveAkcessID(ctx)
+	if err != nil {
+		response.Message = err.Error()
+		logger.Info(response.Message)
+		return response
+	}
+	eformAsBytes, err := getObject(ctx, EformObject, eformid)
 	if err != nil {
 		response.Message = fmt.Sprintf("Error while fetching eform from world state: %s" + err.Error())
 		logger.Error(response.Message)
@@ -132,7 +210,7 @@ func (d *EformContract) SendEform(ctx contractapi.TransactionContextInterface, s
 		logger.Info(response.Message)
 		return response
 	}
-	userAsBytes, err := ctx.GetStub().GetState(sender)
+	userAsBytes, err := getObject(ctx, UserObject, sender)
 	if err != nil {
 		response.Message = fmt.Sprintf("Error while fetching user from world state: %s" + err.Error())
 		logger.Error(response.Message)
@@ -144,15 +222,57 @@ func (d *EformContract) SendEform(ctx contractapi.TransactionContextInterface, s
 		return response
 	}
 
-	shareeform := EformShare{
-		ObjectType: "eformshare",
+	receiversAsJSON, err := getTransientValue(ctx, "receivers")
+	if err != nil {
+		response.Message = err.Error()
+		logger.Info(response.Message)
+		return response
+	}
+	var receivers []string
+	err = json.Unmarshal([]byte(receiversAsJSON), &receivers)
+	if err != nil {
+		response.Message = fmt.Sprintf("Receivers must be JSON array of AKcessIDs: %s", err.Error())
+		logger.Info(response.Message)
+		return response
+	}
+	salt, err := getTransientValue(ctx, "salt")
+	if err != nil {
+		response.Message = err.Error()
+		logger.Info(response.Message)
+		return response
+	}
+	collections, err := getPrivateDataConfig(ctx)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while fetching private data config: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+	shareReceivers := ShareReceivers{
+		ObjectType: "sharereceivers",
 		SharingID:  sharingid,
-		Sender:     sender,
 		Receivers:  receivers,
-		EformID:    eformid,
+		Salt:       salt,
+	}
+	err = putPrivateObject(ctx, collections.ShareCollection, "sharereceivers", []string{sharingid}, shareReceivers)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while saving receivers in private data collection: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+
+	receiverHashes := []string{}
+	for _, receiver := range receivers {
+		receiverHashes = append(receiverHashes, saltedHash(salt, receiver))
+	}
+	shareeform := EformShare{
+		ObjectType:     "eformshare",
+		SharingID:      sharingid,
+		Sender:         sender,
+		ReceiverHashes: receiverHashes,
+		EformID:        eformid,
 	}
 	shareEformAsBytes, _ := json.Marshal(shareeform)
-	err = ctx.GetStub().PutState(sharingid, shareEformAsBytes)
+	err = putObject(ctx, EformShareObject, sharingid, shareEformAsBytes)
 	if err != nil {
 		response.Message = fmt.Sprintf("Error while sharing eform: %s" + err.Error())
 		logger.Error(response.Message)
@@ -160,7 +280,7 @@ func (d *EformContract) SendEform(ctx contractapi.TransactionContextInterface, s
 	}
 
 	response.Success = true
-	response.Message = fmt.Sprintf("Eform %s shared from %s to %s", eformid, sender, receivers)
+	response.Message = fmt.Sprintf("Eform %s shared from %s to %d receivers", eformid, sender, len(receivers))
 	logger.Info(response.Message)
 	return response
 }
@@ -174,8 +294,13 @@ func (d *EformContract) VerifyEform(ctx contractapi.TransactionContextInterface,
 		Data:    nil,
 	}
 
-	invoker, _ := getCommonName(ctx)
-	eformAsBytes, err := ctx.GetStub().GetState(eformid)
+	invoker, err := resolveAkcessID(ctx)
+	if err != nil {
+		response.Message = err.Error()
+		logger.Info(response.Message)
+		return response
+	}
+	eformAsBytes, err := getObject(ctx, EformObject, eformid)
 	if err != nil {
 		response.Message = fmt.Sprintf("Error while fetching eform from world state: %s" + err.Error())
 		logger.Error(response.Message)
@@ -193,31 +318,55 @@ func (d *EformContract) VerifyEform(ctx contractapi.TransactionContextInterface,
 		logger.Error(response.Message)
 		return response
 	}
+	txTime, err := getTxTimestamp(ctx)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while getting tx timestamp: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+	policy, err := getTimePolicy(ctx)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while fetching time policy: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+	err = policy.CheckExpiryDate(expirydate, txTime)
+	if err != nil {
+		response.Message = err.Error()
+		logger.Info(response.Message)
+		return response
+	}
 
-	invokeArgs := util.ToChaincodeArgs("GetVerifier", invoker)
-	verifierAsBytes := ctx.GetStub().InvokeChaincode("akcess", invokeArgs, "akcessglobal")
-	if verifierAsBytes.Payload == nil {
+	verifier := getGlobalVerifier(ctx, invoker)
+	if verifier == nil {
 		response.Message = fmt.Sprintf("Verifier %s not registered on global channel", invoker)
 		logger.Info(response.Message)
 		return response
 	}
-	var verifier Verifier
-	json.Unmarshal(verifierAsBytes.Payload, &verifier)
+	if !verifier.IsApproved() {
+		response.Message = fmt.Sprintf("Verifier %s is not approved on global channel", invoker)
+		logger.Info(response.Message)
+		return response
+	}
 
 	var eform Eform
 	json.Unmarshal(eformAsBytes, &eform)
 
 	verification := Verification{
-		VerifierObj: verifier,
-		ExpirtyDate: expirydate,
+		VerifierID:      verifier.AkcessID,
+		VerifierVersion: verifier.Version,
+		ExpirtyDate:     expirydate,
+		TxTimestamp:     txTime,
 	}
 
 	verifierList := VerifiersList(eform.Verifications)
 	_, found := Find(verifierList, invoker)
 	if found {
 		for i, v := range eform.Verifications {
-			if v.VerifierObj.AkcessID == invoker {
+			if v.VerifierID == invoker {
+				eform.Verifications[i].VerifierVersion = verifier.Version
 				eform.Verifications[i].ExpirtyDate = expirydate
+				eform.Verifications[i].TxTimestamp = txTime
 				break
 			}
 		}
@@ -226,7 +375,7 @@ func (d *EformContract) VerifyEform(ctx contractapi.TransactionContextInterface,
 	}
 
 	eformAsBytes, _ = json.Marshal(eform)
-	err = ctx.GetStub().PutState(eformid, eformAsBytes)
+	err = putObject(ctx, EformObject, eformid, eformAsBytes)
 	if err != nil {
 		response.Message = fmt.Sprintf("Error while verifying eform: %s" + err.Error())
 		logger.Error(response.Message)
@@ -239,41 +388,9 @@ func (d *EformContract) VerifyEform(ctx contractapi.TransactionContextInterface,
 	return response
 }
 
-// GetTxForEform get eform details for perticular transaction
-// func (d *EformContract) GetTxForEform(ctx contractapi.TransactionContextInterface, eformid string, txid string) (*Eform, error) {
-// 	resultIterator, err := ctx.GetStub().GetHistoryForKey(eformid)
-// 	if err != nil {
-// 		return nil, err
-// 	}
-// 	defer resultIterator.Close()
-
-// 	for resultIterator.HasNext() {
-// 		queryResponse, err := resultIterator.Next()
-// 		if err != nil {
-// 			return nil, err
-// 		}
-// 		d := new(Eform)
-// 		_ = json.Unmarshal(queryResponse.Value, d)
-// 		fmt.Println(queryResponse.TxId)
-// 		fmt.Println(queryResponse.TxId == txid)
-// 		if queryResponse.TxId == txid {
-// 			queryResult := Eform{
-// 				ObjectType:        "eform",
-// 				EformID:        d.EformID,
-// 				EformHash:      d.EformHash,
-// 				SignatureHash:     d.SignatureHash,
-// 				AkcessID:          d.AkcessID,
-// 				VerifiedBy:        d.VerifiedBy,
-// 				VerificationGrade: d.VerificationGrade,
-// 			}
-// 			return &queryResult, nil
-// 		}
-// 	}
-// 	return nil, fmt.Errorf("there is not tx with %s for eform %s", txid, eformid)
-// }
-
-// GetVerifiersOfEform get verifiers of perticular eform
-func (d *EformContract) GetVerifiersOfEform(ctx contractapi.TransactionContextInterface, eformid string) Response {
+// GetVerifiersOfEform get verifiers of perticular eform with status of each verification,
+// pass activeOnly to skip expired verifications
+func (d *EformContract) GetVerifiersOfEform(ctx contractapi.TransactionContextInterface, eformid string, activeOnly bool) Response {
 	response := Response{
 		TxID:    ctx.GetStub().GetTxID(),
 		Success: false,
@@ -281,7 +398,7 @@ func (d *EformContract) GetVerifiersOfEform(ctx contractapi.TransactionContextIn
 		Data:    nil,
 	}
 
-	eformAsBytes, err := ctx.GetStub().GetState(eformid)
+	eformAsBytes, err := getObject(ctx, EformObject, eformid)
 	if err != nil {
 		response.Message = fmt.Sprintf("Error while fetching eform from world state: %s" + err.Error())
 		logger.Error(response.Message)
@@ -296,7 +413,19 @@ func (d *EformContract) GetVerifiersOfEform(ctx contractapi.TransactionContextIn
 	var eform Eform
 	json.Unmarshal(eformAsBytes, &eform)
 
-	response.Data = eform.Verifications
+	now, err := getTxTimestamp(ctx)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while getting tx timestamp: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+
+	verifiers := map[string]*Verifier{}
+	for _, verifierID := range VerifiersList(eform.Verifications) {
+		verifiers[verifierID] = getGlobalVerifier(ctx, verifierID)
+	}
+
+	response.Data = EvaluateVerifications(eform.Verifications, verifiers, now, activeOnly)
 	response.Success = true
 	response.Message = fmt.Sprintf("Successfully fetched verifications of eform %s", eformid)
 	logger.Info(response.Message)
@@ -347,3 +476,203 @@ func (d *EformContract) GetSignature(ctx contractapi.TransactionContextInterface
 	logger.Info(response.Message)
 	return response
 }
+
+// CheckSignatureOTP checks OTP and salt disclosed off-chain against OTP hash of signer's signatures on eform
+func (d *EformContract) CheckSignatureOTP(ctx contractapi.TransactionContextInterface, eformid string, signer string, otpCode string, salt string) Response {
+	response := Response{
+		TxID:    ctx.GetStub().GetTxID(),
+		Success: false,
+		Message: "",
+		Data:    nil,
+	}
+
+	eformAsBytes, err := getObject(ctx, EformObject, eformid)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while fetching eform from world state: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+	if eformAsBytes == nil {
+		response.Message = fmt.Sprintf("Eform with id %s doesn't exist", eformid)
+		logger.Info(response.Message)
+		return response
+	}
+
+	var eform Eform
+	json.Unmarshal(eformAsBytes, &eform)
+
+	otpHash := saltedHash(salt, otpCode)
+	matches := false
+	for _, signature := range eform.Signature {
+		if signature.AkcessID == signer && signature.OTPHash == otpHash {
+			matches = true
+			break
+		}
+	}
+
+	response.Data = matches
+	response.Success = true
+	response.Message = fmt.Sprintf("Checked OTP of %s's signature on eform %s", signer, eformid)
+	logger.Info(response.Message)
+	return response
+}
+
+// CheckShareReceiver checks receiver and salt disclosed off-chain against receiver hashes of eform share
+func (d *EformContract) CheckShareReceiver(ctx contractapi.TransactionContextInterface, sharingid string, receiver string, salt string) Response {
+	response := Response{
+		TxID:    ctx.GetStub().GetTxID(),
+		Success: false,
+		Message: "",
+		Data:    nil,
+	}
+
+	shareAsBytes, err := getObject(ctx, EformShareObject, sharingid)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while fetching share from world state: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+	if shareAsBytes == nil {
+		response.Message = fmt.Sprintf("Share with id %s doesn't exist", sharingid)
+		logger.Info(response.Message)
+		return response
+	}
+
+	var share EformShare
+	json.Unmarshal(shareAsBytes, &share)
+
+	_, matches := Find(share.ReceiverHashes, saltedHash(salt, receiver))
+
+	response.Data = matches
+	response.Success = true
+	response.Message = fmt.Sprintf("Checked receiver of share %s", sharingid)
+	logger.Info(response.Message)
+	return response
+}
+
+// GetLegacyShares returns IDs of eform shares made before receivers were moved to private data collection,
+// their receivers are still in public state until they are migrated with MigrateShareReceivers
+func (d *EformContract) GetLegacyShares(ctx contractapi.TransactionContextInterface) Response {
+	response := Response{
+		TxID:    ctx.GetStub().GetTxID(),
+		Success: false,
+		Message: "",
+		Data:    nil,
+	}
+
+	resultIterator, err := ctx.GetStub().GetQueryResult(`{"selector": {"docType": "eformshare", "receivers": {"$exists": true}}}`)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while fetching query result: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+	defer resultIterator.Close()
+
+	result := []string{}
+	for resultIterator.HasNext() {
+		queryResponse, err := resultIterator.Next()
+		if err != nil {
+			response.Message = fmt.Sprintf("Error while iterating shares: %s", err.Error())
+			logger.Error(response.Message)
+			return response
+		}
+		var share EformShare
+		json.Unmarshal(queryResponse.Value, &share)
+		result = append(result, share.SharingID)
+	}
+
+	response.Success = true
+	response.Message = fmt.Sprintf("Found %d legacy shares", len(result))
+	logger.Info(response.Message)
+	response.Data = result
+	return response
+}
+
+// MigrateShareReceivers admin moves plaintext receivers of legacy eform shares to private data collection and removes
+// them from public state. Salt is passed in transient data, each share is salted with hash of it and its sharing ID.
+// Shares which have no plaintext receivers are skipped
+func (d *EformContract) MigrateShareReceivers(ctx contractapi.TransactionContextInterface, sharingIDs []string) Response {
+	response := Response{
+		TxID:    ctx.GetStub().GetTxID(),
+		Success: false,
+		Message: "",
+		Data:    nil,
+	}
+
+	invoker := invokerName(ctx)
+	if !IsAdmin(ctx) {
+		response.Message = fmt.Sprintf("Identity %s is not an admin, only admin can migrate share receivers", invoker)
+		logger.Info(response.Message)
+		return response
+	}
+	salt, err := getTransientValue(ctx, "salt")
+	if err != nil {
+		response.Message = err.Error()
+		logger.Info(response.Message)
+		return response
+	}
+	collections, err := getPrivateDataConfig(ctx)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while fetching private data config: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+
+	result := MigrationResult{
+		Migrated: []string{},
+		Skipped:  []string{},
+	}
+	for _, sharingid := range sharingIDs {
+		shareAsBytes, err := getObject(ctx, EformShareObject, sharingid)
+		if err != nil {
+			response.Message = fmt.Sprintf("Error while fetching share %s: %s", sharingid, err.Error())
+			logger.Error(response.Message)
+			return response
+		}
+		var legacy struct {
+			Receivers []string `json:"receivers"`
+		}
+		if shareAsBytes != nil {
+			json.Unmarshal(shareAsBytes, &legacy)
+		}
+		if len(legacy.Receivers) == 0 {
+			result.Skipped = append(result.Skipped, sharingid)
+			continue
+		}
+
+		shareSalt := saltedHash(salt, sharingid)
+		shareReceivers := ShareReceivers{
+			ObjectType: "sharereceivers",
+			SharingID:  sharingid,
+			Receivers:  legacy.Receivers,
+			Salt:       shareSalt,
+		}
+		err = putPrivateObject(ctx, collections.ShareCollection, "sharereceivers", []string{sharingid}, shareReceivers)
+		if err != nil {
+			response.Message = fmt.Sprintf("Error while saving receivers in private data collection: %s", err.Error())
+			logger.Error(response.Message)
+			return response
+		}
+
+		var share EformShare
+		json.Unmarshal(shareAsBytes, &share)
+		share.ReceiverHashes = []string{}
+		for _, receiver := range legacy.Receivers {
+			share.ReceiverHashes = append(share.ReceiverHashes, saltedHash(shareSalt, receiver))
+		}
+		shareEformAsBytes, _ := json.Marshal(share)
+		err = putObject(ctx, EformShareObject, sharingid, shareEformAsBytes)
+		if err != nil {
+			response.Message = fmt.Sprintf("Error while migrating receivers of share %s: %s", sharingid, err.Error())
+			logger.Error(response.Message)
+			return response
+		}
+		result.Migrated = append(result.Migrated, sharingid)
+	}
+
+	response.Success = true
+	response.Message = fmt.Sprintf("Migrated receivers of %d legacy shares", len(result.Migrated))
+	logger.Info(response.Message)
+	response.Data = result
+	return response
+}
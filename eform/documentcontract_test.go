@@ -0,0 +1,114 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestVerifyEform(t *testing.T) {
+	expiry := testTime.AddDate(1, 0, 0).Format(time.RFC3339)
+	tests := []struct {
+		name          string
+		verifier      *Verifier
+		expiryDate    string
+		expectedError string
+	}{
+		{name: "unregistered verifier", expiryDate: expiry, expectedError: "Verifier bob not registered on global channel"},
+		{name: "unapproved verifier", verifier: &Verifier{AkcessID: "bob", Status: "pending"}, expiryDate: expiry, expectedError: "Verifier bob is not approved on global channel"},
+		{name: "expiry date in the past", verifier: &Verifier{AkcessID: "bob", Status: "approved"}, expiryDate: testTime.Format(time.RFC3339), expectedError: "is not in the future"},
+		{name: "approved verifier", verifier: &Verifier{AkcessID: "bob", Status: "approved", Version: 3}, expiryDate: expiry},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := newEformLedger(t)
+			if tt.verifier != nil {
+				l.stub.akcess.verifiers["bob"] = *tt.verifier
+			}
+			response := new(EformContract).VerifyEform(l.as("Org1MSP", "bob", nil), "form1", tt.expiryDate)
+			expectResponse(t, response, tt.expectedError)
+			if tt.expectedError != "" {
+				return
+			}
+			value, _ := getObject(l.as("Org1MSP", "reader", nil), EformObject, "form1")
+			var eform Eform
+			json.Unmarshal(value, &eform)
+			if len(eform.Verifications) != 1 || eform.Verifications[0].VerifierID != "bob" || eform.Verifications[0].VerifierVersion != 3 {
+				t.Fatalf("expected verification by bob at verifier version 3, got %+v", eform.Verifications)
+			}
+		})
+	}
+}
+
+func TestMigrateShareReceivers(t *testing.T) {
+	l := newEformLedger(t)
+	l.put(l.compositeKey(UserObject, "alice"), map[string]string{"docType": "user", "akcessId": "alice"})
+	l.put("share1", map[string]interface{}{
+		"docType":   "eformshare",
+		"SharingID": "share1",
+		"sender":    "alice",
+		"eformId":   "form1",
+		"receivers": []string{"bob"},
+	})
+	ctx := l.as("Org1MSP", "alice", nil)
+	l.stub.SetTransient(map[string][]byte{"receivers": []byte(`["bob"]`), "salt": []byte("salt")})
+	expectResponse(t, new(EformContract).SendEform(ctx, "share2", "form1"), "")
+	l.stub.TransientMap = nil
+
+	legacy := new(EformContract).GetLegacyShares(l.as("Org1MSP", "alice", nil))
+	expectResponse(t, legacy, "")
+	if shares := legacy.Data.([]string); !equalStrings(shares, []string{"share1"}) {
+		t.Fatalf("expected legacy share share1, got %v", shares)
+	}
+
+	tests := []struct {
+		name             string
+		mspID            string
+		salt             string
+		expectedMigrated []string
+		expectedSkipped  []string
+		expectedError    string
+	}{
+		{name: "non admin can't migrate", mspID: "Org1MSP", salt: "salt", expectedError: "only admin can migrate share receivers"},
+		{name: "salt is required", mspID: "AdminMSP", expectedError: "salt must be passed in transient data"},
+		{name: "admin migrates legacy shares", mspID: "AdminMSP", salt: "salt", expectedMigrated: []string{"share1"}, expectedSkipped: []string{"share2", "share3"}},
+		{name: "migrated share is skipped", mspID: "AdminMSP", salt: "salt", expectedMigrated: []string{}, expectedSkipped: []string{"share1", "share2", "share3"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := l.as(tt.mspID, "admin", nil)
+			if tt.salt != "" {
+				l.stub.SetTransient(map[string][]byte{"salt": []byte(tt.salt)})
+			}
+			response := new(EformContract).MigrateShareReceivers(ctx, []string{"share1", "share2", "share3"})
+			l.stub.TransientMap = nil
+			expectResponse(t, response, tt.expectedError)
+			if tt.expectedError != "" {
+				return
+			}
+			result := response.Data.(MigrationResult)
+			if !equalStrings(result.Migrated, tt.expectedMigrated) || !equalStrings(result.Skipped, tt.expectedSkipped) {
+				t.Fatalf("expected migrated %v and skipped %v, got %+v", tt.expectedMigrated, tt.expectedSkipped, result)
+			}
+		})
+	}
+
+	if l.stub.State["share1"] != nil {
+		t.Fatalf("expected legacy key of share1 to be deleted")
+	}
+	legacy = new(EformContract).GetLegacyShares(l.as("Org1MSP", "alice", nil))
+	if shares := legacy.Data.([]string); len(shares) != 0 {
+		t.Fatalf("expected no legacy shares left, got %v", shares)
+	}
+	shareSalt := saltedHash("salt", "share1")
+	check := new(EformContract).CheckShareReceiver(l.as("Org1MSP", "reader", nil), "share1", "bob", shareSalt)
+	if matches, _ := check.Data.(bool); !matches {
+		t.Fatalf("expected bob to be receiver of migrated share1, got %+v", check)
+	}
+	check = new(EformContract).CheckShareReceiver(l.as("Org1MSP", "reader", nil), "share1", "mallory", shareSalt)
+	if matches, _ := check.Data.(bool); matches {
+		t.Fatalf("expected mallory not to be receiver of migrated share1")
+	}
+}
@@ -0,0 +1,206 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// EformMatch eform whose content hash matches looked up hash
+type EformMatch struct {
+	EformID      string            `json:"eformId"`
+	AkcessID     string            `json:"akcessId"`  // owner of the eform
+	HashIndex    int               `json:"hashIndex"` // position of matching hash in eformHash, numbered from 1
+	IsLatest     bool              `json:"isLatest"`  // signatures are made over the last hash only
+	Signatures   []Signature       `json:"signatures"`
+	Verification VerificationsView `json:"verification"`
+}
+
+// IndexResult result of one batch of eform hash indexing
+type IndexResult struct {
+	Indexed []string `json:"indexed"`
+	NextKey string   `json:"nextKey"` // pass as startKey of next batch, empty when indexing is done
+}
+
+// FindEformByHash returns eforms with given content hash,
+// used by relying parties holding only the file to check its authenticity
+func (d *EformContract) FindEformByHash(ctx contractapi.TransactionContextInterface, hash string) Response {
+	response := Response{
+		TxID:    ctx.GetStub().GetTxID(),
+		Success: false,
+		Message: "",
+		Data:    nil,
+	}
+
+	hash = strings.ToLower(hash)
+	if hash == "" {
+		response.Message = fmt.Sprint("Hash can't be empty")
+		logger.Info(response.Message)
+		return response
+	}
+	now, err := getTxTimestamp(ctx)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while getting tx timestamp: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+
+	resultIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(EformHashIndex, []string{hash})
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while fetching eform hash index: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+	defer resultIterator.Close()
+
+	result := []EformMatch{}
+	for resultIterator.HasNext() {
+		queryResponse, err := resultIterator.Next()
+		if err != nil {
+			response.Message = fmt.Sprintf("Error while iterating eform hash index: %s", err.Error())
+			logger.Error(response.Message)
+			return response
+		}
+		_, attributes, err := ctx.GetStub().SplitCompositeKey(queryResponse.Key)
+		if err != nil || len(attributes) != 3 {
+			continue
+		}
+		eformid := attributes[1]
+		position, err := strconv.Atoi(attributes[2])
+		if err != nil {
+			continue
+		}
+
+		eformAsBytes, err := getObject(ctx, EformObject, eformid)
+		if err != nil {
+			response.Message = fmt.Sprintf("Error while fetching eform from world state: %s", err.Error())
+			logger.Error(response.Message)
+			return response
+		}
+		// skip entries of eforms which no longer exist
+		if eformAsBytes == nil {
+			continue
+		}
+		var eform Eform
+		json.Unmarshal(eformAsBytes, &eform)
+		if position < 1 || position > len(eform.EformHash) {
+			continue
+		}
+
+		verifiers := map[string]*Verifier{}
+		for _, verifierID := range VerifiersList(eform.Verifications) {
+			verifiers[verifierID] = getGlobalVerifier(ctx, verifierID)
+		}
+		result = append(result, EformMatch{
+			EformID:      eform.EformID,
+			AkcessID:     eform.AkcessID,
+			HashIndex:    position,
+			IsLatest:     position == len(eform.EformHash),
+			Signatures:   eform.Signature,
+			Verification: EvaluateVerifications(eform.Verifications, verifiers, now, false),
+		})
+	}
+	if len(result) == 0 {
+		response.Message = fmt.Sprintf("No eform has hash %s", hash)
+		logger.Info(response.Message)
+		return response
+	}
+
+	response.Success = true
+	response.Message = fmt.Sprintf("Found %d eforms with hash %s", len(result), hash)
+	logger.Info(response.Message)
+	response.Data = result
+	return response
+}
+
+// IndexEformHashes admin indexes hashes of eforms created before hash index was introduced.
+// Indexing runs in batches of at most limit eforms starting from eform with id startKey,
+// until returned nextKey is empty. Eforms still under legacy keys are indexed by MigrateLegacyKeys
+func (d *EformContract) IndexEformHashes(ctx contractapi.TransactionContextInterface, startKey string, limit int) Response {
+	response := Response{
+		TxID:    ctx.GetStub().GetTxID(),
+		Success: false,
+		Message: "",
+		Data:    nil,
+	}
+
+	invoker := invokerName(ctx)
+	if !IsAdmin(ctx) {
+		response.Message = fmt.Sprintf("Identity %s is not an admin, only admin can index eforms", invoker)
+		logger.Info(response.Message)
+		return response
+	}
+	if limit <= 0 {
+		response.Message = fmt.Sprint("Limit must be greater than zero")
+		logger.Info(response.Message)
+		return response
+	}
+
+	resultIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(EformObject, []string{})
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while fetching eforms: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+	defer resultIterator.Close()
+
+	result := IndexResult{
+		Indexed: []string{},
+	}
+	for resultIterator.HasNext() {
+		queryResponse, err := resultIterator.Next()
+		if err != nil {
+			response.Message = fmt.Sprintf("Error while iterating eforms: %s", err.Error())
+			logger.Error(response.Message)
+			return response
+		}
+		_, attributes, err := ctx.GetStub().SplitCompositeKey(queryResponse.Key)
+		if err != nil || len(attributes) != 1 || attributes[0] < startKey {
+			continue
+		}
+		if len(result.Indexed) == limit {
+			result.NextKey = attributes[0]
+			break
+		}
+
+		err = indexEformHashes(ctx, queryResponse.Value)
+		if err != nil {
+			response.Message = fmt.Sprintf("Error while indexing eform %s: %s", attributes[0], err.Error())
+			logger.Error(response.Message)
+			return response
+		}
+		result.Indexed = append(result.Indexed, attributes[0])
+	}
+
+	response.Success = true
+	response.Message = fmt.Sprintf("Indexed hashes of %d eforms", len(result.Indexed))
+	logger.Info(response.Message)
+	response.Data = result
+	return response
+}
+
+// indexEformHashes adds index entries of all hashes of stored eform
+func indexEformHashes(ctx contractapi.TransactionContextInterface, value []byte) error {
+	var eform Eform
+	err := json.Unmarshal(value, &eform)
+	if err != nil {
+		return err
+	}
+	for i, hash := range eform.EformHash {
+		if hash == "" {
+			continue
+		}
+		indexKey, err := ctx.GetStub().CreateCompositeKey(EformHashIndex, []string{strings.ToLower(hash), eform.EformID, strconv.Itoa(i + 1)})
+		if err != nil {
+			return err
+		}
+		err = ctx.GetStub().PutState(indexKey, []byte{0x00})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,241 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+
+	"otp"
+)
+
+// ErasedAkcessID replaces AKcessID of erased user in records which can't be deleted, e.g. signatures
+const ErasedAkcessID = "erased"
+
+// ErasureResult summary of eform data erased for a user
+type ErasureResult struct {
+	AkcessID             string `json:"akcessId"`
+	SignatureOTPs        int    `json:"signatureOTPs"`        // OTPs deleted from private data collection
+	OTPChallenges        int    `json:"otpChallenges"`        // OTP challenges deleted from private data collection
+	SignaturesAnonymized int    `json:"signaturesAnonymized"` // signatures whose signer was replaced by ErasedAkcessID
+	SharesDeleted        int    `json:"sharesDeleted"`        // shares sent by the user
+	SharesUpdated        int    `json:"sharesUpdated"`        // shares the user was removed from as receiver
+}
+
+// EraseUserData erases eform data of user erased from AKcess. Only the user or an admin can erase it.
+// OTPs are deleted from private data collection, shares sent by the user are deleted,
+// the user is removed from receivers of other shares and its signatures are anonymized
+func (d *EformContract) EraseUserData(ctx contractapi.TransactionContextInterface, akcessid string) Response {
+	response := Response{
+		TxID:    ctx.GetStub().GetTxID(),
+		Success: false,
+		Message: "",
+		Data:    nil,
+	}
+
+	invoker := invokerName(ctx)
+	if invoker != akcessid && !IsAdmin(ctx) {
+		response.Message = fmt.Sprintf("Identity %s can't erase data of user %s, only the user or an admin can", invoker, akcessid)
+		logger.Info(response.Message)
+		return response
+	}
+
+	result, err := eraseUserData(ctx, akcessid)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while erasing data of user %s: %s", akcessid, err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+
+	response.Success = true
+	response.Message = fmt.Sprintf("Eform data of user %s erased", akcessid)
+	logger.Info(response.Message)
+	response.Data = result
+	return response
+}
+
+// eraseUserData deletes or anonymizes OTPs, shares and signatures of user
+func eraseUserData(ctx contractapi.TransactionContextInterface, akcessID string) (ErasureResult, error) {
+	result := ErasureResult{AkcessID: akcessID}
+	collections, err := getPrivateDataConfig(ctx)
+	if err != nil {
+		return result, err
+	}
+	result.OTPChallenges, err = deletePrivateObjects(ctx, collections.OTPCollection, otp.ObjectType, []string{akcessID})
+	if err != nil {
+		return result, err
+	}
+
+	signedEforms, err := queryEforms(ctx, fmt.Sprintf(`{
+		"selector": {
+		   "docType": "eform",
+		   "signature": {
+			  "$elemMatch": {
+				 "akcessId": "%s"
+			  }
+		   }
+		}
+	 }`, akcessID))
+	if err != nil {
+		return result, err
+	}
+	for _, eform := range signedEforms {
+		deleted, err := deletePrivateObjects(ctx, collections.OTPCollection, "signatureotp", []string{eform.EformID, akcessID})
+		if err != nil {
+			return result, err
+		}
+		result.SignatureOTPs += deleted
+
+		for i := range eform.Signature {
+			if eform.Signature[i].AkcessID == akcessID {
+				eform.Signature[i].AkcessID = ErasedAkcessID
+				eform.Signature[i].OTPHash = ""
+				eform.Signature[i].OTPChallenge = ""
+				result.SignaturesAnonymized++
+			}
+		}
+		eformAsBytes, _ := json.Marshal(eform)
+		err = putObject(ctx, EformObject, eform.EformID, eformAsBytes)
+		if err != nil {
+			return result, err
+		}
+	}
+
+	sentShares, err := ctx.GetStub().GetQueryResult(fmt.Sprintf(`{
+		"selector": {
+		   "docType": "eformshare",
+		   "sender": "%s"
+		}
+	}`, akcessID))
+	if err != nil {
+		return result, err
+	}
+	defer sentShares.Close()
+	for sentShares.HasNext() {
+		queryResponse, err := sentShares.Next()
+		if err != nil {
+			return result, err
+		}
+		var share EformShare
+		json.Unmarshal(queryResponse.Value, &share)
+
+		receiversKey, _ := ctx.GetStub().CreateCompositeKey("sharereceivers", []string{share.SharingID})
+		err = ctx.GetStub().DelPrivateData(collections.ShareCollection, receiversKey)
+		if err != nil {
+			return result, err
+		}
+		err = deleteObject(ctx, EformShareObject, share.SharingID)
+		if err != nil {
+			return result, err
+		}
+		result.SharesDeleted++
+	}
+
+	receivedShares, err := ctx.GetStub().GetPrivateDataQueryResult(collections.ShareCollection, fmt.Sprintf(`{
+		"selector": {
+		   "docType": "sharereceivers",
+		   "receivers": {
+			  "$elemMatch": {
+				 "$eq": "%s"
+			  }
+		   }
+		}
+	}`, akcessID))
+	if err != nil {
+		return result, err
+	}
+	defer receivedShares.Close()
+	for receivedShares.HasNext() {
+		queryResponse, err := receivedShares.Next()
+		if err != nil {
+			return result, err
+		}
+		var receivers ShareReceivers
+		json.Unmarshal(queryResponse.Value, &receivers)
+		err = removeShareReceiver(ctx, collections.ShareCollection, queryResponse.Key, receivers, akcessID)
+		if err != nil {
+			return result, err
+		}
+		result.SharesUpdated++
+	}
+
+	return result, nil
+}
+
+// removeShareReceiver removes receiver from private receivers of share and its hash from public share
+func removeShareReceiver(ctx contractapi.TransactionContextInterface, collection string, receiversKey string, receivers ShareReceivers, receiver string) error {
+	remaining := []string{}
+	for _, r := range receivers.Receivers {
+		if r != receiver {
+			remaining = append(remaining, r)
+		}
+	}
+	receivers.Receivers = remaining
+	receiversAsBytes, _ := json.Marshal(receivers)
+	err := ctx.GetStub().PutPrivateData(collection, receiversKey, receiversAsBytes)
+	if err != nil {
+		return err
+	}
+
+	shareAsBytes, err := getObject(ctx, EformShareObject, receivers.SharingID)
+	if err != nil || shareAsBytes == nil {
+		return err
+	}
+	var share EformShare
+	json.Unmarshal(shareAsBytes, &share)
+	receiverHash := saltedHash(receivers.Salt, receiver)
+	hashes := []string{}
+	for _, h := range share.ReceiverHashes {
+		if h != receiverHash {
+			hashes = append(hashes, h)
+		}
+	}
+	share.ReceiverHashes = hashes
+	shareAsBytes, _ = json.Marshal(share)
+	return putObject(ctx, EformShareObject, share.SharingID, shareAsBytes)
+}
+
+// deletePrivateObjects deletes all objects of given type whose composite key starts with attributes
+// from private data collection and returns number of deleted objects
+func deletePrivateObjects(ctx contractapi.TransactionContextInterface, collection string, objectType string, attributes []string) (int, error) {
+	resultIterator, err := ctx.GetStub().GetPrivateDataByPartialCompositeKey(collection, objectType, attributes)
+	if err != nil {
+		return 0, err
+	}
+	defer resultIterator.Close()
+
+	deleted := 0
+	for resultIterator.HasNext() {
+		queryResponse, err := resultIterator.Next()
+		if err != nil {
+			return deleted, err
+		}
+		err = ctx.GetStub().DelPrivateData(collection, queryResponse.Key)
+		if err != nil {
+			return deleted, err
+		}
+		deleted++
+	}
+	return deleted, nil
+}
+
+// queryEforms returns eforms matching rich query
+func queryEforms(ctx contractapi.TransactionContextInterface, queryString string) ([]Eform, error) {
+	resultIterator, err := ctx.GetStub().GetQueryResult(queryString)
+	if err != nil {
+		return nil, err
+	}
+	defer resultIterator.Close()
+
+	result := []Eform{}
+	for resultIterator.HasNext() {
+		queryResponse, err := resultIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+		eform := new(Eform)
+		_ = json.Unmarshal(queryResponse.Value, eform)
+		result = append(result, *eform)
+	}
+	return result, nil
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// Kinds of change between two states of an object
+const (
+	ChangeCreated = "created"
+	ChangeDeleted = "deleted"
+	ChangeAdded   = "added"
+	ChangeUpdated = "updated"
+	ChangeRemoved = "removed"
+)
+
+// HistoryChange one change made by a transaction
+type HistoryChange struct {
+	Field  string `json:"field"`
+	Change string `json:"change"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// EformHistoryEntry state of eform written by a transaction together with what it changed
+type EformHistoryEntry struct {
+	TxID      string          `json:"txId"`
+	Timestamp time.Time       `json:"timestamp"`
+	IsDelete  bool            `json:"isDelete"`
+	Eform     *Eform          `json:"eform,omitempty"` // nil when the transaction deleted the eform
+	Changes   []HistoryChange `json:"changes"`
+}
+
+// GetTxForEform returns every past state of eform with changes made by each transaction, oldest first.
+// Pass txid to get only the state written by that transaction
+func (d *EformContract) GetTxForEform(ctx contractapi.TransactionContextInterface, eformid string, txid string) Response {
+	response := Response{
+		TxID:    ctx.GetStub().GetTxID(),
+		Success: false,
+		Message: "",
+		Data:    nil,
+	}
+
+	states, err := getObjectHistory(ctx, EformObject, eformid)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while fetching history of eform: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+	if len(states) == 0 {
+		response.Message = fmt.Sprintf("Eform with id %s has no history", eformid)
+		logger.Info(response.Message)
+		return response
+	}
+
+	history := []EformHistoryEntry{}
+	var previous *Eform
+	for _, state := range states {
+		entry := EformHistoryEntry{
+			TxID:      state.TxID,
+			Timestamp: state.Timestamp,
+			IsDelete:  state.IsDelete,
+		}
+		if !state.IsDelete {
+			entry.Eform = new(Eform)
+			err = json.Unmarshal(state.Value, entry.Eform)
+			if err != nil {
+				response.Message = fmt.Sprintf("Error while reading state of eform written by tx %s: %s", state.TxID, err.Error())
+				logger.Error(response.Message)
+				return response
+			}
+		}
+		entry.Changes = diffEforms(previous, entry.Eform)
+		previous = entry.Eform
+
+		if txid == "" {
+			history = append(history, entry)
+			continue
+		}
+		if entry.TxID == txid {
+			response.Success = true
+			response.Message = fmt.Sprintf("Successfully fetched state of eform %s written by tx %s", eformid, txid)
+			logger.Info(response.Message)
+			response.Data = entry
+			return response
+		}
+	}
+	if txid != "" {
+		response.Message = fmt.Sprintf("There is no tx %s for eform %s", txid, eformid)
+		logger.Info(response.Message)
+		return response
+	}
+
+	response.Success = true
+	response.Message = fmt.Sprintf("Successfully fetched %d states of eform %s", len(history), eformid)
+	logger.Info(response.Message)
+	response.Data = history
+	return response
+}
+
+// diffEforms lists changes between two states of eform, nil state means eform doesn't exist.
+// Hashes and signatures are only appended, so they are compared by position
+func diffEforms(previous *Eform, current *Eform) []HistoryChange {
+	changes := []HistoryChange{}
+	if current == nil {
+		if previous != nil {
+			changes = append(changes, HistoryChange{Field: "eform", Change: ChangeDeleted})
+		}
+		return changes
+	}
+	if previous == nil {
+		changes = append(changes, HistoryChange{Field: "eform", Change: ChangeCreated})
+		previous = &Eform{}
+	}
+
+	if previous.AkcessID != "" && previous.AkcessID != current.AkcessID {
+		changes = append(changes, HistoryChange{Field: "akcessId", Change: ChangeUpdated, Detail: fmt.Sprintf("%s -> %s", previous.AkcessID, current.AkcessID)})
+	}
+	for i := len(previous.EformHash); i < len(current.EformHash); i++ {
+		changes = append(changes, HistoryChange{Field: "eformHash", Change: ChangeAdded, Detail: current.EformHash[i]})
+	}
+
+	for i, s := range current.Signature {
+		if i >= len(previous.Signature) {
+			changes = append(changes, HistoryChange{Field: "signature", Change: ChangeAdded, Detail: "by " + s.AkcessID})
+		} else if !reflect.DeepEqual(previous.Signature[i], s) {
+			changes = append(changes, HistoryChange{Field: "signature", Change: ChangeUpdated, Detail: "by " + s.AkcessID})
+		}
+	}
+	for i := len(current.Signature); i < len(previous.Signature); i++ {
+		changes = append(changes, HistoryChange{Field: "signature", Change: ChangeRemoved, Detail: "by " + previous.Signature[i].AkcessID})
+	}
+
+	previousVerifications := map[string]Verification{}
+	for _, v := range previous.Verifications {
+		previousVerifications[v.VerifierID] = v
+	}
+	currentVerifications := map[string]bool{}
+	for _, v := range current.Verifications {
+		currentVerifications[v.VerifierID] = true
+		old, found := previousVerifications[v.VerifierID]
+		if !found {
+			changes = append(changes, HistoryChange{Field: "verifications", Change: ChangeAdded, Detail: "by " + v.VerifierID})
+		} else if !reflect.DeepEqual(old, v) {
+			changes = append(changes, HistoryChange{Field: "verifications", Change: ChangeUpdated, Detail: "by " + v.VerifierID})
+		}
+	}
+	for _, v := range previous.Verifications {
+		if !currentVerifications[v.VerifierID] {
+			changes = append(changes, HistoryChange{Field: "verifications", Change: ChangeRemoved, Detail: "by " + v.VerifierID})
+		}
+	}
+	return changes
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestGetObjectLegacyFallback(t *testing.T) {
+	tests := []struct {
+		name          string
+		setup         func(l *testLedger)
+		objectType    string
+		expectedOwner string
+	}{
+		{
+			name:       "object doesn't exist",
+			setup:      func(l *testLedger) {},
+			objectType: EformObject,
+		},
+		{
+			name: "object under composite key",
+			setup: func(l *testLedger) {
+				l.put(l.compositeKey(EformObject, "form1"), Eform{ObjectType: "eform", EformID: "form1", AkcessID: "alice"})
+			},
+			objectType:    EformObject,
+			expectedOwner: "alice",
+		},
+		{
+			name: "object under legacy key",
+			setup: func(l *testLedger) {
+				l.put("form1", Eform{ObjectType: "eform", EformID: "form1", AkcessID: "alice"})
+			},
+			objectType:    EformObject,
+			expectedOwner: "alice",
+		},
+		{
+			name: "composite key wins over legacy key",
+			setup: func(l *testLedger) {
+				l.put("form1", Eform{ObjectType: "eform", EformID: "form1", AkcessID: "alice"})
+				l.put(l.compositeKey(EformObject, "form1"), Eform{ObjectType: "eform", EformID: "form1", AkcessID: "bob"})
+			},
+			objectType:    EformObject,
+			expectedOwner: "bob",
+		},
+		{
+			name: "legacy key holding object of other type",
+			setup: func(l *testLedger) {
+				l.put("form1", EformShare{ObjectType: "eformshare", Sender: "alice", EformID: "form2"})
+			},
+			objectType: EformObject,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := newTestLedger(t)
+			tt.setup(l)
+			value, err := getObject(l.as("Org1MSP", "reader", nil), tt.objectType, "form1")
+			expectErr(t, err, "")
+			if tt.expectedOwner == "" {
+				if value != nil {
+					t.Fatalf("expected no eform, got %s", value)
+				}
+				return
+			}
+			if owner := eformOwner(t, value); owner != tt.expectedOwner {
+				t.Fatalf("expected eform of %s, got eform of %s", tt.expectedOwner, owner)
+			}
+		})
+	}
+}
+
+// eformOwner owner of eform stored as value
+func eformOwner(t *testing.T, value []byte) string {
+	t.Helper()
+	var eform Eform
+	if err := json.Unmarshal(value, &eform); err != nil {
+		t.Fatalf("unmarshalling eform: %s", err.Error())
+	}
+	return eform.AkcessID
+}
+
+func TestPutObjectRemovesLegacyKey(t *testing.T) {
+	l := newTestLedger(t)
+	l.put("form1", Eform{ObjectType: "eform", EformID: "form1", AkcessID: "alice"})
+	l.put("share1", EformShare{ObjectType: "eformshare", Sender: "alice", EformID: "form1"})
+
+	ctx := l.as("Org1MSP", "alice", nil)
+	expectErr(t, putObject(ctx, EformObject, "form1", []byte(`{"docType":"eform","eformId":"form1","akcessId":"alice"}`)), "")
+	expectErr(t, putObject(ctx, EformObject, "share1", []byte(`{"docType":"eform","eformId":"share1","akcessId":"alice"}`)), "")
+
+	if l.stub.State["form1"] != nil {
+		t.Fatalf("expected legacy key form1 to be deleted")
+	}
+	if l.stub.State["share1"] == nil {
+		t.Fatalf("expected legacy key share1 holding share to be kept")
+	}
+}
+
+func TestMigrateLegacyKeys(t *testing.T) {
+	l := newTestLedger(t)
+	l.put("form1", Eform{ObjectType: "eform", EformID: "form1", EformHash: []string{"hash1"}, AkcessID: "alice"})
+	l.put("form2", Eform{ObjectType: "eform", EformID: "form2", AkcessID: "old"})
+	l.put(l.compositeKey(EformObject, "form2"), Eform{ObjectType: "eform", EformID: "form2", AkcessID: "new"})
+	l.put("junk", map[string]string{"docType": "unknown"})
+	l.put("share1", EformShare{ObjectType: "eformshare", Sender: "alice", EformID: "form1"})
+
+	tests := []struct {
+		name             string
+		mspID            string
+		startKey         string
+		limit            int
+		expectedMigrated []string
+		expectedSkipped  []string
+		expectedNextKey  string
+		expectedError    string
+	}{
+		{name: "non admin can't migrate", mspID: "Org1MSP", limit: 10, expectedError: "only admin can migrate keys"},
+		{name: "limit must be positive", mspID: "AdminMSP", limit: 0, expectedError: "Limit must be greater than zero"},
+		{name: "first batch", mspID: "AdminMSP", limit: 2, expectedMigrated: []string{"form1", "form2"}, expectedSkipped: []string{}, expectedNextKey: "junk"},
+		{name: "last batch skips unknown object", mspID: "AdminMSP", startKey: "junk", limit: 2, expectedMigrated: []string{"share1"}, expectedSkipped: []string{"junk"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := new(EformContract).MigrateLegacyKeys(l.as(tt.mspID, "admin", nil), tt.startKey, tt.limit)
+			expectResponse(t, response, tt.expectedError)
+			if tt.expectedError != "" {
+				return
+			}
+			result := response.Data.(MigrationResult)
+			if !equalStrings(result.Migrated, tt.expectedMigrated) || !equalStrings(result.Skipped, tt.expectedSkipped) || result.NextKey != tt.expectedNextKey {
+				t.Fatalf("expected migrated %v, skipped %v and next key %q, got %+v", tt.expectedMigrated, tt.expectedSkipped, tt.expectedNextKey, result)
+			}
+		})
+	}
+
+	for _, key := range []string{"form1", "form2", "share1"} {
+		if l.stub.State[key] != nil {
+			t.Fatalf("expected legacy key %s to be deleted", key)
+		}
+	}
+	ctx := l.as("Org1MSP", "reader", nil)
+	value, _ := getObject(ctx, EformObject, "form2")
+	if owner := eformOwner(t, value); owner != "new" {
+		t.Fatalf("expected eform form2 already under composite key to be kept, got eform of %s", owner)
+	}
+	if l.stub.State[l.compositeKey(EformHashIndex, "hash1", "form1", "1")] == nil {
+		t.Fatalf("expected hash of migrated eform form1 to be indexed")
+	}
+}
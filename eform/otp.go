@@ -0,0 +1,83 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+
+	"otp"
+)
+
+// IssueOTPChallenge OTP issuer records challenge signer has to answer when signing eform.
+// OTP and its salt are passed in transient data under "otp" and "salt" keys, OTP itself is never stored.
+// Challenge expires after expiresIn seconds and can be used for one signature only, see package otp
+func (d *EformContract) IssueOTPChallenge(ctx contractapi.TransactionContextInterface, eformid string, signer string, expiresIn int) Response {
+	response := Response{
+		TxID:    ctx.GetStub().GetTxID(),
+		Success: false,
+		Message: "",
+		Data:    nil,
+	}
+
+	invoker := invokerName(ctx)
+	if !IsOTPIssuer(ctx) {
+		response.Message = fmt.Sprintf("Identity %s is not an OTP issuer, only OTP issuer can issue OTP challenges", invoker)
+		logger.Info(response.Message)
+		return response
+	}
+	eformAsBytes, err := getObject(ctx, EformObject, eformid)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while fetching eform from world state: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+	if eformAsBytes == nil {
+		response.Message = fmt.Sprintf("Eform with id %s doesn't exist", eformid)
+		logger.Info(response.Message)
+		return response
+	}
+	lifetime, err := otp.Lifetime(expiresIn)
+	if err != nil {
+		response.Message = err.Error()
+		logger.Info(response.Message)
+		return response
+	}
+
+	otpCode, err := getTransientValue(ctx, "otp")
+	if err != nil {
+		response.Message = err.Error()
+		logger.Info(response.Message)
+		return response
+	}
+	salt, err := getTransientValue(ctx, "salt")
+	if err != nil {
+		response.Message = err.Error()
+		logger.Info(response.Message)
+		return response
+	}
+	collections, err := getPrivateDataConfig(ctx)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while fetching private data config: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+	txTime, err := getTxTimestamp(ctx)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while getting tx timestamp: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+
+	issued, err := otp.Issue(ctx, collections.OTPCollection, eformid, signer, otpCode, salt, invoker, txTime, lifetime)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while saving OTP challenge in private data collection: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+
+	response.Success = true
+	response.Message = fmt.Sprintf("OTP challenge issued to %s for eform %s", signer, eformid)
+	logger.Info(response.Message)
+	response.Data = *issued
+	return response
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"otp"
+)
+
+// issueOTP issues OTP challenge for form1 with OTP and salt passed in transient data
+func issueOTP(l *testLedger, mspID string, attrs map[string]string, signer string, otpCode string, expiresIn int) Response {
+	l.t.Helper()
+	ctx := l.as(mspID, "issuer", attrs)
+	err := l.stub.SetTransient(map[string][]byte{"otp": []byte(otpCode), "salt": []byte("salt-" + otpCode)})
+	if err != nil {
+		l.t.Fatalf("setting transient data: %s", err.Error())
+	}
+	response := new(EformContract).IssueOTPChallenge(ctx, "form1", signer, expiresIn)
+	l.stub.TransientMap = nil
+	return response
+}
+
+// signEform signs form1 with OTP passed in transient data
+func signEform(l *testLedger, enrollmentID string, signature string, otpCode string) Response {
+	l.t.Helper()
+	ctx := l.as("Org1MSP", enrollmentID, nil)
+	err := l.stub.SetTransient(map[string][]byte{"otp": []byte(otpCode)})
+	if err != nil {
+		l.t.Fatalf("setting transient data: %s", err.Error())
+	}
+	response := new(EformContract).SignEform(ctx, "form1", signature, l.now().Format(time.RFC3339))
+	l.stub.TransientMap = nil
+	return response
+}
+
+func TestIssueOTPChallenge(t *testing.T) {
+	tests := []struct {
+		name          string
+		mspID         string
+		attrs         map[string]string
+		expiresIn     int
+		expectedError string
+	}{
+		{name: "member of OTP issuer MSP", mspID: "OTPMSP", expiresIn: 300},
+		{name: "holder of otpIssuer attribute", mspID: "Org1MSP", attrs: map[string]string{"otpIssuer": "true"}, expiresIn: 300},
+		{name: "non issuer", mspID: "Org1MSP", expiresIn: 300, expectedError: "Identity Org1MSP::issuer is not an OTP issuer"},
+		{name: "lifetime over maximum", mspID: "OTPMSP", expiresIn: 901, expectedError: "OTP challenge must expire within 900 seconds"},
+		{name: "no lifetime", mspID: "OTPMSP", expiresIn: 0, expectedError: "OTP challenge must expire within 900 seconds"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := newEformLedger(t)
+			response := issueOTP(l, tt.mspID, tt.attrs, "alice", "123456", tt.expiresIn)
+			expectResponse(t, response, tt.expectedError)
+			if tt.expectedError != "" {
+				return
+			}
+			if issued := response.Data.(otp.Challenge); issued.OTPHash != "" || issued.Salt != "" {
+				t.Fatalf("expected OTP hash and salt not to be returned, got %+v", issued)
+			}
+		})
+	}
+}
+
+func TestConsumeOTPChallenge(t *testing.T) {
+	l := newEformLedger(t)
+	expectResponse(t, issueOTP(l, "OTPMSP", nil, "alice", "123456", 60), "")
+	expectResponse(t, issueOTP(l, "OTPMSP", nil, "alice", "654321", 1), "")
+
+	tests := []struct {
+		name          string
+		signer        string
+		otpCode       string
+		expectedError string
+	}{
+		{name: "wrong OTP", signer: "alice", otpCode: "000000", expectedError: "OTP doesn't match any challenge issued to alice for eform form1"},
+		{name: "OTP issued to other signer", signer: "bob", otpCode: "123456", expectedError: "OTP doesn't match any challenge issued to bob for eform form1"},
+		{name: "expired OTP", signer: "alice", otpCode: "654321", expectedError: "expired at"},
+		{name: "valid OTP", signer: "alice", otpCode: "123456"},
+		{name: "consumed OTP", signer: "alice", otpCode: "123456", expectedError: "is already used"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := l.as("Org1MSP", tt.signer, nil)
+			challenge, err := otp.Consume(ctx, DefaultOTPCollection, "eform", "form1", tt.signer, tt.otpCode, l.now())
+			expectErr(t, err, tt.expectedError)
+			if tt.expectedError == "" && (challenge.ConsumedAt == nil || challenge.ConsumedBy != ctx.GetStub().GetTxID()) {
+				t.Fatalf("expected challenge to be consumed by current transaction, got %+v", challenge)
+			}
+		})
+	}
+}
+
+func TestSignEform(t *testing.T) {
+	tests := []struct {
+		name          string
+		setup         func(l *testLedger)
+		signer        string
+		signature     string
+		otpCode       string
+		expectedError string
+	}{
+		{
+			name:      "valid signature and OTP",
+			setup:     func(l *testLedger) { expectResponse(l.t, issueOTP(l, "OTPMSP", nil, "alice", "123456", 60), "") },
+			signer:    "alice",
+			signature: "sig-alice",
+			otpCode:   "123456",
+		},
+		{
+			name:          "unbound identity",
+			setup:         func(l *testLedger) {},
+			signer:        "mallory",
+			signature:     "sig-alice",
+			otpCode:       "123456",
+			expectedError: "Identity Org1MSP::mallory is not bound to any AKcessID",
+		},
+		{
+			name:          "signature of other user",
+			setup:         func(l *testLedger) { expectResponse(l.t, issueOTP(l, "OTPMSP", nil, "bob", "123456", 60), "") },
+			signer:        "bob",
+			signature:     "sig-alice",
+			otpCode:       "123456",
+			expectedError: "Signature doesn't verify with any active signing key of user bob",
+		},
+		{
+			name:          "OTP issued to other signer",
+			setup:         func(l *testLedger) { expectResponse(l.t, issueOTP(l, "OTPMSP", nil, "bob", "123456", 60), "") },
+			signer:        "alice",
+			signature:     "sig-alice",
+			otpCode:       "123456",
+			expectedError: "OTP doesn't match any challenge issued to alice for eform form1",
+		},
+		{
+			name: "consumed OTP",
+			setup: func(l *testLedger) {
+				expectResponse(l.t, issueOTP(l, "OTPMSP", nil, "alice", "123456", 60), "")
+				expectResponse(l.t, signEform(l, "alice", "sig-alice", "123456"), "")
+			},
+			signer:        "alice",
+			signature:     "sig-alice",
+			otpCode:       "123456",
+			expectedError: "is already used",
+		},
+		{
+			name: "OTP after too many failed attempts",
+			setup: func(l *testLedger) {
+				expectResponse(l.t, issueOTP(l, "OTPMSP", nil, "alice", "123456", 60), "")
+				for attempt := 0; attempt < otp.MaxFailedAttempts; attempt++ {
+					expectResponse(l.t, signEform(l, "alice", "sig-alice", "000000"), "OTP doesn't match any challenge issued to alice for eform form1")
+				}
+			},
+			signer:        "alice",
+			signature:     "sig-alice",
+			otpCode:       "123456",
+			expectedError: "is locked after 5 failed attempts",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := newEformLedger(t)
+			tt.setup(l)
+			expectResponse(t, signEform(l, tt.signer, tt.signature, tt.otpCode), tt.expectedError)
+			if tt.expectedError != "" {
+				return
+			}
+			value, _ := getObject(l.as("Org1MSP", "reader", nil), EformObject, "form1")
+			var eform Eform
+			json.Unmarshal(value, &eform)
+			if len(eform.Signature) != 1 || eform.Signature[0].KeyID != "key1" || eform.Signature[0].OTPHash != saltedHash("salt-123456", "123456") {
+				t.Fatalf("expected signature with key key1 and OTP hash, got %+v", eform.Signature)
+			}
+		})
+	}
+}
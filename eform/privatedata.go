@@ -0,0 +1,146 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// Default names of private data collections, see collections_config.json. Collections are stored on AKcessMSP peers only,
+// clients of every organization with bound identities read and write them through those peers, so collections are not
+// member only and chaincode checks who may access each entry
+const (
+	DefaultOTPCollection   = "eformOTPCollection"
+	DefaultShareCollection = "eformShareCollection"
+)
+
+// PrivateDataConfig names of private data collections sensitive fields are stored in
+type PrivateDataConfig struct {
+	ObjectType      string `json:"docType"`
+	OTPCollection   string `json:"otpCollection"`
+	ShareCollection string `json:"shareCollection"`
+	UpdatedBy       string `json:"updatedBy"`
+}
+
+// ShareReceivers receivers of eform share, only their salted hashes are stored in public state
+type ShareReceivers struct {
+	ObjectType string   `json:"docType"`
+	SharingID  string   `json:"sharingid"`
+	Receivers  []string `json:"receivers"`
+	Salt       string   `json:"salt"`
+}
+
+// SetPrivateDataCollections admin sets names of private data collections used for OTPs and share receivers
+func (d *EformContract) SetPrivateDataCollections(ctx contractapi.TransactionContextInterface, otpCollection string, shareCollection string) Response {
+	response := Response{
+		TxID:    ctx.GetStub().GetTxID(),
+		Success: false,
+		Message: "",
+		Data:    nil,
+	}
+
+	invoker := invokerName(ctx)
+	if !IsAdmin(ctx) {
+		response.Message = fmt.Sprintf("Identity %s is not an admin, only admin can configure private data collections", invoker)
+		logger.Info(response.Message)
+		return response
+	}
+	if otpCollection == "" || shareCollection == "" {
+		response.Message = fmt.Sprint("Collection names can't be empty")
+		logger.Info(response.Message)
+		return response
+	}
+
+	config := PrivateDataConfig{
+		ObjectType:      "privatedataconfig",
+		OTPCollection:   otpCollection,
+		ShareCollection: shareCollection,
+		UpdatedBy:       invoker,
+	}
+	configAsBytes, _ := json.Marshal(config)
+	key, _ := objectKey(ctx, ConfigObject, "privatedata")
+	err := ctx.GetStub().PutState(key, configAsBytes)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while saving private data config: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+
+	response.Success = true
+	response.Message = fmt.Sprint("Private data collections updated")
+	logger.Info(response.Message)
+	response.Data = config
+	return response
+}
+
+// GetPrivateDataCollections returns names of private data collections in use
+func (d *EformContract) GetPrivateDataCollections(ctx contractapi.TransactionContextInterface) Response {
+	response := Response{
+		TxID:    ctx.GetStub().GetTxID(),
+		Success: false,
+		Message: "",
+		Data:    nil,
+	}
+
+	config, err := getPrivateDataConfig(ctx)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while fetching private data config: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+
+	response.Success = true
+	response.Message = fmt.Sprint("Successfully fetched private data collections")
+	logger.Info(response.Message)
+	response.Data = config
+	return response
+}
+
+// getPrivateDataConfig returns private data collections set by admin, default collections if admin didn't set any
+func getPrivateDataConfig(ctx contractapi.TransactionContextInterface) (*PrivateDataConfig, error) {
+	key, _ := objectKey(ctx, ConfigObject, "privatedata")
+	configAsBytes, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return nil, err
+	}
+
+	config := PrivateDataConfig{
+		ObjectType:      "privatedataconfig",
+		OTPCollection:   DefaultOTPCollection,
+		ShareCollection: DefaultShareCollection,
+	}
+	if configAsBytes != nil {
+		err = json.Unmarshal(configAsBytes, &config)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &config, nil
+}
+
+// getTransientValue returns value passed in transient data of the proposal under given key
+func getTransientValue(ctx contractapi.TransactionContextInterface, key string) (string, error) {
+	transient, err := ctx.GetStub().GetTransient()
+	if err != nil {
+		return "", err
+	}
+	value, found := transient[key]
+	if !found || len(value) == 0 {
+		return "", fmt.Errorf("%s must be passed in transient data", key)
+	}
+	return string(value), nil
+}
+
+// putPrivateObject writes object to private data collection under composite key of given type and attributes
+func putPrivateObject(ctx contractapi.TransactionContextInterface, collection string, objectType string, attributes []string, object interface{}) error {
+	key, err := ctx.GetStub().CreateCompositeKey(objectType, attributes)
+	if err != nil {
+		return err
+	}
+	objectAsBytes, err := json.Marshal(object)
+	if err != nil {
+		return err
+	}
+	return ctx.GetStub().PutPrivateData(collection, key, objectAsBytes)
+}
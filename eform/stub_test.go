@@ -0,0 +1,360 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/hyperledger/fabric-chaincode-go/pkg/cid"
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-chaincode-go/shimtest"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/hyperledger/fabric-protos-go/ledger/queryresult"
+	"github.com/hyperledger/fabric-protos-go/msp"
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+)
+
+// testTime tx timestamp of first transaction in tests, every next transaction is one second later
+var testTime = time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// attributeOID OID of certificate extension Fabric CA stores attributes in
+var attributeOID = asn1.ObjectIdentifier{1, 2, 3, 4, 5, 6, 7, 8, 1}
+
+// fakeAkcess state of akcess chaincode on global channel eform chaincode reads
+type fakeAkcess struct {
+	bindings   map[string]string // AKcessID by MSP qualified enrollment ID
+	config     *AccessConfig
+	verifiers  map[string]Verifier
+	signatures map[string]string // key ID by AKcessID, message and signature joined with |
+}
+
+// testStub mock stub with queries chaincode uses which shimtest doesn't implement and fake akcess chaincode
+type testStub struct {
+	*shimtest.MockStub
+	akcess *fakeAkcess
+}
+
+// InvokeChaincode answers calls of akcess chaincode from fake akcess state
+func (s *testStub) InvokeChaincode(chaincodeName string, args [][]byte, channel string) pb.Response {
+	if chaincodeName != "akcess" || channel != "akcessglobal" || len(args) == 0 {
+		return shim.Error(fmt.Sprintf("chaincode %s on channel %s is not available", chaincodeName, channel))
+	}
+	switch string(args[0]) {
+	case "ResolveIdentity":
+		identity, err := cid.New(s)
+		if err != nil {
+			return shim.Error(err.Error())
+		}
+		mspID, _ := identity.GetMSPID()
+		certificate, _ := identity.GetX509Certificate()
+		enrollmentID := certificate.Subject.CommonName
+		akcessID, found := s.akcess.bindings[mspID+"::"+enrollmentID]
+		if !found {
+			return shim.Error(fmt.Sprintf("Identity %s::%s is not bound to any AKcessID", mspID, enrollmentID))
+		}
+		bindingAsBytes, _ := json.Marshal(IdentityBinding{MSPID: mspID, EnrollmentID: enrollmentID, AkcessID: akcessID})
+		return shim.Success(bindingAsBytes)
+	case "GetAccessConfig":
+		if s.akcess.config == nil {
+			return shim.Error("Ledger is not initialized, run InitLedger first")
+		}
+		configAsBytes, _ := json.Marshal(s.akcess.config)
+		return shim.Success(configAsBytes)
+	case "GetVerifier":
+		verifier, found := s.akcess.verifiers[string(args[1])]
+		if !found {
+			return shim.Error(fmt.Sprintf("AKcessID %s doesn't exist", args[1]))
+		}
+		verifierAsBytes, _ := json.Marshal(verifier)
+		return shim.Success(verifierAsBytes)
+	case "VerifyUserSignature":
+		keyID, found := s.akcess.signatures[string(args[1])+"|"+string(args[2])+"|"+string(args[3])]
+		if !found {
+			return shim.Error(fmt.Sprintf("Signature doesn't verify with any active signing key of user %s", args[1]))
+		}
+		return shim.Success([]byte(keyID))
+	}
+	return shim.Error(fmt.Sprintf("Invalid function %s", args[0]))
+}
+
+// GetStateByRange range query over simple keys, composite keys are skipped as they are on peer
+func (s *testStub) GetStateByRange(startKey string, endKey string) (shim.StateQueryIteratorInterface, error) {
+	results := []*queryresult.KV{}
+	for elem := s.Keys.Front(); elem != nil; elem = elem.Next() {
+		key := elem.Value.(string)
+		if strings.HasPrefix(key, "\x00") || key < startKey || (endKey != "" && key >= endKey) {
+			continue
+		}
+		results = append(results, &queryresult.KV{Key: key, Value: s.State[key]})
+	}
+	return &testIterator{results: results}, nil
+}
+
+// GetPrivateDataByPartialCompositeKey query over composite keys of private data collection
+func (s *testStub) GetPrivateDataByPartialCompositeKey(collection string, objectType string, attributes []string) (shim.StateQueryIteratorInterface, error) {
+	prefix, err := s.CreateCompositeKey(objectType, attributes)
+	if err != nil {
+		return nil, err
+	}
+	keys := []string{}
+	for key := range s.PvtState[collection] {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	results := []*queryresult.KV{}
+	for _, key := range keys {
+		results = append(results, &queryresult.KV{Key: key, Value: s.PvtState[collection][key]})
+	}
+	return &testIterator{results: results}, nil
+}
+
+// GetQueryResult rich query matching selector fields by equality or $exists, enough for queries chaincode runs
+func (s *testStub) GetQueryResult(query string) (shim.StateQueryIteratorInterface, error) {
+	var parsed struct {
+		Selector map[string]interface{} `json:"selector"`
+	}
+	err := json.Unmarshal([]byte(query), &parsed)
+	if err != nil {
+		return nil, err
+	}
+	results := []*queryresult.KV{}
+	for elem := s.Keys.Front(); elem != nil; elem = elem.Next() {
+		key := elem.Value.(string)
+		var object map[string]interface{}
+		if json.Unmarshal(s.State[key], &object) != nil {
+			continue
+		}
+		matches, err := matchesSelector(object, parsed.Selector)
+		if err != nil {
+			return nil, err
+		}
+		if matches {
+			results = append(results, &queryresult.KV{Key: key, Value: s.State[key]})
+		}
+	}
+	return &testIterator{results: results}, nil
+}
+
+func matchesSelector(object map[string]interface{}, selector map[string]interface{}) (bool, error) {
+	for field, condition := range selector {
+		value, found := object[field]
+		operators, isOperator := condition.(map[string]interface{})
+		if !isOperator {
+			if !found || !reflect.DeepEqual(value, condition) {
+				return false, nil
+			}
+			continue
+		}
+		for operator, operand := range operators {
+			if operator != "$exists" {
+				return false, fmt.Errorf("operator %s is not supported by test stub", operator)
+			}
+			if found != operand.(bool) {
+				return false, nil
+			}
+		}
+	}
+	return true, nil
+}
+
+// testIterator iterator over fixed query results
+type testIterator struct {
+	results []*queryresult.KV
+}
+
+func (it *testIterator) HasNext() bool {
+	return len(it.results) > 0
+}
+
+func (it *testIterator) Next() (*queryresult.KV, error) {
+	if len(it.results) == 0 {
+		return nil, fmt.Errorf("iterator has no more results")
+	}
+	result := it.results[0]
+	it.results = it.results[1:]
+	return result, nil
+}
+
+func (it *testIterator) Close() error {
+	return nil
+}
+
+// testLedger world state shared by transactions of one test, akcess chaincode is initialized with AdminMSP
+// as admin MSP and OTPMSP as OTP issuer MSP
+type testLedger struct {
+	t    *testing.T
+	stub *testStub
+	txs  int
+}
+
+func newTestLedger(t *testing.T) *testLedger {
+	return &testLedger{
+		t: t,
+		stub: &testStub{
+			MockStub: shimtest.NewMockStub("eform", nil),
+			akcess: &fakeAkcess{
+				bindings:   map[string]string{},
+				config:     &AccessConfig{AdminMSPIDs: []string{"AdminMSP"}, OTPIssuerMSPIDs: []string{"OTPMSP"}},
+				verifiers:  map[string]Verifier{},
+				signatures: map[string]string{},
+			},
+		},
+	}
+}
+
+// bind binds identity with given MSP and enrollment ID to AKcessID in fake akcess chaincode
+func (l *testLedger) bind(mspID string, enrollmentID string, akcessID string) {
+	l.stub.akcess.bindings[mspID+"::"+enrollmentID] = akcessID
+}
+
+// as starts new transaction invoked by identity with given MSP, enrollment ID and certificate attributes
+func (l *testLedger) as(mspID string, enrollmentID string, attrs map[string]string) contractapi.TransactionContextInterface {
+	l.t.Helper()
+	l.txs++
+	l.stub.MockTransactionStart(fmt.Sprintf("tx%d", l.txs))
+	l.stub.TxTimestamp, _ = ptypes.TimestampProto(l.now())
+	l.stub.Creator = serializedIdentity(l.t, mspID, enrollmentID, attrs)
+
+	identity, err := cid.New(l.stub)
+	if err != nil {
+		l.t.Fatalf("creating client identity: %s", err.Error())
+	}
+	ctx := new(contractapi.TransactionContext)
+	ctx.SetStub(l.stub)
+	ctx.SetClientIdentity(identity)
+	return ctx
+}
+
+// now tx timestamp of current transaction
+func (l *testLedger) now() time.Time {
+	return testTime.Add(time.Duration(l.txs) * time.Second)
+}
+
+// put writes object to world state in its own transaction, used to set up state tests start from
+func (l *testLedger) put(key string, object interface{}) {
+	l.t.Helper()
+	objectAsBytes, err := json.Marshal(object)
+	if err != nil {
+		l.t.Fatalf("marshalling %s: %s", key, err.Error())
+	}
+	l.stub.MockTransactionStart("setup")
+	err = l.stub.PutState(key, objectAsBytes)
+	l.stub.MockTransactionEnd("setup")
+	if err != nil {
+		l.t.Fatalf("writing %s: %s", key, err.Error())
+	}
+}
+
+// compositeKey composite key of given type and attributes
+func (l *testLedger) compositeKey(objectType string, attributes ...string) string {
+	l.t.Helper()
+	key, err := l.stub.CreateCompositeKey(objectType, attributes)
+	if err != nil {
+		l.t.Fatalf("creating composite key: %s", err.Error())
+	}
+	return key
+}
+
+// serializedIdentity serialized identity with self signed certificate, common name is set to enrollment ID
+// and attributes are stored in certificate extension as Fabric CA stores them
+func serializedIdentity(t *testing.T, mspID string, enrollmentID string, attrs map[string]string) []byte {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generating key: %s", err.Error())
+	}
+	template := x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: enrollmentID, Organization: []string{mspID}},
+		NotBefore:    testTime.Add(-time.Hour),
+		NotAfter:     testTime.Add(24 * time.Hour),
+	}
+	if attrs != nil {
+		attrsAsBytes, _ := json.Marshal(map[string]map[string]string{"attrs": attrs})
+		template.ExtraExtensions = []pkix.Extension{{Id: attributeOID, Value: attrsAsBytes}}
+	}
+	certificate, err := x509.CreateCertificate(rand.Reader, &template, &template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("creating certificate: %s", err.Error())
+	}
+
+	identity, err := proto.Marshal(&msp.SerializedIdentity{
+		Mspid:   mspID,
+		IdBytes: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certificate}),
+	})
+	if err != nil {
+		t.Fatalf("serializing identity: %s", err.Error())
+	}
+	return identity
+}
+
+// expectResponse checks response succeeded, or failed with message containing expected error
+func expectResponse(t *testing.T, response Response, expectedError string) {
+	t.Helper()
+	expectError(t, response.Success, response.Message, expectedError)
+}
+
+// expectErr checks err is nil, or contains expected error
+func expectErr(t *testing.T, err error, expectedError string) {
+	t.Helper()
+	message := ""
+	if err != nil {
+		message = err.Error()
+	}
+	expectError(t, err == nil, message, expectedError)
+}
+
+func expectError(t *testing.T, success bool, message string, expectedError string) {
+	t.Helper()
+	if expectedError == "" {
+		if !success {
+			t.Fatalf("expected success, got error: %s", message)
+		}
+		return
+	}
+	if success {
+		t.Fatalf("expected error containing %q, got success: %s", expectedError, message)
+	}
+	if !strings.Contains(message, expectedError) {
+		t.Fatalf("expected error containing %q, got: %s", expectedError, message)
+	}
+}
+
+func equalStrings(a []string, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// newEformLedger ledger with eform form1 created by alice, alice and bob are bound in akcess chaincode
+// and signature sig-alice over last hash of form1 verifies with alice's signing key key1
+func newEformLedger(t *testing.T) *testLedger {
+	l := newTestLedger(t)
+	l.bind("Org1MSP", "alice", "alice")
+	l.bind("Org1MSP", "bob", "bob")
+	l.stub.akcess.signatures["alice|hash2|sig-alice"] = "key1"
+	expectResponse(t, new(EformContract).CreateEform(l.as("Org1MSP", "alice", nil), "form1", []string{"hash1", "hash2"}), "")
+	return l
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// Default time policy, used until admin sets one
+const (
+	DefaultSignDateSkewSeconds = 300
+	DefaultExpiryHorizonDays   = 5 * 365
+)
+
+// TimePolicy limits client supplied dates are checked with against tx timestamp
+type TimePolicy struct {
+	ObjectType          string `json:"docType"`
+	SignDateSkewSeconds int    `json:"signDateSkewSeconds"` // how far sign date may be from tx timestamp in either direction
+	ExpiryHorizonDays   int    `json:"expiryHorizonDays"`   // how far after tx timestamp expiry date may be
+	UpdatedBy           string `json:"updatedBy"`
+}
+
+// SetTimePolicy admin sets allowed clock skew of sign dates and maximum horizon of verification expiry dates
+func (d *EformContract) SetTimePolicy(ctx contractapi.TransactionContextInterface, signDateSkewSeconds int, expiryHorizonDays int) Response {
+	response := Response{
+		TxID:    ctx.GetStub().GetTxID(),
+		Success: false,
+		Message: "",
+		Data:    nil,
+	}
+
+	invoker := invokerName(ctx)
+	if !IsAdmin(ctx) {
+		response.Message = fmt.Sprintf("Identity %s is not an admin, only admin can set time policy", invoker)
+		logger.Info(response.Message)
+		return response
+	}
+	if signDateSkewSeconds < 0 || expiryHorizonDays <= 0 {
+		response.Message = fmt.Sprint("Sign date skew can't be negative and expiry horizon must be greater than zero")
+		logger.Info(response.Message)
+		return response
+	}
+
+	policy := TimePolicy{
+		ObjectType:          "timepolicy",
+		SignDateSkewSeconds: signDateSkewSeconds,
+		ExpiryHorizonDays:   expiryHorizonDays,
+		UpdatedBy:           invoker,
+	}
+	policyAsBytes, _ := json.Marshal(policy)
+	key, _ := objectKey(ctx, ConfigObject, "timepolicy")
+	err := ctx.GetStub().PutState(key, policyAsBytes)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while saving time policy: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+
+	response.Success = true
+	response.Message = fmt.Sprint("Time policy updated")
+	logger.Info(response.Message)
+	response.Data = policy
+	return response
+}
+
+// GetTimePolicy returns time policy in use
+func (d *EformContract) GetTimePolicy(ctx contractapi.TransactionContextInterface) Response {
+	response := Response{
+		TxID:    ctx.GetStub().GetTxID(),
+		Success: false,
+		Message: "",
+		Data:    nil,
+	}
+
+	policy, err := getTimePolicy(ctx)
+	if err != nil {
+		response.Message = fmt.Sprintf("Error while fetching time policy: %s", err.Error())
+		logger.Error(response.Message)
+		return response
+	}
+
+	response.Success = true
+	response.Message = fmt.Sprint("Successfully fetched time policy")
+	logger.Info(response.Message)
+	response.Data = policy
+	return response
+}
+
+// CheckSignDate refuses sign date which differs from tx timestamp by more than allowed skew
+func (p TimePolicy) CheckSignDate(signDate time.Time, txTime time.Time) error {
+	skew := time.Duration(p.SignDateSkewSeconds) * time.Second
+	if signDate.Before(txTime.Add(-skew)) || signDate.After(txTime.Add(skew)) {
+		return fmt.Errorf("Sign date %s is more than %d seconds away from tx timestamp %s", signDate.Format(time.RFC3339), p.SignDateSkewSeconds, txTime.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// CheckExpiryDate refuses expiry date which is not after tx timestamp or is beyond expiry horizon
+func (p TimePolicy) CheckExpiryDate(expiryDate time.Time, txTime time.Time) error {
+	if !expiryDate.After(txTime) {
+		return fmt.Errorf("Expiry date %s is not in the future", expiryDate.Format(time.RFC3339))
+	}
+	if expiryDate.After(txTime.AddDate(0, 0, p.ExpiryHorizonDays)) {
+		return fmt.Errorf("Expiry date %s is more than %d days in the future", expiryDate.Format(time.RFC3339), p.ExpiryHorizonDays)
+	}
+	return nil
+}
+
+// getTimePolicy returns time policy set by admin, default policy if admin didn't set any
+func getTimePolicy(ctx contractapi.TransactionContextInterface) (*TimePolicy, error) {
+	key, _ := objectKey(ctx, ConfigObject, "timepolicy")
+	policyAsBytes, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return nil, err
+	}
+
+	policy := TimePolicy{
+		ObjectType:          "timepolicy",
+		SignDateSkewSeconds: DefaultSignDateSkewSeconds,
+		ExpiryHorizonDays:   DefaultExpiryHorizonDays,
+	}
+	if policyAsBytes != nil {
+		err = json.Unmarshal(policyAsBytes, &policy)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &policy, nil
+}
@@ -1,9 +1,15 @@
 package main
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"time"
 
+	"github.com/hyperledger/fabric-chaincode-go/shim"
 	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/hyperledger/fabric/common/util"
 )
 
 // Response chaincode response will be returned in this format
@@ -21,10 +27,138 @@ func UnknownTransactionHandler(ctx contractapi.TransactionContextInterface) erro
 	return fmt.Errorf("Invalid function %s passed with args %v", fcn, args)
 }
 
-func getCommonName(ctx contractapi.TransactionContextInterface) (string, error) {
+// getTxTimestamp returns the transaction timestamp set by the client in the proposal.
+// It is the same on every endorsing peer, so it is safe to store in world state
+func getTxTimestamp(ctx contractapi.TransactionContextInterface) (time.Time, error) {
+	ts, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(ts.Seconds, int64(ts.Nanos)).UTC(), nil
+}
+
+// getGlobalVerifier reads verifier from verifier registry of akcess chaincode on global channel,
+// returns nil if verifier is not registered
+func getGlobalVerifier(ctx contractapi.TransactionContextInterface, akcessid string) *Verifier {
+	invokeArgs := util.ToChaincodeArgs("GetVerifier", akcessid)
+	verifierAsBytes := ctx.GetStub().InvokeChaincode("akcess", invokeArgs, "akcessglobal")
+	if verifierAsBytes.Payload == nil {
+		return nil
+	}
+	var verifier Verifier
+	err := json.Unmarshal(verifierAsBytes.Payload, &verifier)
+	if err != nil || verifier.AkcessID == "" {
+		return nil
+	}
+	return &verifier
+}
+
+// IdentityBinding binding of certificate identity to AKcessID kept by akcess chaincode
+type IdentityBinding struct {
+	MSPID        string `json:"mspId"`
+	EnrollmentID string `json:"enrollmentId"`
+	AkcessID     string `json:"akcessId"`
+}
+
+// resolveAkcessID returns AKcessID identity invoking transaction is bound to in akcess chaincode on global channel.
+// Unbound and ambiguous identities are rejected by akcess chaincode with explicit error
+func resolveAkcessID(ctx contractapi.TransactionContextInterface) (string, error) {
+	invokeArgs := util.ToChaincodeArgs("ResolveIdentity")
+	bindingAsBytes := ctx.GetStub().InvokeChaincode("akcess", invokeArgs, "akcessglobal")
+	if bindingAsBytes.Status != shim.OK {
+		return "", fmt.Errorf("%s", bindingAsBytes.Message)
+	}
+	var binding IdentityBinding
+	err := json.Unmarshal(bindingAsBytes.Payload, &binding)
+	if err != nil || binding.AkcessID == "" {
+		return "", fmt.Errorf("Error while resolving AKcessID of invoker")
+	}
+	return binding.AkcessID, nil
+}
+
+// verifyUserSignature verifies base64 encoded signature over message with active signing keys user registered
+// in akcess chaincode on global channel and returns ID of key it verifies with
+func verifyUserSignature(ctx contractapi.TransactionContextInterface, akcessid string, message string, signature string) (string, error) {
+	invokeArgs := util.ToChaincodeArgs("VerifyUserSignature", akcessid, message, signature)
+	keyIDAsBytes := ctx.GetStub().InvokeChaincode("akcess", invokeArgs, "akcessglobal")
+	if keyIDAsBytes.Status != shim.OK {
+		return "", fmt.Errorf("%s", keyIDAsBytes.Message)
+	}
+	return string(keyIDAsBytes.Payload), nil
+}
+
+// invokerName returns AKcessID of invoker, or its MSP qualified certificate name when identity isn't bound.
+// Used to record who changed configuration, as admins don't need an AKcessID
+func invokerName(ctx contractapi.TransactionContextInterface) string {
+	if akcessID, err := resolveAkcessID(ctx); err == nil {
+		return akcessID
+	}
+	mspID, _ := ctx.GetClientIdentity().GetMSPID()
 	x509, err := ctx.GetClientIdentity().GetX509Certificate()
 	if err != nil {
-		return "", err
+		return mspID
 	}
-	return x509.Subject.CommonName, nil
+	return mspID + "::" + x509.Subject.CommonName
+}
+
+// AccessConfig MSPs whose members hold AKcess roles, kept by akcess chaincode
+type AccessConfig struct {
+	AdminMSPIDs     []string `json:"adminMspIds"`
+	OTPIssuerMSPIDs []string `json:"otpIssuerMspIds"`
+}
+
+// getGlobalAccessConfig returns access config of akcess chaincode on global channel,
+// nil if it can't be read or ledger is not initialized
+func getGlobalAccessConfig(ctx contractapi.TransactionContextInterface) *AccessConfig {
+	invokeArgs := util.ToChaincodeArgs("GetAccessConfig")
+	configAsBytes := ctx.GetStub().InvokeChaincode("akcess", invokeArgs, "akcessglobal")
+	if configAsBytes.Status != shim.OK || configAsBytes.Payload == nil {
+		return nil
+	}
+	var config AccessConfig
+	err := json.Unmarshal(configAsBytes.Payload, &config)
+	if err != nil {
+		return nil
+	}
+	return &config
+}
+
+// IsAdmin checks if identity invoking transaction is an AKcess admin.
+// Admin is either member of one of admin MSPs in access config of akcess chaincode
+// or holds isAdmin=true attribute in its certificate
+func IsAdmin(ctx contractapi.TransactionContextInterface) bool {
+	mspID, err := ctx.GetClientIdentity().GetMSPID()
+	if err == nil {
+		if config := getGlobalAccessConfig(ctx); config != nil {
+			if _, found := Find(config.AdminMSPIDs, mspID); found {
+				return true
+			}
+		}
+	}
+
+	err = ctx.GetClientIdentity().AssertAttributeValue("isAdmin", "true")
+	return err == nil
+}
+
+// IsOTPIssuer checks if identity invoking transaction is trusted to issue OTP challenges.
+// Issuer is either member of one of OTP issuer MSPs in access config of akcess chaincode
+// or holds otpIssuer=true attribute in its certificate
+func IsOTPIssuer(ctx contractapi.TransactionContextInterface) bool {
+	mspID, err := ctx.GetClientIdentity().GetMSPID()
+	if err == nil {
+		if config := getGlobalAccessConfig(ctx); config != nil {
+			if _, found := Find(config.OTPIssuerMSPIDs, mspID); found {
+				return true
+			}
+		}
+	}
+
+	err = ctx.GetClientIdentity().AssertAttributeValue("otpIssuer", "true")
+	return err == nil
+}
+
+// saltedHash returns hex encoded SHA-256 hash of salt followed by value
+func saltedHash(salt string, value string) string {
+	hash := sha256.Sum256([]byte(salt + value))
+	return hex.EncodeToString(hash[:])
 }
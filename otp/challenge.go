@@ -0,0 +1,171 @@
+// Package otp keeps OTP challenges signers of AKcess documents and eforms answer when signing.
+// Only salted hash of OTP is stored in private data collection. Every answer that doesn't consume a challenge
+// counts as failed attempt on challenges still open, which are locked after MaxFailedAttempts
+package otp
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+const (
+	// ObjectType type of composite key challenges are stored under, attributes are signer, subject ID and challenge ID
+	ObjectType = "otpchallenge"
+	// MaxLifetime longest time OTP challenge can stay valid
+	MaxLifetime = 15 * time.Minute
+	// MaxFailedAttempts wrong answers after which challenge can't be used anymore
+	MaxFailedAttempts = 5
+)
+
+// Challenge OTP issued by OTP issuer to signer of document or eform
+type Challenge struct {
+	ObjectType     string     `json:"docType"`
+	ChallengeID    string     `json:"challengeId"` // tx ID of issuing transaction
+	SubjectID      string     `json:"subjectId"`   // ID of document or eform signed
+	Signer         string     `json:"signer"`
+	OTPHash        string     `json:"otpHash,omitempty"`
+	Salt           string     `json:"salt,omitempty"`
+	IssuedBy       string     `json:"issuedBy"`
+	IssuedAt       time.Time  `json:"issuedAt"`
+	ExpiresAt      time.Time  `json:"expiresAt"`
+	FailedAttempts int        `json:"failedAttempts,omitempty"`
+	ConsumedAt     *time.Time `json:"consumedAt,omitempty"`
+	ConsumedBy     string     `json:"consumedBy,omitempty"` // tx ID of signing transaction
+}
+
+// UnmarshalJSON reads challenges issued before documents and eforms shared this package,
+// they named their subject documentID or eformId
+func (c *Challenge) UnmarshalJSON(data []byte) error {
+	type challenge Challenge
+	var stored struct {
+		challenge
+		DocumentID string `json:"documentID"`
+		EformID    string `json:"eformId"`
+	}
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return err
+	}
+	*c = Challenge(stored.challenge)
+	if c.SubjectID == "" {
+		c.SubjectID = stored.DocumentID + stored.EformID
+	}
+	return nil
+}
+
+// Locked reports whether challenge had too many failed attempts to be answered
+func (c Challenge) Locked() bool {
+	return c.FailedAttempts >= MaxFailedAttempts
+}
+
+// Lifetime converts lifetime of challenge in seconds, refusing lifetime which isn't positive or exceeds MaxLifetime
+func Lifetime(expiresIn int) (time.Duration, error) {
+	lifetime := time.Duration(expiresIn) * time.Second
+	if lifetime <= 0 || lifetime > MaxLifetime {
+		return 0, fmt.Errorf("OTP challenge must expire within %d seconds", int(MaxLifetime.Seconds()))
+	}
+	return lifetime, nil
+}
+
+// Issue stores challenge of current transaction for signer of subject and returns it without OTP hash and salt
+func Issue(ctx contractapi.TransactionContextInterface, collection string, subjectID string, signer string, otpCode string, salt string, issuedBy string, now time.Time, lifetime time.Duration) (*Challenge, error) {
+	challenge := Challenge{
+		ObjectType:  ObjectType,
+		ChallengeID: ctx.GetStub().GetTxID(),
+		SubjectID:   subjectID,
+		Signer:      signer,
+		OTPHash:     saltedHash(salt, otpCode),
+		Salt:        salt,
+		IssuedBy:    issuedBy,
+		IssuedAt:    now,
+		ExpiresAt:   now.Add(lifetime),
+	}
+	key, err := ctx.GetStub().CreateCompositeKey(ObjectType, []string{signer, subjectID, challenge.ChallengeID})
+	if err != nil {
+		return nil, err
+	}
+	challengeAsBytes, _ := json.Marshal(challenge)
+	err = ctx.GetStub().PutPrivateData(collection, key, challengeAsBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	challenge.OTPHash = ""
+	challenge.Salt = ""
+	return &challenge, nil
+}
+
+// Consume finds open challenge issued to signer for subject which OTP answers and marks it consumed by current
+// transaction. subject names kind of subject, document or eform, in errors. When OTP consumes no challenge, failed
+// attempt is recorded on every open challenge, so transaction must be committed even though signing fails
+func Consume(ctx contractapi.TransactionContextInterface, collection string, subject string, subjectID string, signer string, otpCode string, now time.Time) (*Challenge, error) {
+	resultIterator, err := ctx.GetStub().GetPrivateDataByPartialCompositeKey(collection, ObjectType, []string{signer, subjectID})
+	if err != nil {
+		return nil, fmt.Errorf("Error while fetching OTP challenges: %s", err.Error())
+	}
+	keys := []string{}
+	challenges := []Challenge{}
+	for resultIterator.HasNext() {
+		queryResponse, err := resultIterator.Next()
+		if err != nil {
+			resultIterator.Close()
+			return nil, fmt.Errorf("Error while iterating OTP challenges: %s", err.Error())
+		}
+		var challenge Challenge
+		json.Unmarshal(queryResponse.Value, &challenge)
+		keys = append(keys, queryResponse.Key)
+		challenges = append(challenges, challenge)
+	}
+	resultIterator.Close()
+
+	matchErr := fmt.Errorf("OTP doesn't match any challenge issued to %s for %s %s", signer, subject, subjectID)
+	for i, challenge := range challenges {
+		if saltedHash(challenge.Salt, otpCode) != challenge.OTPHash {
+			continue
+		}
+		if challenge.ConsumedAt != nil {
+			matchErr = fmt.Errorf("OTP of challenge %s is already used", challenge.ChallengeID)
+			continue
+		}
+		if !now.Before(challenge.ExpiresAt) {
+			matchErr = fmt.Errorf("OTP of challenge %s expired at %s", challenge.ChallengeID, challenge.ExpiresAt.Format(time.RFC3339))
+			continue
+		}
+		if challenge.Locked() {
+			matchErr = fmt.Errorf("OTP challenge %s is locked after %d failed attempts", challenge.ChallengeID, challenge.FailedAttempts)
+			continue
+		}
+
+		challenge.ConsumedAt = &now
+		challenge.ConsumedBy = ctx.GetStub().GetTxID()
+		challengeAsBytes, _ := json.Marshal(challenge)
+		err = ctx.GetStub().PutPrivateData(collection, keys[i], challengeAsBytes)
+		if err != nil {
+			return nil, fmt.Errorf("Error while consuming OTP challenge: %s", err.Error())
+		}
+		return &challenge, nil
+	}
+
+	for i, challenge := range challenges {
+		if challenge.ConsumedAt != nil || !now.Before(challenge.ExpiresAt) || challenge.Locked() {
+			continue
+		}
+		challenge.FailedAttempts++
+		challengeAsBytes, _ := json.Marshal(challenge)
+		err = ctx.GetStub().PutPrivateData(collection, keys[i], challengeAsBytes)
+		if err != nil {
+			return nil, fmt.Errorf("Error while recording failed OTP attempt: %s", err.Error())
+		}
+	}
+	return nil, matchErr
+}
+
+// saltedHash returns hex encoded SHA-256 hash of salt followed by value
+func saltedHash(salt string, value string) string {
+	hash := sha256.Sum256([]byte(salt + value))
+	return hex.EncodeToString(hash[:])
+}
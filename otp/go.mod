@@ -0,0 +1,8 @@
+module otp
+
+go 1.14
+
+require (
+	github.com/hyperledger/fabric-chaincode-go v0.0.0-20201119163726-f8ef75b17719 // indirect
+	github.com/hyperledger/fabric-contract-api-go v1.1.1
+)